@@ -0,0 +1,162 @@
+package A
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AutoRefreshOption defines functional options for AutoRefresh.
+type AutoRefreshOption func(*AutoRefresh)
+
+// WithRefreshSchedule computes the delay before each refresh from the latest
+// successful value, for values such as tokens that carry their own expiry.
+// It replaces the fixed interval. prev is nil until a refresh has succeeded.
+func WithRefreshSchedule(next func(prev any) time.Duration) AutoRefreshOption {
+	return func(a *AutoRefresh) {
+		a.next = next
+	}
+}
+
+// WithOnRefreshError calls fn with the error of every failed refresh.
+func WithOnRefreshError(fn func(err error)) AutoRefreshOption {
+	return func(a *AutoRefresh) {
+		a.onError = fn
+	}
+}
+
+// WithRefreshOptions applies opts to the future of every refresh.
+func WithRefreshOptions(opts ...Option) AutoRefreshOption {
+	return func(a *AutoRefresh) {
+		a.opts = append(a.opts, opts...)
+	}
+}
+
+// WithRefreshClock makes the refresher read time from c instead of the real
+// clock.
+func WithRefreshClock(c Clock) AutoRefreshOption {
+	return func(a *AutoRefresh) {
+		a.clock = c
+	}
+}
+
+// AutoRefresh holds a value that is refreshed in the background, such as an
+// auth token or a feature-flag snapshot. Result blocks only until the first
+// refresh settles; after that it returns the latest successful value at
+// once, and failed refreshes keep serving it.
+type AutoRefresh struct {
+	ctx     context.Context
+	task    func(context.Context) (any, error)
+	next    func(prev any) time.Duration
+	onError func(err error)
+	opts    []Option
+	clock   Clock
+
+	filled chan struct{}
+	fill   sync.Once
+
+	mu      sync.Mutex
+	value   any
+	err     error
+	ok      bool
+	current *Future
+	timer   Timer
+	closed  bool
+}
+
+// NewAutoRefresh starts running task under ctx and runs it again interval
+// after each run settles, until Close is called or ctx is done.
+func NewAutoRefresh(ctx context.Context, task func(context.Context) (any, error), interval time.Duration, opts ...AutoRefreshOption) *AutoRefresh {
+	ctx = orBackground(ctx)
+	a := &AutoRefresh{
+		ctx:  ctx,
+		task: task,
+		next: func(any) time.Duration {
+			return interval
+		},
+		filled: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.refresh()
+	context.AfterFunc(ctx, a.Close)
+	return a
+}
+
+// Result waits for the first refresh to settle, then returns the latest
+// successful value. Until a refresh has succeeded, it returns the error of
+// the latest one.
+func (a *AutoRefresh) Result() (any, error) {
+	<-a.filled
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.ok {
+		return a.value, nil
+	}
+	return nil, a.err
+}
+
+// Close stops the refresher and aborts a refresh in flight. Result keeps
+// returning the latest value.
+func (a *AutoRefresh) Close() {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return
+	}
+	a.closed = true
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	current := a.current
+	a.mu.Unlock()
+	if current != nil {
+		current.Abort()
+	}
+}
+
+// refresh runs the task once.
+func (a *AutoRefresh) refresh() {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return
+	}
+	f := NewFuture(a.ctx, a.task, a.opts...)
+	a.current = f
+	a.mu.Unlock()
+	f.whenDone(func() {
+		a.settled(f)
+	})
+}
+
+// settled records the result of a refresh and schedules the next one.
+func (a *AutoRefresh) settled(f *Future) {
+	value, err := f.peek()
+	a.mu.Lock()
+	a.current = nil
+	if err == nil {
+		a.value, a.ok = value, true
+	} else if !a.ok {
+		a.err = err
+	}
+	if !a.closed {
+		a.timer = a.afterFunc(a.next(a.value), a.refresh)
+	}
+	a.mu.Unlock()
+	a.fill.Do(func() {
+		close(a.filled)
+	})
+	if err != nil && a.onError != nil {
+		a.onError(err)
+	}
+}
+
+// afterFunc calls fn after d on the refresher's clock.
+func (a *AutoRefresh) afterFunc(d time.Duration, fn func()) Timer {
+	if a.clock == nil {
+		return time.AfterFunc(d, fn)
+	}
+	return a.clock.AfterFunc(d, fn)
+}
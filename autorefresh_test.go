@@ -0,0 +1,112 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+func TestAutoRefresh_Refreshes(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	var runs atomic.Int32
+	a := NewAutoRefresh(context.Background(), func(ctx context.Context) (any, error) {
+		return runs.Add(1), nil
+	}, time.Minute, WithRefreshClock(clock))
+	defer a.Close()
+
+	if v, err := a.Result(); v != int32(1) || err != nil {
+		t.Fatalf("expected the first value, got %v, %v", v, err)
+	}
+	clock.Advance(time.Minute)
+	for runs.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.BlockUntil(1)
+	if v, _ := a.Result(); v != int32(2) {
+		t.Fatalf("expected the refreshed value, got %v", v)
+	}
+}
+
+func TestAutoRefresh_KeepsValueOnError(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	errFailed := errors.New("failed")
+	var runs atomic.Int32
+	reported := make(chan error, 1)
+	a := NewAutoRefresh(context.Background(), func(ctx context.Context) (any, error) {
+		if runs.Add(1) > 1 {
+			return nil, errFailed
+		}
+		return "token", nil
+	}, time.Minute, WithRefreshClock(clock), WithOnRefreshError(func(err error) {
+		reported <- err
+	}))
+	defer a.Close()
+
+	a.Result()
+	clock.Advance(time.Minute)
+	if err := <-reported; !errors.Is(err, errFailed) {
+		t.Fatalf("expected errFailed to be reported, got %v", err)
+	}
+	if v, err := a.Result(); v != "token" || err != nil {
+		t.Fatalf("expected the previous value, got %v, %v", v, err)
+	}
+}
+
+func TestAutoRefresh_Schedule(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	var runs atomic.Int32
+	var prevs []any
+	a := NewAutoRefresh(context.Background(), func(ctx context.Context) (any, error) {
+		return runs.Add(1), nil
+	}, time.Hour, WithRefreshClock(clock), WithRefreshSchedule(func(prev any) time.Duration {
+		prevs = append(prevs, prev)
+		return time.Second
+	}))
+
+	a.Result()
+	clock.BlockUntil(1)
+	a.Close()
+	if n := clock.Timers(); n != 0 {
+		t.Fatalf("expected Close to stop the refresher, got %d timers", n)
+	}
+	if len(prevs) != 1 || prevs[0] != int32(1) {
+		t.Fatalf("expected the schedule to see the first value, got %v", prevs)
+	}
+}
+
+func TestAutoRefresh_FirstFillFails(t *testing.T) {
+	errFailed := errors.New("failed")
+	a := NewAutoRefresh(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, errFailed
+	}, time.Hour)
+	defer a.Close()
+	if _, err := a.Result(); !errors.Is(err, errFailed) {
+		t.Fatalf("expected errFailed, got %v", err)
+	}
+}
+
+func TestAutoRefresh_StopsWithContext(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	a := NewAutoRefresh(ctx, func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, time.Minute, WithRefreshClock(clock))
+
+	<-started
+	cancel()
+	if _, err := a.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// The refresher stops once ctx is done
+	for clock.Timers() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
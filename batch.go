@@ -0,0 +1,180 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrMissingKey is returned by a Batcher's futures whose key the batch
+// function left out of its result.
+var ErrMissingKey = errors.New("key missing from batch result")
+
+// BatcherOption defines functional options for Batcher.
+type BatcherOption func(*batcherConfig)
+
+// batcherConfig is the part of a Batcher that options set, kept apart so
+// options need no type parameters.
+type batcherConfig struct {
+	clock Clock
+}
+
+// WithBatchClock makes the batcher read time from c instead of the real
+// clock.
+func WithBatchClock(c Clock) BatcherOption {
+	return func(cfg *batcherConfig) {
+		cfg.clock = c
+	}
+}
+
+// Batcher coalesces individual lookups of values of type V by keys of type
+// K into batched calls, like a dataloader. Loads are collected until
+// maxBatch distinct keys are pending or maxWait has passed since the first
+// of them, and are then flushed in one call of the batch function.
+type Batcher[K comparable, V any] struct {
+	batcherConfig
+	maxBatch int
+	maxWait  time.Duration
+	batchFn  func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []*batchLoad[K]
+	keys    int
+	timer   Timer
+}
+
+// batchLoad is a Load waiting for its batch.
+type batchLoad[K comparable] struct {
+	ctx    context.Context
+	key    K
+	future *Future
+}
+
+// NewBatcher creates a Batcher. batchFn returns a value for each key it is
+// given; a value that is an error, when V is an interface type, fails that
+// key's futures alone, and an error from batchFn fails the whole batch. A
+// maxBatch of zero or less means no size bound.
+func NewBatcher[K comparable, V any](maxBatch int, maxWait time.Duration, batchFn func(ctx context.Context, keys []K) (map[K]V, error), opts ...BatcherOption) *Batcher[K, V] {
+	b := &Batcher[K, V]{maxBatch: maxBatch, maxWait: maxWait, batchFn: batchFn}
+	for _, opt := range opts {
+		opt(&b.batcherConfig)
+	}
+	return b
+}
+
+// Load adds key to the pending batch and returns a future for its value at
+// once. Loads of the same key in one batch share a slot. Aborting the
+// future, or ctx being done, before the batch flushes removes the load from
+// the batch; afterwards, the batch runs to completion for its other loads.
+func (b *Batcher[K, V]) Load(ctx context.Context, key K) *Future {
+	ctx = orBackground(ctx)
+	f := newFuture(ctx, nil)
+	f.once.Do(func() {})
+	load := &batchLoad[K]{ctx: ctx, key: key, future: f}
+
+	var batch []*batchLoad[K]
+	b.mu.Lock()
+	if !slices.ContainsFunc(b.pending, func(l *batchLoad[K]) bool { return l.key == key }) {
+		b.keys++
+	}
+	b.pending = append(b.pending, load)
+	if b.maxBatch > 0 && b.keys >= b.maxBatch {
+		batch = b.take()
+	} else if b.timer == nil {
+		b.timer = b.afterFunc(b.maxWait, b.Flush)
+	}
+	b.mu.Unlock()
+
+	f.whenDone(func() {
+		b.remove(load)
+	})
+	context.AfterFunc(f.ctx, func() {
+		f.settle(nil, context.Cause(f.ctx))
+	})
+	if batch != nil {
+		b.run(batch)
+	}
+	return f
+}
+
+// Flush sends the pending loads as a batch without waiting for maxBatch or
+// maxWait.
+func (b *Batcher[K, V]) Flush() {
+	b.mu.Lock()
+	batch := b.take()
+	b.mu.Unlock()
+	if len(batch) > 0 {
+		b.run(batch)
+	}
+}
+
+// take removes and returns the pending loads. The caller holds b.mu.
+func (b *Batcher[K, V]) take() []*batchLoad[K] {
+	batch := b.pending
+	b.pending, b.keys = nil, 0
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	return batch
+}
+
+// remove drops a settled load from the pending batch, if it is still there.
+func (b *Batcher[K, V]) remove(load *batchLoad[K]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	i := slices.Index(b.pending, load)
+	if i < 0 {
+		return
+	}
+	b.pending = slices.Delete(b.pending, i, i+1)
+	if !slices.ContainsFunc(b.pending, func(l *batchLoad[K]) bool { return l.key == load.key }) {
+		b.keys--
+	}
+	if len(b.pending) == 0 && b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+}
+
+// run calls the batch function for batch in a future of its own, so a
+// panic fails the batch instead of crashing, and settles every load.
+func (b *Batcher[K, V]) run(batch []*batchLoad[K]) {
+	var keys []K
+	for _, load := range batch {
+		if !slices.Contains(keys, load.key) {
+			keys = append(keys, load.key)
+		}
+	}
+	f := NewFuture(context.WithoutCancel(batch[0].ctx), func(ctx context.Context) (any, error) {
+		return b.batchFn(ctx, keys)
+	})
+	f.whenDone(func() {
+		values, err := f.peek()
+		for _, load := range batch {
+			if err != nil {
+				load.future.settle(nil, err)
+				continue
+			}
+			value, ok := values.(map[K]V)[load.key]
+			if !ok {
+				load.future.settle(nil, fmt.Errorf("%w: %#v", ErrMissingKey, load.key))
+			} else if err, ok := any(value).(error); ok {
+				load.future.settle(nil, err)
+			} else {
+				load.future.settle(value, nil)
+			}
+		}
+	})
+}
+
+// afterFunc calls fn after d on the batcher's clock.
+func (b *Batcher[K, V]) afterFunc(d time.Duration, fn func()) Timer {
+	if b.clock == nil {
+		return time.AfterFunc(d, fn)
+	}
+	return b.clock.AfterFunc(d, fn)
+}
@@ -0,0 +1,155 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+// recordingBatchFn returns the batch function of a test Batcher, which
+// records every batch it is called with.
+func recordingBatchFn(batches *[][]string, mu *sync.Mutex) func(context.Context, []string) (map[string]any, error) {
+	return func(ctx context.Context, keys []string) (map[string]any, error) {
+		mu.Lock()
+		*batches = append(*batches, keys)
+		mu.Unlock()
+		values := make(map[string]any)
+		for _, key := range keys {
+			switch key {
+			case "missing":
+			case "bad":
+				values[key] = errors.New("bad key")
+			default:
+				values[key] = "value:" + key
+			}
+		}
+		return values, nil
+	}
+}
+
+func TestBatcher_FlushesOnMaxBatch(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	b := NewBatcher(3, time.Hour, recordingBatchFn(&batches, &mu))
+
+	a1 := b.Load(context.Background(), "a")
+	a2 := b.Load(context.Background(), "a")
+	bf := b.Load(context.Background(), "b")
+	c := b.Load(context.Background(), "c")
+	for f, want := range map[*Future]string{a1: "value:a", a2: "value:a", bf: "value:b", c: "value:c"} {
+		if v, err := f.Result(); v != want || err != nil {
+			t.Fatalf("expected %s, got %v, %v", want, v, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 1 || !slices.Equal(batches[0], []string{"a", "b", "c"}) {
+		t.Fatalf("expected one batch of distinct keys, got %v", batches)
+	}
+}
+
+func TestBatcher_FlushesOnMaxWait(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	var mu sync.Mutex
+	var batches [][]string
+	b := NewBatcher(10, 5*time.Millisecond, recordingBatchFn(&batches, &mu), WithBatchClock(clock))
+
+	f := b.Load(context.Background(), "a")
+	clock.Advance(4 * time.Millisecond)
+	if f.Ready() {
+		t.Fatal("expected the batch to wait for maxWait")
+	}
+	clock.Advance(time.Millisecond)
+	if v, _ := f.Result(); v != "value:a" {
+		t.Fatalf("expected value:a, got %v", v)
+	}
+}
+
+func TestBatcher_PerKeyErrors(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	b := NewBatcher(0, time.Hour, recordingBatchFn(&batches, &mu))
+
+	ok := b.Load(context.Background(), "ok")
+	bad := b.Load(context.Background(), "bad")
+	missing := b.Load(context.Background(), "missing")
+	b.Flush()
+
+	if v, err := ok.Result(); v != "value:ok" || err != nil {
+		t.Fatalf("expected value:ok, got %v, %v", v, err)
+	}
+	if _, err := bad.Result(); err == nil || err.Error() != "bad key" {
+		t.Fatalf("expected the key's own error, got %v", err)
+	}
+	if _, err := missing.Result(); !errors.Is(err, ErrMissingKey) {
+		t.Fatalf("expected ErrMissingKey, got %v", err)
+	}
+}
+
+func TestBatcher_BatchError(t *testing.T) {
+	errFailed := errors.New("failed")
+	b := NewBatcher(2, time.Hour, func(ctx context.Context, keys []string) (map[string]any, error) {
+		return nil, errFailed
+	})
+	first := b.Load(context.Background(), "a")
+	second := b.Load(context.Background(), "b")
+	for _, f := range []*Future{first, second} {
+		if _, err := f.Result(); !errors.Is(err, errFailed) {
+			t.Fatalf("expected errFailed, got %v", err)
+		}
+	}
+}
+
+func TestBatcher_AbortRemovesLoad(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	b := NewBatcher(2, time.Hour, recordingBatchFn(&batches, &mu))
+
+	aborted := b.Load(context.Background(), "a")
+	aborted.Abort()
+
+	// The aborted key no longer counts towards maxBatch
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := b.Load(ctx, "b")
+	cancel()
+	if _, err := cancelled.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	c := b.Load(context.Background(), "c")
+	d := b.Load(context.Background(), "d")
+	c.Result()
+	d.Result()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 1 || !slices.Equal(batches[0], []string{"c", "d"}) {
+		t.Fatalf("expected aborted loads to be removed, got %v", batches)
+	}
+}
+
+func TestBatcher_Typed(t *testing.T) {
+	b := NewBatcher(2, time.Hour, func(ctx context.Context, ids []int) (map[int]string, error) {
+		names := make(map[int]string)
+		for _, id := range ids {
+			if id > 0 {
+				names[id] = fmt.Sprintf("user %d", id)
+			}
+		}
+		return names, nil
+	})
+	found := b.Load(context.Background(), 7)
+	missing := b.Load(context.Background(), -1)
+	if v, err := found.Result(); v != "user 7" || err != nil {
+		t.Fatalf("expected user 7, got %v, %v", v, err)
+	}
+	if _, err := missing.Result(); !errors.Is(err, ErrMissingKey) || err.Error() != "key missing from batch result: -1" {
+		t.Fatalf("expected ErrMissingKey for -1, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package A
+
+import "context"
+
+// Checkpoint returns nil while ctx is live and, once it is done, the cause
+// it was cancelled with, such as an AbortWithError error or a
+// WithTimeoutCause cause. Tasks call it between phases of their work:
+//
+//	if err := A.Checkpoint(ctx); err != nil {
+//		return nil, err
+//	}
+func Checkpoint(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return context.Cause(ctx)
+}
+
+// CheckpointEvery returns a function for loop bodies that checks ctx like
+// Checkpoint on every nth call only, so a hot loop pays for a counter
+// increment on the others. An n below 1 checks on every call. The returned
+// function is not safe for concurrent use.
+func CheckpointEvery(ctx context.Context, n int) func() error {
+	n = max(n, 1)
+	calls := 0
+	return func() error {
+		calls++
+		if calls < n {
+			return nil
+		}
+		calls = 0
+		return Checkpoint(ctx)
+	}
+}
@@ -0,0 +1,73 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	if err := Checkpoint(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	errShutdown := errors.New("shutdown")
+	cancel(errShutdown)
+	if err := Checkpoint(ctx); err != errShutdown {
+		t.Fatalf("expected the cause, got %v", err)
+	}
+
+	// Inside a future, an abort's cause comes through
+	errStop := errors.New("stop")
+	started := make(chan struct{})
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, Checkpoint(ctx)
+	})
+	<-started
+	f.AbortWithError(errStop)
+	if _, err := f.Result(); !errors.Is(err, errStop) {
+		t.Fatalf("expected the abort cause, got %v", err)
+	}
+}
+
+func TestCheckpointEvery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	check := CheckpointEvery(ctx, 3)
+	cancel()
+
+	// Only every third call looks at the context
+	for i := 1; i <= 6; i++ {
+		err := check()
+		if (i%3 == 0) != errors.Is(err, context.Canceled) {
+			t.Fatalf("call %d: unexpected %v", i, err)
+		}
+	}
+
+	always := CheckpointEvery(ctx, 0)
+	if err := always(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected every call to check, got %v", err)
+	}
+}
+
+func BenchmarkCheckpoint(b *testing.B) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for i := 0; i < b.N; i++ {
+		if err := Checkpoint(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCheckpointEvery(b *testing.B) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	check := CheckpointEvery(ctx, 64)
+	for i := 0; i < b.N; i++ {
+		if err := check(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
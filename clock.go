@@ -0,0 +1,93 @@
+package A
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time for a future's timestamps, timeouts, slow
+// warnings, and stall detection. Tests can pass a fake one with WithClock;
+// testutil.FakeClock is one.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f once d has elapsed, like time.AfterFunc.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by a Clock. *time.Timer satisfies it.
+type Timer = interface {
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// WithClock makes the future read time from c instead of the real clock.
+// A nil c means the real clock.
+func WithClock(c Clock) Option {
+	return func(f *Future) {
+		f.clock = c
+	}
+}
+
+// now returns the current time on the future's clock.
+func (f *Future) now() time.Time {
+	if f.clock == nil {
+		return time.Now()
+	}
+	return f.clock.Now()
+}
+
+// since returns the time elapsed since t on the future's clock.
+func (f *Future) since(t time.Time) time.Duration {
+	return f.now().Sub(t)
+}
+
+// afterFunc calls fn after d on the future's clock.
+func (f *Future) afterFunc(d time.Duration, fn func()) Timer {
+	if f.clock == nil {
+		return time.AfterFunc(d, fn)
+	}
+	return f.clock.AfterFunc(d, fn)
+}
+
+// withTimeout is context.WithTimeout on the future's clock.
+func (f *Future) withTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	return f.withTimeoutCause(parent, d, nil)
+}
+
+// withTimeoutCause is context.WithTimeoutCause on the future's clock.
+func (f *Future) withTimeoutCause(parent context.Context, d time.Duration, cause error) (context.Context, context.CancelFunc) {
+	if f.clock == nil {
+		return context.WithTimeoutCause(parent, d, cause)
+	}
+	if cause == nil {
+		cause = context.DeadlineExceeded
+	}
+	ctx, cancel := context.WithCancelCause(parent)
+	timer := f.clock.AfterFunc(d, func() {
+		cancel(cause)
+	})
+	return &clockCtx{Context: ctx, deadline: f.clock.Now().Add(d), cause: cause}, func() {
+		timer.Stop()
+		cancel(context.Canceled)
+	}
+}
+
+// clockCtx is a context whose deadline is kept by a Clock. cause is what
+// the context is cancelled with when the deadline passes.
+type clockCtx struct {
+	context.Context
+	deadline time.Time
+	cause    error
+}
+
+func (c *clockCtx) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *clockCtx) Err() error {
+	err := c.Context.Err()
+	if err != nil && context.Cause(c.Context) == c.cause {
+		return context.DeadlineExceeded
+	}
+	return err
+}
@@ -0,0 +1,210 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// Completion is what a settled future produced and how it got there, in one
+// shape shared by Future.Completion, OnComplete, Group results,
+// CompletionQueue.Next, and CompletionMetrics.
+type Completion struct {
+	// Future is the future that settled.
+	Future  *Future
+	Value   any
+	Err     error
+	Outcome Outcome
+	Meta    Meta
+}
+
+// Succeeded reports whether the future settled with a nil error.
+func (c Completion) Succeeded() bool {
+	return c.Outcome == Succeeded
+}
+
+// TimedOut reports whether the task ran into its timeout.
+func (c Completion) TimedOut() bool {
+	return c.Outcome == TimedOut
+}
+
+// Aborted reports whether the future was settled by Abort or
+// AbortWithError.
+func (c Completion) Aborted() bool {
+	return c.Outcome == Aborted
+}
+
+// Panicked reports whether the task panicked.
+func (c Completion) Panicked() bool {
+	return c.Outcome == Panicked
+}
+
+// Completion waits like Result and returns the result with how the future
+// settled.
+func (f *Future) Completion() Completion {
+	f.consume()
+	f.once.Do(f.start)
+	if !f.ready.Load() {
+		<-f.doneChan()
+	}
+	return f.completion()
+}
+
+// OnComplete calls fn with the future's Completion once it settles, or at
+// once if it already has. fn runs on the settling goroutine before waiters
+// are released, so it should be quick. OnComplete does not start a lazy
+// future.
+func (f *Future) OnComplete(fn func(Completion)) {
+	f.consume()
+	f.whenDone(func() {
+		fn(f.completion())
+	})
+}
+
+// completion returns the Completion of a settled future.
+func (f *Future) completion() Completion {
+	f.consume()
+	f.mu.Lock()
+	c := Completion{Future: f, Outcome: f.outcome, Meta: f.meta()}
+	c.Value, c.Err = f.take()
+	f.mu.Unlock()
+	c.Value = f.cloned(c.Value)
+	return c
+}
+
+// settledCompletion returns the Completion of a settled future for
+// CompletionMetrics, leaving a single-consumer value in place.
+func (f *Future) settledCompletion() Completion {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return Completion{Future: f, Value: f.item, Err: f.err, Outcome: f.outcome, Meta: f.meta()}
+}
+
+// ErrCompletionQueueClosed is returned by Add after Close, and by Next once
+// a closed queue has been drained.
+var ErrCompletionQueueClosed = errors.New("completion queue is closed")
+
+// CompletionQueue hands out the Completions of futures in the order they
+// settle, like
+// asyncio.as_completed, while futures may be added or removed at any time.
+// It waits on settlement hooks rather than a goroutine per future.
+type CompletionQueue struct {
+	mu      sync.Mutex
+	pending map[*Future]struct{}
+	ready   []*Future
+	closed  bool
+	// changed is closed and replaced whenever a future becomes ready or
+	// the queue is closed.
+	changed chan struct{}
+}
+
+// NewCompletionQueue creates an empty CompletionQueue.
+func NewCompletionQueue() *CompletionQueue {
+	return &CompletionQueue{
+		pending: make(map[*Future]struct{}),
+		changed: make(chan struct{}),
+	}
+}
+
+// Add adds f to the queue, starting it if it is lazy. Adding a future that
+// is already in the queue has no effect.
+func (q *CompletionQueue) Add(f *Future) error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return ErrCompletionQueueClosed
+	}
+	if _, ok := q.pending[f]; ok || slices.Contains(q.ready, f) {
+		q.mu.Unlock()
+		return nil
+	}
+	q.pending[f] = struct{}{}
+	q.mu.Unlock()
+
+	f.whenDone(func() {
+		q.settled(f)
+	})
+	f.once.Do(f.start)
+	return nil
+}
+
+// Remove takes f out of the queue, whether or not it has settled, and
+// reports whether it was there.
+func (q *CompletionQueue) Remove(f *Future) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.pending[f]; ok {
+		delete(q.pending, f)
+		return true
+	}
+	if i := slices.Index(q.ready, f); i >= 0 {
+		q.ready = slices.Delete(q.ready, i, i+1)
+		return true
+	}
+	return false
+}
+
+// Next returns the Completion of the next future to settle, waiting for one
+// if none has; its Future field tells which future it was. If ctx is done
+// first, it returns ctx's cause. Once the queue is closed and every future
+// in it has been returned, it returns ErrCompletionQueueClosed.
+func (q *CompletionQueue) Next(ctx context.Context) (Completion, error) {
+	for {
+		q.mu.Lock()
+		if len(q.ready) > 0 {
+			f := q.ready[0]
+			q.ready = slices.Delete(q.ready, 0, 1)
+			q.mu.Unlock()
+			return f.completion(), nil
+		}
+		if q.closed && len(q.pending) == 0 {
+			q.mu.Unlock()
+			return Completion{}, ErrCompletionQueueClosed
+		}
+		changed := q.changed
+		q.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return Completion{}, context.Cause(ctx)
+		}
+	}
+}
+
+// Len returns the number of futures in the queue, settled or not.
+func (q *CompletionQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending) + len(q.ready)
+}
+
+// Close stops the queue from accepting futures. Next keeps returning the
+// futures already in it as they settle.
+func (q *CompletionQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		q.notify()
+	}
+}
+
+// settled moves f from pending to ready, unless it has been removed.
+func (q *CompletionQueue) settled(f *Future) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.pending[f]; !ok {
+		return
+	}
+	delete(q.pending, f)
+	q.ready = append(q.ready, f)
+	q.notify()
+}
+
+// notify wakes every waiting Next. The caller holds q.mu.
+func (q *CompletionQueue) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
@@ -0,0 +1,163 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFuture_Completion(t *testing.T) {
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "done", nil
+	}, WithName("load"))
+	c := f.Completion()
+	if c.Value != "done" || c.Err != nil || !c.Succeeded() || c.TimedOut() || c.Meta.Name != "load" || c.Meta.Attempts != 1 {
+		t.Fatalf("unexpected completion %+v", c)
+	}
+
+	timedOut := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, WithTimeout(time.Millisecond))
+	if c := timedOut.Completion(); !c.TimedOut() || c.Succeeded() || !IsTimeout(c.Err) || c.Outcome != c.Meta.Outcome {
+		t.Fatalf("unexpected timed out completion %+v", c)
+	}
+}
+
+func TestFuture_OnComplete(t *testing.T) {
+	release := make(chan struct{})
+	completions := make(chan Completion, 2)
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-release
+		return nil, errors.New("failed")
+	})
+	f.OnComplete(func(c Completion) {
+		completions <- c
+	})
+	select {
+	case c := <-completions:
+		t.Fatalf("expected no completion before settling, got %+v", c)
+	default:
+	}
+	close(release)
+	if c := <-completions; c.Outcome != Failed || c.Err == nil {
+		t.Fatalf("unexpected completion %+v", c)
+	}
+
+	// Registering on a settled future calls fn at once
+	f.OnComplete(func(c Completion) {
+		completions <- c
+	})
+	if c := <-completions; c.Outcome != Failed {
+		t.Fatalf("unexpected completion %+v", c)
+	}
+
+	// A lazy future is not started
+	lazy := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "lazy", nil
+	}, WithLazy())
+	lazy.OnComplete(func(c Completion) {
+		completions <- c
+	})
+	if lazy.State() != Pending {
+		t.Fatalf("expected OnComplete not to start the future")
+	}
+	lazy.Result()
+	if c := <-completions; c.Value != "lazy" {
+		t.Fatalf("unexpected completion %+v", c)
+	}
+}
+
+// gated returns a future that completes with v once release is closed.
+func gated(release chan struct{}, v any) *Future {
+	return NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-release
+		return v, nil
+	})
+}
+
+func TestCompletionQueue_Order(t *testing.T) {
+	q := NewCompletionQueue()
+	first, second := make(chan struct{}), make(chan struct{})
+	a := gated(first, "a")
+	b := gated(second, "b")
+	q.Add(a)
+	q.Add(b)
+	if q.Len() != 2 {
+		t.Fatalf("expected 2 futures, got %d", q.Len())
+	}
+
+	close(second)
+	if c, err := q.Next(context.Background()); c.Future != b || c.Value != "b" || err != nil {
+		t.Fatalf("expected b to come first, got %v, %v", c, err)
+	}
+
+	// Futures can be added while waiting
+	c := newSettled(context.Background(), "c", nil)
+	q.Add(c)
+	if got, _ := q.Next(context.Background()); got.Future != c {
+		t.Fatalf("expected c, got %v", got)
+	}
+	close(first)
+	if got, _ := q.Next(context.Background()); got.Future != a || !got.Succeeded() {
+		t.Fatalf("expected a, got %v", got)
+	}
+	if q.Len() != 0 {
+		t.Fatalf("expected an empty queue, got %d", q.Len())
+	}
+}
+
+func TestCompletionQueue_Remove(t *testing.T) {
+	q := NewCompletionQueue()
+	release := make(chan struct{})
+	a := gated(release, "a")
+	b := newSettled(context.Background(), "b", nil)
+	q.Add(a)
+	q.Add(b)
+	if !q.Remove(a) || !q.Remove(b) {
+		t.Fatal("expected both futures to be removed")
+	}
+	if q.Remove(a) {
+		t.Fatal("expected a second Remove to report false")
+	}
+	close(release)
+	a.Result()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if c, err := q.Next(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected removed futures not to be returned, got %v, %v", c, err)
+	}
+}
+
+func TestCompletionQueue_Close(t *testing.T) {
+	q := NewCompletionQueue()
+	release := make(chan struct{})
+	a := gated(release, "a")
+	q.Add(a)
+	q.Close()
+	if err := q.Add(newSettled(context.Background(), nil, nil)); !errors.Is(err, ErrCompletionQueueClosed) {
+		t.Fatalf("expected ErrCompletionQueueClosed, got %v", err)
+	}
+
+	// A closed queue still hands out the futures it holds
+	close(release)
+	if got, _ := q.Next(context.Background()); got.Future != a || !got.Succeeded() {
+		t.Fatalf("expected a, got %v", got)
+	}
+	if _, err := q.Next(context.Background()); !errors.Is(err, ErrCompletionQueueClosed) {
+		t.Fatalf("expected ErrCompletionQueueClosed, got %v", err)
+	}
+}
+
+func TestCompletionQueue_StartsLazy(t *testing.T) {
+	q := NewCompletionQueue()
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "lazy", nil
+	}, WithLazy())
+	q.Add(f)
+	if got, _ := q.Next(context.Background()); got.Future != f || got.Value != "lazy" {
+		t.Fatalf("expected the lazy future, got %v", got)
+	}
+}
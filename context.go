@@ -0,0 +1,174 @@
+package A
+
+import (
+	"context"
+	"slices"
+)
+
+// orBackground returns ctx, or context.Background if ctx is nil, so
+// constructors given a nil context behave as if given Background rather
+// than panicking deep inside the context package.
+func orBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+// WithTaskContext derives the context the task runs under from the context
+// the future was created with, e.g. to strip its deadline with
+// context.WithoutCancel. Abort still cancels whatever fn returns. Repeated
+// uses compose in order.
+func WithTaskContext(fn func(parent context.Context) context.Context) Option {
+	return func(f *Future) {
+		if fn == nil {
+			f.invalidOption("WithTaskContext", "nil function")
+			return
+		}
+		prev := f.taskContext
+		f.taskContext = func(ctx context.Context) context.Context {
+			if prev != nil {
+				ctx = prev(ctx)
+			}
+			return fn(ctx)
+		}
+	}
+}
+
+// WithDetachedContext keeps the cancellation and deadline of the context the
+// future was created with from reaching the task, for fire-and-forget work
+// that must outlive a request. Values, including trace spans, still
+// propagate; only Abort and the future's own WithTimeout stop the task.
+func WithDetachedContext() Option {
+	return WithTaskContext(context.WithoutCancel)
+}
+
+// WithRunOnCancelled runs the task even if its context is already done when
+// it is about to start. By default such a future fails with the context's
+// cause without calling the task, which saves work for stale queued tasks.
+func WithRunOnCancelled() Option {
+	return func(f *Future) {
+		f.runOnDone = true
+	}
+}
+
+// WithContextPropagation copies the values of keys from the context the
+// future was created with onto the task context, so request and trace IDs
+// survive a WithTaskContext that starts from a fresh context.
+func WithContextPropagation(keys ...any) Option {
+	return func(f *Future) {
+		if slices.Contains(keys, nil) {
+			f.invalidOption("WithContextPropagation", "nil key")
+			return
+		}
+		f.propagate = append(f.propagate, keys...)
+	}
+}
+
+// WithContextDecorator applies fn to the task context after WithTaskContext
+// and WithContextPropagation, for libraries that carry request state in
+// values only they can copy. Decorators apply in order.
+func WithContextDecorator(fn func(context.Context) context.Context) Option {
+	return func(f *Future) {
+		if fn == nil {
+			f.invalidOption("WithContextDecorator", "nil decorator")
+			return
+		}
+		f.decorators = append(f.decorators, fn)
+	}
+}
+
+// WithoutAbort runs the task directly under the context the future was
+// created with, saving the allocations of a cancellable context for futures
+// that are never aborted. Abort still settles the future, but cannot cancel
+// a running task; only the parent context and WithTimeout can.
+func WithoutAbort() Option {
+	return func(f *Future) {
+		f.noAbort = true
+	}
+}
+
+// WithValues makes the values of parent visible to the task, without its
+// cancellation or deadline. Values from parent take precedence over values
+// of the same key in the context the future was created with.
+func WithValues(parent context.Context) Option {
+	if parent == nil {
+		return func(f *Future) {
+			f.invalidOption("WithValues", "nil context")
+		}
+	}
+	return WithTaskContext(func(ctx context.Context) context.Context {
+		return valuesContext{Context: ctx, values: parent}
+	})
+}
+
+// BindToContext aborts each of fs with ctx's cause once ctx is done, such as
+// when an HTTP client disconnects, so eager futures a handler started stop
+// computing once nobody wants their results. A future that settles first
+// drops its registration with ctx, so a long-lived ctx does not accumulate
+// them. Futures bound to a ctx that is already done are aborted at once.
+func BindToContext(ctx context.Context, fs ...*Future) {
+	ctx = orBackground(ctx)
+	if ctx.Err() != nil {
+		for _, f := range fs {
+			f.AbortWithError(context.Cause(ctx))
+		}
+		return
+	}
+	for _, f := range fs {
+		stop := context.AfterFunc(ctx, func() {
+			f.AbortWithError(context.Cause(ctx))
+		})
+		f.whenDone(func() {
+			stop()
+		})
+	}
+}
+
+// Context returns the context the task runs under, which is done once the
+// future is aborted or settles, unless it was created with WithoutAbort. It
+// is valid before a lazy future starts. The per-run WithTimeout deadline is
+// not part of it. Use Abort to stop the task rather than anything found in
+// the context.
+func (f *Future) Context() context.Context {
+	return f.ctx
+}
+
+// valuesContext overlays the values of another context.
+type valuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c valuesContext) Value(key any) any {
+	if v := c.values.Value(key); v != nil {
+		return v
+	}
+	return c.Context.Value(key)
+}
+
+// taskCtx derives the task context from parent with the WithTaskContext
+// functions, then copies the propagated values and applies the decorators.
+func (f *Future) taskCtx(parent context.Context) context.Context {
+	ctx := parent
+	if f.taskContext != nil {
+		if ctx = f.taskContext(parent); ctx == nil {
+			f.invalidOption("WithTaskContext", "function returned a nil context")
+			ctx = parent
+		}
+	}
+	for _, key := range f.propagate {
+		if v := parent.Value(key); v != nil {
+			ctx = context.WithValue(ctx, key, v)
+		}
+	}
+	for _, decorate := range f.decorators {
+		next := decorate(ctx)
+		if next == nil {
+			f.invalidOption("WithContextDecorator", "decorator returned a nil context")
+			continue
+		}
+		ctx = next
+	}
+	return ctx
+}
@@ -0,0 +1,311 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type ctxKey string
+
+func TestWithTaskContextStripsCancellation(t *testing.T) {
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey("k"), "v"))
+	started := make(chan struct{})
+	f := NewFuture(parent, func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return ctx.Value(ctxKey("k")), context.Cause(ctx)
+	}, WithTaskContext(context.WithoutCancel))
+	<-started
+	cancel()
+	select {
+	case <-f.Done():
+		t.Fatalf("task context was cancelled with its parent")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	ctx, stop := context.WithTimeout(context.Background(), time.Second)
+	defer stop()
+	if err := f.AbortAndWait(ctx); err != nil {
+		t.Fatalf("expected abort to cancel the task context, got %v", err)
+	}
+	if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWithValues(t *testing.T) {
+	request, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey("request"), "r"))
+	cancel()
+	base := context.WithValue(context.Background(), ctxKey("base"), "b")
+	f := NewFuture(base, func(ctx context.Context) (any, error) {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return [2]any{ctx.Value(ctxKey("request")), ctx.Value(ctxKey("base"))}, nil
+	}, WithValues(request))
+	v, err := f.Result()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != [2]any{"r", "b"} {
+		t.Fatalf("unexpected values: %v", v)
+	}
+}
+
+func TestWithTaskContextNil(t *testing.T) {
+	_, err := NewFutureE(context.Background(), func(context.Context) (any, error) {
+		return nil, nil
+	}, WithTaskContext(func(context.Context) context.Context { return nil }))
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestWithDetachedContext(t *testing.T) {
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey("k"), "v"))
+	cancel()
+	f := NewFuture(parent, func(ctx context.Context) (any, error) {
+		time.Sleep(10 * time.Millisecond)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return ctx.Value(ctxKey("k")), nil
+	}, WithDetachedContext())
+	if v, err := f.Result(); v != "v" || err != nil {
+		t.Fatalf("expected detached task to complete, got %v, %v", v, err)
+	}
+}
+
+func TestWithDetachedContextTimeout(t *testing.T) {
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, WithDetachedContext(), WithTimeout(10*time.Millisecond))
+	if _, err := f.Result(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the future's own timeout to apply, got %v", err)
+	}
+}
+
+func TestCancelledBeforeStart(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cause := errors.New("client went away")
+	ran := false
+	f := NewFuture(ctx, func(context.Context) (any, error) {
+		ran = true
+		return nil, nil
+	}, WithLazy())
+	cancel(cause)
+	if _, err := f.Result(); err != cause {
+		t.Fatalf("expected the context cause, got %v", err)
+	}
+	if ran {
+		t.Fatalf("task ran under a cancelled context")
+	}
+}
+
+func TestWithRunOnCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f := NewFuture(ctx, func(ctx context.Context) (any, error) {
+		return "ran", nil
+	}, WithRunOnCancelled())
+	if v, err := f.Result(); v != "ran" || err != nil {
+		t.Fatalf("expected task to run, got %v, %v", v, err)
+	}
+}
+
+func TestFutureContext(t *testing.T) {
+	parent := context.WithValue(context.Background(), ctxKey("k"), "v")
+	var taskCtx context.Context
+	f := NewFuture(parent, func(ctx context.Context) (any, error) {
+		taskCtx = ctx
+		return nil, nil
+	}, WithLazy())
+	ctx := f.Context()
+	if ctx.Value(ctxKey("k")) != "v" || ctx.Err() != nil {
+		t.Fatalf("unexpected context before start: %v", ctx)
+	}
+	cleaned := make(chan struct{})
+	context.AfterFunc(ctx, func() {
+		close(cleaned)
+	})
+	f.Result()
+	if taskCtx != ctx {
+		t.Fatalf("Context differs from the context the task ran under")
+	}
+	select {
+	case <-cleaned:
+	case <-time.After(time.Second):
+		t.Fatalf("context not done after the future settled")
+	}
+}
+
+func TestWithContextPropagation(t *testing.T) {
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey("request-id"), "r1"))
+	cancel()
+	fresh := WithTaskContext(func(context.Context) context.Context {
+		return context.Background()
+	})
+	task := func(ctx context.Context) (any, error) {
+		return ctx.Value(ctxKey("request-id")), nil
+	}
+	if v, _ := NewFuture(parent, task, fresh).Result(); v != nil {
+		t.Fatalf("expected value to be dropped without propagation, got %v", v)
+	}
+	if v, _ := NewFuture(parent, task, fresh, WithContextPropagation(ctxKey("request-id"))).Result(); v != "r1" {
+		t.Fatalf("expected propagated value, got %v", v)
+	}
+
+	p := NewPool(1)
+	defer p.Shutdown(context.Background())
+	f := p.Submit(parent, task, WithDetachedContext(), WithContextPropagation(ctxKey("request-id")))
+	if v, err := f.Result(); v != "r1" || err != nil {
+		t.Fatalf("expected value to survive pool submission, got %v, %v", v, err)
+	}
+}
+
+func TestWithContextDecorator(t *testing.T) {
+	parent := context.WithValue(context.Background(), ctxKey("carrier"), "c")
+	f := NewFuture(parent, func(ctx context.Context) (any, error) {
+		return ctx.Value(ctxKey("copy")), nil
+	}, WithTaskContext(func(context.Context) context.Context {
+		return context.Background()
+	}), WithContextPropagation(ctxKey("carrier")), WithContextDecorator(func(ctx context.Context) context.Context {
+		// Decorators see the propagated values.
+		return context.WithValue(ctx, ctxKey("copy"), ctx.Value(ctxKey("carrier")))
+	}))
+	if v, err := f.Result(); v != "c" || err != nil {
+		t.Fatalf("unexpected result: %v, %v", v, err)
+	}
+}
+
+// countingCtx counts the AfterFunc registrations still outstanding.
+type countingCtx struct {
+	context.Context
+	live atomic.Int32
+}
+
+// Value hides the embedded context's values, which would otherwise let the
+// context package bypass AfterFunc and hook into the parent directly.
+func (c *countingCtx) Value(key any) any {
+	return nil
+}
+
+func (c *countingCtx) AfterFunc(fn func()) func() bool {
+	c.live.Add(1)
+	stop := context.AfterFunc(c.Context, func() {
+		c.live.Add(-1)
+		fn()
+	})
+	return func() bool {
+		stopped := stop()
+		if stopped {
+			c.live.Add(-1)
+		}
+		return stopped
+	}
+}
+
+func TestBindToContext_CancelThenSettle(t *testing.T) {
+	parent, cancel := context.WithCancelCause(context.Background())
+	blocked := func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	f := NewFuture(context.Background(), blocked)
+	lazy := NewFuture(context.Background(), blocked, WithLazy())
+	BindToContext(parent, f, lazy)
+
+	errGone := errors.New("client disconnected")
+	cancel(errGone)
+	for _, f := range []*Future{f, lazy} {
+		if _, err := f.Result(); !errors.Is(err, errGone) {
+			t.Fatalf("expected the disconnect cause, got %v", err)
+		}
+		if err := f.AbortAndWait(context.Background()); err != nil {
+			t.Fatalf("expected the task to stop, got %v", err)
+		}
+	}
+}
+
+func TestBindToContext_SettleThenCancel(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx := &countingCtx{Context: parent}
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "ok", nil
+	})
+	BindToContext(ctx, f)
+	if v, err := f.Result(); v != "ok" || err != nil {
+		t.Fatalf("expected ok, got %v, %v", v, err)
+	}
+	if n := ctx.live.Load(); n != 0 {
+		t.Fatalf("expected settling to drop the registration, %d left", n)
+	}
+
+	// Cancelling afterwards leaves the result alone
+	cancel()
+	if v, err := f.Result(); v != "ok" || err != nil {
+		t.Fatalf("expected ok after cancel, got %v, %v", v, err)
+	}
+}
+
+func TestBindToContext_ManyFutures(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	ctx := &countingCtx{Context: parent}
+
+	const n = 100
+	release := make(chan struct{})
+	fs := make([]*Future, n)
+	for i := range fs {
+		fs[i] = NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			if i%2 == 0 {
+				return i, nil
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-release:
+				return i, nil
+			}
+		})
+	}
+	BindToContext(ctx, fs...)
+	for i := 0; i < n; i += 2 {
+		fs[i].Result()
+	}
+	if live := ctx.live.Load(); live != n/2 {
+		t.Fatalf("expected %d registrations for the unsettled futures, got %d", n/2, live)
+	}
+
+	cancel()
+	for i, f := range fs {
+		_, err := f.Result()
+		if i%2 == 0 && err != nil {
+			t.Fatalf("expected settled future %d to keep its result, got %v", i, err)
+		}
+		if i%2 == 1 && !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected future %d to be aborted, got %v", i, err)
+		}
+	}
+	close(release)
+	if live := ctx.live.Load(); live != 0 {
+		t.Fatalf("expected no registrations left, got %d", live)
+	}
+}
+
+func TestBindToContext_AlreadyDone(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	cancel()
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, WithLazy())
+	BindToContext(parent, f)
+	if f.State() != Settled {
+		t.Fatalf("expected the future to be aborted at once, got %v", f.State())
+	}
+}
@@ -0,0 +1,105 @@
+package A
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// DoneCounter counts how many of a set of futures have settled, for
+// progress reporting without blocking on any of them. It neither starts
+// lazy futures nor reads their results.
+type DoneCounter struct {
+	done    atomic.Int64
+	total   atomic.Int64
+	waiting atomic.Int64
+
+	mu      sync.Mutex
+	waiters []doneWaiter
+}
+
+// doneWaiter is a Wait call blocked until n futures have settled.
+type doneWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// Counter returns a DoneCounter over fs. Futures that have already settled
+// are counted at once.
+func Counter(fs []*Future) *DoneCounter {
+	c := &DoneCounter{}
+	c.Add(fs...)
+	return c
+}
+
+// Add counts fs too, for batches that grow after the counter is created.
+func (c *DoneCounter) Add(fs ...*Future) {
+	c.total.Add(int64(len(fs)))
+	for _, f := range fs {
+		f.whenDone(c.settled)
+	}
+}
+
+// Done returns how many of the futures have settled.
+func (c *DoneCounter) Done() int {
+	return int(c.done.Load())
+}
+
+// Total returns how many futures the counter covers.
+func (c *DoneCounter) Total() int {
+	return int(c.total.Load())
+}
+
+// Wait blocks until at least n of the futures have settled, or until ctx is
+// done. An n above Total waits for futures to be added.
+func (c *DoneCounter) Wait(ctx context.Context, n int) error {
+	c.mu.Lock()
+	c.waiting.Add(1)
+	if c.done.Load() >= int64(n) {
+		c.waiting.Add(-1)
+		c.mu.Unlock()
+		return nil
+	}
+	w := doneWaiter{n: int64(n), ready: make(chan struct{})}
+	c.waiters = append(c.waiters, w)
+	c.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, other := range c.waiters {
+			if other.ready == w.ready {
+				c.waiters = slices.Delete(c.waiters, i, i+1)
+				c.waiting.Add(-1)
+				return ctx.Err()
+			}
+		}
+		// Satisfied while ctx was finishing.
+		return nil
+	}
+}
+
+// settled counts a settled future, waking any Wait calls it satisfies.
+// It only takes the lock while someone is waiting.
+func (c *DoneCounter) settled() {
+	done := c.done.Add(1)
+	if c.waiting.Load() == 0 {
+		return
+	}
+	c.mu.Lock()
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.n <= done {
+			close(w.ready)
+			c.waiting.Add(-1)
+			continue
+		}
+		waiters = append(waiters, w)
+	}
+	c.waiters = waiters
+	c.mu.Unlock()
+}
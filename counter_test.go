@@ -0,0 +1,87 @@
+package A
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCounter(t *testing.T) {
+	release := make(chan struct{})
+	blocked := func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	}
+	settled := newSettled(context.Background(), "ok", nil)
+	lazy := NewFuture(context.Background(), blocked, WithLazy())
+	running := NewFuture(context.Background(), blocked)
+
+	c := Counter([]*Future{settled, lazy, running})
+	if c.Done() != 1 || c.Total() != 3 {
+		t.Fatalf("expected 1 of 3 done, got %d of %d", c.Done(), c.Total())
+	}
+	if lazy.State() != Pending {
+		t.Fatalf("expected the counter not to start a lazy future, got %v", lazy.State())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.Wait(ctx, 2); err != context.DeadlineExceeded {
+		t.Fatalf("expected Wait to time out, got %v", err)
+	}
+
+	close(release)
+	running.Result()
+	if err := c.Wait(context.Background(), 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Done() != 2 {
+		t.Fatalf("expected 2 done, got %d", c.Done())
+	}
+
+	// Wait blocks for futures added later
+	added := NewFuture(context.Background(), blocked, WithLazy())
+	waited := make(chan error, 1)
+	go func() {
+		waited <- c.Wait(context.Background(), 4)
+	}()
+	c.Add(added)
+	lazy.Result()
+	added.Result()
+	if err := <-waited; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Done() != 4 || c.Total() != 4 {
+		t.Fatalf("expected 4 of 4 done, got %d of %d", c.Done(), c.Total())
+	}
+}
+
+func TestCounter_ConcurrentWaiters(t *testing.T) {
+	const n = 100
+	start := make(chan struct{})
+	fs := make([]*Future, n)
+	for i := range fs {
+		fs[i] = NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			<-start
+			return nil, nil
+		})
+	}
+	c := Counter(fs)
+
+	var wg sync.WaitGroup
+	for i := 1; i <= n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Wait(context.Background(), i); err != nil {
+				t.Errorf("unexpected error waiting for %d: %v", i, err)
+			}
+			if done := c.Done(); done < i {
+				t.Errorf("Wait for %d returned with %d done", i, done)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
@@ -0,0 +1,213 @@
+package A
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"time"
+)
+
+// WithCallerInfo records where the future was created, for debugging
+// futures that never settle. Capturing the call site walks the stack, so it
+// is off by default.
+func WithCallerInfo() Option {
+	return func(f *Future) {
+		f.callerInfo = true
+	}
+}
+
+// Origin returns the file:line that created the future, or "" if it was
+// created without WithCallerInfo.
+func (f *Future) Origin() string {
+	return f.origin
+}
+
+// pkgPrefix prefixes the names of the functions in this package.
+var pkgPrefix = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	slash := strings.LastIndex(name, "/")
+	return name[:slash+strings.Index(name[slash:], ".")+1]
+}()
+
+// callerOrigin returns the file:line of the first caller outside this
+// package, such as the code that called NewFuture, Pool.Submit, or Group.Go.
+func callerOrigin() string {
+	var pcs [16]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		internal := strings.HasPrefix(frame.Function, pkgPrefix) && !strings.HasSuffix(frame.File, "_test.go")
+		if !internal || !more {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+	}
+}
+
+// Snapshot is a point-in-time view of a future, for debug endpoints and
+// error reports.
+type Snapshot struct {
+	// Name is the name given with WithName.
+	Name string
+	// State is the lifecycle stage of the future.
+	State State
+	// Lazy reports whether the future was created with WithLazy.
+	Lazy bool
+	// Origin is the file:line that created the future, if it was created
+	// with WithCallerInfo.
+	Origin string
+	// CreatedAt is when the future was created.
+	CreatedAt time.Time
+	// StartedAt is when the task started executing, or the zero time if it
+	// has not started.
+	StartedAt time.Time
+	// SettledAt is when the future settled, or the zero time if it has not.
+	SettledAt time.Time
+	// Err is the error the future settled with, or "" if it has not settled
+	// or succeeded.
+	Err string
+	// Panicked reports whether the task panicked.
+	Panicked bool
+	// Aborted reports whether the future was settled by Abort or
+	// AbortWithError.
+	Aborted bool
+	// Attempts is the number of times the task started executing.
+	Attempts int
+}
+
+// Snapshot returns a consistent view of the future. It never blocks on the
+// task and is safe to call at any point, including during settlement.
+func (f *Future) Snapshot() Snapshot {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := Snapshot{
+		Name:      f.name,
+		State:     f.state(),
+		Lazy:      f.lazy,
+		Origin:    f.origin,
+		CreatedAt: f.createdAt,
+		StartedAt: f.startedAt,
+		SettledAt: f.settledAt,
+		Panicked:  f.panicked,
+		Aborted:   f.settled && f.outcome == Aborted,
+		Attempts:  f.attempts,
+	}
+	if f.err != nil {
+		s.Err = f.err.Error()
+	}
+	return s
+}
+
+// Meta describes how a future settled, as returned by ResultMeta.
+type Meta struct {
+	// Name is the name given with WithName.
+	Name string
+	// Outcome is how the future settled.
+	Outcome Outcome
+	// Attempts is the number of times the task started executing.
+	Attempts int
+	// Hedges is the number of hedge calls WithHedge launched; they are not
+	// counted as attempts.
+	Hedges int
+	// ExecTime is how long the task executed; it is zero if the task never
+	// started.
+	ExecTime time.Duration
+	// WaitTime is how long the future waited to start, or until it settled
+	// if it never started. It is IdleTime plus StartDelay.
+	WaitTime time.Duration
+	// IdleTime is the part of WaitTime a lazy future spent before anything
+	// asked it to start.
+	IdleTime time.Duration
+	// StartDelay is the part of WaitTime from being asked to start to
+	// starting: pool queue, governor, limiter, and semaphore waits.
+	StartDelay time.Duration
+	// QueueWait is the part of StartDelay spent in a pool queue.
+	QueueWait time.Duration
+	// Panicked reports whether the task panicked.
+	Panicked bool
+	// Aborted reports whether the future was settled by Abort or
+	// AbortWithError.
+	Aborted bool
+}
+
+// ResultMeta waits like Result and returns the result together with how the
+// future settled, read under the same lock so the two always agree.
+func (f *Future) ResultMeta() (any, error, Meta) {
+	f.consume()
+	f.once.Do(f.start)
+	if !f.ready.Load() {
+		<-f.doneChan()
+	}
+
+	f.mu.Lock()
+	meta := f.meta()
+	item, err := f.take()
+	f.mu.Unlock()
+	return f.cloned(item), err, meta
+}
+
+// meta describes how the future settled. It must be called with f.mu held.
+func (f *Future) meta() Meta {
+	execTime, waitTime := execWait(f.createdAt, f.startedAt, f.settledAt)
+	idleTime, startDelay := splitWait(f.createdAt, f.requestedAt, f.startedAt, f.settledAt)
+	return Meta{
+		Name:       f.name,
+		Outcome:    f.outcome,
+		Attempts:   f.attempts,
+		Hedges:     f.hedges,
+		ExecTime:   execTime,
+		WaitTime:   waitTime,
+		IdleTime:   idleTime,
+		StartDelay: startDelay,
+		QueueWait:  f.queueWait,
+		Panicked:   f.panicked,
+		Aborted:    f.outcome == Aborted,
+	}
+}
+
+// WithPprofLabels runs the task under pprof.Do with labels applied to its
+// goroutine, so CPU and goroutine profiles can attribute work to futures.
+// A nil or empty map labels the goroutine with the future's name under the
+// key "future".
+func WithPprofLabels(labels map[string]string) Option {
+	return func(f *Future) {
+		f.pprof = true
+		f.pprofLabels = labels
+	}
+}
+
+// runLabeled runs the task under the future's pprof labels.
+func (f *Future) runLabeled(ctx context.Context, task Task) (res any, err error) {
+	var pairs []string
+	for k, v := range f.pprofLabels {
+		pairs = append(pairs, k, v)
+	}
+	if len(pairs) == 0 {
+		pairs = []string{"future", f.name}
+	}
+	pprof.Do(ctx, pprof.Labels(pairs...), func(ctx context.Context) {
+		res, err = task(ctx)
+	})
+	return res, err
+}
+
+// WithRuntimeTrace records the future as a runtime/trace task named after
+// it, from creation to settlement, so go tool trace shows the delay between
+// creating the future and starting its task. Each execution attempt and the
+// settlement callbacks are marked as regions.
+func WithRuntimeTrace() Option {
+	return func(f *Future) {
+		f.runtimeTrace = true
+	}
+}
+
+// traceName returns the name of the future's runtime/trace task.
+func (f *Future) traceName() string {
+	if f.name == "" {
+		return "future"
+	}
+	return "future " + f.name
+}
@@ -0,0 +1,191 @@
+package A
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+func TestFuture_Origin(t *testing.T) {
+	task := func(ctx context.Context) (any, error) {
+		return nil, nil
+	}
+	if origin := NewFuture(context.Background(), task).Origin(); origin != "" {
+		t.Fatalf("expected no origin without WithCallerInfo, got %q", origin)
+	}
+
+	// The origin is the caller's line, however the future was created
+	p := NewPool(1)
+	defer p.Shutdown(context.Background())
+	g := NewGroup(context.Background())
+	_, file, line, _ := runtime.Caller(0)
+	futures := []*Future{
+		NewFuture(context.Background(), task, WithCallerInfo()),
+		p.Submit(context.Background(), task, WithCallerInfo()),
+		g.Go(task, WithCallerInfo()),
+	}
+	for i, f := range futures {
+		if want := fmt.Sprintf("%s:%d", file, line+i+2); f.Origin() != want {
+			t.Fatalf("expected origin %q, got %q", want, f.Origin())
+		}
+	}
+	g.Wait()
+}
+
+func BenchmarkNewFuture(b *testing.B) {
+	task := func(ctx context.Context) (any, error) {
+		return nil, nil
+	}
+	b.Run("Plain", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			newFuture(context.Background(), task)
+		}
+	})
+	b.Run("WithoutAbort", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			newFuture(context.Background(), task, WithoutAbort())
+		}
+	})
+	b.Run("CallerInfo", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			newFuture(context.Background(), task, WithCallerInfo())
+		}
+	})
+}
+
+func TestFuture_Snapshot(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-release
+		return nil, errors.New("failed")
+	}, WithName("job"))
+
+	<-started
+	s := future.Snapshot()
+	if s.Name != "job" || s.State != Running || s.Attempts != 1 || s.StartedAt.IsZero() || !s.SettledAt.IsZero() {
+		t.Fatalf("unexpected running snapshot %+v", s)
+	}
+	close(release)
+	future.Result()
+	s = future.Snapshot()
+	if s.State != Settled || s.Err != `future "job": failed` || s.Aborted || s.Panicked || s.SettledAt.Before(s.StartedAt) {
+		t.Fatalf("unexpected settled snapshot %+v", s)
+	}
+
+	lazy := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithLazy())
+	if s := lazy.Snapshot(); s.State != Pending || !s.Lazy || s.Attempts != 0 {
+		t.Fatalf("unexpected lazy snapshot %+v", s)
+	}
+	lazy.Abort()
+	if s := lazy.Snapshot(); !s.Aborted || s.Attempts != 0 || s.Err != context.Canceled.Error() {
+		t.Fatalf("unexpected aborted snapshot %+v", s)
+	}
+
+	panicky := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		panic("boom")
+	})
+	panicky.Result()
+	if s := panicky.Snapshot(); !s.Panicked || s.Aborted {
+		t.Fatalf("unexpected panicked snapshot %+v", s)
+	}
+}
+
+func TestFuture_SnapshotConcurrent(t *testing.T) {
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "done", nil
+	})
+
+	// Snapshots taken while the future settles must be consistent
+	for !future.Ready() {
+		if s := future.Snapshot(); s.State == Settled && s.SettledAt.IsZero() {
+			t.Fatalf("inconsistent snapshot %+v", s)
+		}
+	}
+}
+
+func TestFuture_ResultMeta(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		clock.Advance(3 * time.Second)
+		return "done", nil
+	}, WithName("load"), WithLazy(), WithSync(), WithClock(clock))
+	clock.Advance(2 * time.Second)
+
+	v, err, meta := future.ResultMeta()
+	if v != "done" || err != nil {
+		t.Fatalf("unexpected result %v, %v", v, err)
+	}
+	want := Meta{Name: "load", Outcome: Succeeded, Attempts: 1, ExecTime: 3 * time.Second, WaitTime: 2 * time.Second, IdleTime: 2 * time.Second}
+	if meta != want {
+		t.Fatalf("expected %+v, got %+v", want, meta)
+	}
+
+	panicky := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		panic("boom")
+	})
+	if _, err, meta := panicky.ResultMeta(); !IsPanic(err) || !meta.Panicked || meta.Outcome != Panicked {
+		t.Fatalf("unexpected panicked meta %+v (%v)", meta, err)
+	}
+
+	aborted := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithLazy())
+	aborted.Abort()
+	if _, err, meta := aborted.ResultMeta(); !IsAborted(err) || !meta.Aborted || meta.Attempts != 0 || meta.ExecTime != 0 {
+		t.Fatalf("unexpected aborted meta %+v (%v)", meta, err)
+	}
+}
+
+func TestFuture_PprofLabels(t *testing.T) {
+	label := func(ctx context.Context, key string) (any, error) {
+		value, _ := pprof.Label(ctx, key)
+		return value, nil
+	}
+
+	named := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return label(ctx, "future")
+	}, WithName("load-user"), WithPprofLabels(nil))
+	if value, _ := named.Result(); value != "load-user" {
+		t.Fatalf("expected the name as the default label, got %v", value)
+	}
+
+	custom := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return label(ctx, "tenant")
+	}, WithPprofLabels(map[string]string{"tenant": "acme"}))
+	if value, _ := custom.Result(); value != "acme" {
+		t.Fatalf("expected label 'acme', got %v", value)
+	}
+}
+
+func TestFuture_RuntimeTrace(t *testing.T) {
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skipf("tracing unavailable: %v", err)
+	}
+	NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithName("traced-task"), WithRuntimeTrace()).Result()
+	trace.Stop()
+
+	// The task and region names are recorded in the trace's string table
+	for _, want := range []string{"future traced-task", "attempt", "callbacks"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Fatalf("expected the trace to mention %q", want)
+		}
+	}
+}
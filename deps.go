@@ -0,0 +1,50 @@
+package A
+
+// WithDependencies declares futures the task waits on. Starting the future
+// starts them at once, so a DAG of lazy futures built ahead of time runs in
+// parallel from a single Result call on its root instead of each leaf
+// waiting until the task reaches it. Aborting the future aborts each
+// dependency with the same error, unless another unsettled future still
+// declares it.
+func WithDependencies(deps ...*Future) Option {
+	return func(f *Future) {
+		for _, dep := range deps {
+			if dep == nil {
+				f.invalidOption("WithDependencies", "nil dependency")
+				return
+			}
+		}
+		f.deps = append(f.deps, deps...)
+	}
+}
+
+// dependOn claims f's dependencies until f settles, aborting those nobody
+// else claims if f was aborted.
+func (f *Future) dependOn() {
+	for _, dep := range f.deps {
+		dep.mu.Lock()
+		dep.dependents++
+		dep.mu.Unlock()
+	}
+	f.whenDone(func() {
+		f.mu.Lock()
+		aborted, err := f.outcome == Aborted, f.err
+		f.mu.Unlock()
+		for _, dep := range f.deps {
+			dep.mu.Lock()
+			dep.dependents--
+			unclaimed := dep.dependents == 0
+			dep.mu.Unlock()
+			if aborted && unclaimed {
+				dep.AbortWithError(err)
+			}
+		}
+	})
+}
+
+// startDependencies starts f's dependencies.
+func (f *Future) startDependencies() {
+	for _, dep := range f.deps {
+		dep.once.Do(dep.start)
+	}
+}
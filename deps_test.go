@@ -0,0 +1,98 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithDependencies(t *testing.T) {
+	// Two leaves that each wait for the other to start only finish if they
+	// run in parallel.
+	var started atomic.Int32
+	leaf := func(ctx context.Context) (any, error) {
+		started.Add(1)
+		for started.Load() < 2 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Millisecond):
+			}
+		}
+		return 1, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	left := NewFuture(ctx, leaf, WithLazy())
+	right := NewFuture(ctx, leaf, WithLazy())
+	root := NewFuture(ctx, func(ctx context.Context) (any, error) {
+		l, err := left.Result()
+		if err != nil {
+			return nil, err
+		}
+		r, err := right.Result()
+		if err != nil {
+			return nil, err
+		}
+		return l.(int) + r.(int), nil
+	}, WithLazy(), WithDependencies(left, right))
+
+	if left.State() != Pending || right.State() != Pending {
+		t.Fatalf("expected lazy dependencies to wait for the root to start")
+	}
+	if v, err := root.Result(); v != 2 || err != nil {
+		t.Fatalf("expected 2, got %v, %v", v, err)
+	}
+}
+
+func TestWithDependencies_Abort(t *testing.T) {
+	blocked := func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	own := NewFuture(context.Background(), blocked, WithLazy())
+	shared := NewFuture(context.Background(), blocked, WithLazy())
+	root := NewFuture(context.Background(), blocked, WithLazy(), WithDependencies(own, shared))
+	other := NewFuture(context.Background(), blocked, WithLazy(), WithDependencies(shared))
+	go root.Result()
+	for own.State() == Pending {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A dependency another future still declares survives the abort
+	errStop := errors.New("stop")
+	root.AbortWithError(errStop)
+	if _, err := own.Result(); !errors.Is(err, errStop) {
+		t.Fatalf("expected the dependency to be aborted with the root's error, got %v", err)
+	}
+	if shared.State() == Settled {
+		t.Fatalf("expected the shared dependency to keep running")
+	}
+
+	// Once its last dependent is aborted, it is aborted too
+	other.Abort()
+	if _, err := shared.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the shared dependency to be aborted, got %v", err)
+	}
+}
+
+func TestWithDependencies_SettledDependentsRelease(t *testing.T) {
+	blocked := func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	dep := NewFuture(context.Background(), blocked, WithLazy())
+	done := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithDependencies(dep))
+	aborted := NewFuture(context.Background(), blocked, WithDependencies(dep))
+	done.Result()
+
+	// A dependent that succeeded no longer holds its claim
+	aborted.Abort()
+	if _, err := dep.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the dependency to be aborted, got %v", err)
+	}
+}
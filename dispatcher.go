@@ -0,0 +1,151 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ErrDispatcherClosed is returned by futures started on a closed Dispatcher.
+var ErrDispatcherClosed = errors.New("dispatcher is closed")
+
+// WithDispatcher runs the task on d's goroutines instead of a goroutine of
+// its own. The dispatcher bounds concurrency itself, so the future is not
+// governed.
+func WithDispatcher(d *Dispatcher) Option {
+	return func(f *Future) {
+		f.dispatcher = d
+	}
+}
+
+// Dispatcher multiplexes the tasks of many futures over a fixed set of
+// goroutines. For tiny tasks at very high rates it saves the cost of a
+// goroutine per future; tasks that block hold a goroutine of the dispatcher
+// for as long as they block. Unlike a Pool it has no queue bound, keys, or
+// priorities. A queued future whose context is done settles when a
+// goroutine reaches it, without running its task.
+type Dispatcher struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  []*Future
+	closed bool
+}
+
+// NewDispatcher starts a Dispatcher with the given number of goroutines.
+// Zero or a negative workers means runtime.GOMAXPROCS(0).
+func NewDispatcher(workers int) *Dispatcher {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	d := &Dispatcher{}
+	d.cond = sync.NewCond(&d.mu)
+	for range workers {
+		go d.work()
+	}
+	return d
+}
+
+// Close stops the dispatcher once the tasks already queued have run.
+// Futures started on it afterwards fail with ErrDispatcherClosed.
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	d.closed = true
+	d.cond.Broadcast()
+	d.mu.Unlock()
+}
+
+// Queued returns the number of futures waiting for a goroutine.
+func (d *Dispatcher) Queued() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.queue)
+}
+
+// Do runs task on the dispatcher and returns its result, like Result on a
+// future created WithDispatcher(d). Since the future never escapes, Do
+// recycles it once settled, which saves its allocation on hot paths that
+// only need the result. Futures whose default options set up timers, such
+// as WithTimeout, are not recycled.
+func (d *Dispatcher) Do(ctx context.Context, task Task) (any, error) {
+	f := futurePool.Get().(*Future)
+	f.init(ctx, task)
+	f.once.Do(func() {})
+	if f.rejectInvalid() {
+		return f.peek()
+	}
+	if !f.recyclable() {
+		// A timer may still touch the future after the task returns.
+		d.dispatch(f)
+		return f.Result()
+	}
+	// The channel is not recycled: one made inside a testing/synctest
+	// bubble must not be used outside it.
+	f.signal = make(chan struct{}, 1)
+	if d.dispatch(f) {
+		<-f.signal
+	}
+	item, err := f.peek()
+	f.recycle()
+	return item, err
+}
+
+// futurePool holds futures recycled by Dispatcher.Do.
+var futurePool = sync.Pool{
+	New: func() any {
+		return new(Future)
+	},
+}
+
+// recyclable reports whether f is settled only by the goroutine running its
+// task, and nothing refers to it once that goroutine is done with it. A
+// hedge can outlive the run goroutine.
+func (f *Future) recyclable() bool {
+	return f.timeout == 0 && f.deadline.IsZero() && f.onSlow == nil && f.progress == nil && f.leak == nil &&
+		f.hedgeDelay == 0
+}
+
+// recycle resets f and returns it to the pool.
+func (f *Future) recycle() {
+	*f = Future{}
+	futurePool.Put(f)
+}
+
+// dispatch queues f to run on the next free goroutine. It reports false,
+// settling f, if the dispatcher is closed.
+func (d *Dispatcher) dispatch(f *Future) bool {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		f.settle(nil, ErrDispatcherClosed)
+		return false
+	}
+	d.queue = append(d.queue, f)
+	d.cond.Signal()
+	d.mu.Unlock()
+	return true
+}
+
+// work runs queued futures until the dispatcher is closed and drained.
+func (d *Dispatcher) work() {
+	for {
+		d.mu.Lock()
+		for len(d.queue) == 0 && !d.closed {
+			d.cond.Wait()
+		}
+		if len(d.queue) == 0 {
+			d.mu.Unlock()
+			return
+		}
+		f := d.queue[0]
+		d.queue[0] = nil
+		d.queue = d.queue[1:]
+		d.mu.Unlock()
+		signal := f.signal
+		f.run()
+		if signal != nil {
+			// Do recycles f once it receives this.
+			signal <- struct{}{}
+		}
+	}
+}
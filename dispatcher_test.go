@@ -0,0 +1,195 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcher(t *testing.T) {
+	d := NewDispatcher(2)
+	defer d.Close()
+	var running, peak atomic.Int32
+	fs := make([]*Future, 20)
+	for i := range fs {
+		fs[i] = NewFuture(context.Background(), func(context.Context) (any, error) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			return i, nil
+		}, WithDispatcher(d))
+	}
+	for i, f := range fs {
+		if v, err := f.Result(); v != i || err != nil {
+			t.Fatalf("future %d: unexpected result %v, %v", i, v, err)
+		}
+	}
+	if p := peak.Load(); p > 2 {
+		t.Fatalf("expected at most 2 tasks at once, got %d", p)
+	}
+}
+
+func TestDispatcherQueuedCancelled(t *testing.T) {
+	d := NewDispatcher(1)
+	defer d.Close()
+	release := make(chan struct{})
+	blocker := NewFuture(context.Background(), func(context.Context) (any, error) {
+		<-release
+		return nil, nil
+	}, WithDispatcher(d))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var ran atomic.Bool
+	f := NewFuture(ctx, func(context.Context) (any, error) {
+		ran.Store(true)
+		return nil, nil
+	}, WithDispatcher(d))
+	aborted := NewFuture(context.Background(), func(context.Context) (any, error) {
+		ran.Store(true)
+		return nil, nil
+	}, WithDispatcher(d))
+	cancel()
+	aborted.Abort()
+	close(release)
+	blocker.Result()
+	if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, err := aborted.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if ran.Load() {
+		t.Fatalf("cancelled task ran")
+	}
+}
+
+func TestDispatcherClosed(t *testing.T) {
+	d := NewDispatcher(1)
+	d.Close()
+	f := NewFuture(context.Background(), func(context.Context) (any, error) {
+		return nil, nil
+	}, WithDispatcher(d))
+	if _, err := f.Result(); !errors.Is(err, ErrDispatcherClosed) {
+		t.Fatalf("expected ErrDispatcherClosed, got %v", err)
+	}
+}
+
+// BenchmarkDispatcher compares running 10µs tasks on a goroutine each with
+// running them on a dispatcher, reporting throughput and p99 latency from
+// creation to settlement.
+func BenchmarkDispatcher(b *testing.B) {
+	task := func(context.Context) (any, error) {
+		for start := time.Now(); time.Since(start) < 10*time.Microsecond; {
+		}
+		return nil, nil
+	}
+	bench := func(b *testing.B, opts ...Option) {
+		latencies := make([]time.Duration, b.N)
+		var wg sync.WaitGroup
+		wg.Add(b.N)
+		b.ResetTimer()
+		start := time.Now()
+		for i := range b.N {
+			created := time.Now()
+			f := NewFuture(context.Background(), task, opts...)
+			f.whenDone(func() {
+				latencies[i] = time.Since(created)
+				wg.Done()
+			})
+		}
+		wg.Wait()
+		elapsed := time.Since(start)
+		b.StopTimer()
+		slices.Sort(latencies)
+		b.ReportMetric(float64(b.N)/elapsed.Seconds(), "futures/s")
+		b.ReportMetric(float64(latencies[len(latencies)*99/100].Microseconds()), "p99-µs")
+	}
+	b.Run("Goroutines", func(b *testing.B) {
+		bench(b)
+	})
+	b.Run("Dispatcher", func(b *testing.B) {
+		d := NewDispatcher(0)
+		defer d.Close()
+		bench(b, WithDispatcher(d))
+	})
+}
+
+func TestDispatcherDo(t *testing.T) {
+	d := NewDispatcher(2)
+	defer d.Close()
+	var wg sync.WaitGroup
+	for i := range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := d.Do(context.Background(), func(context.Context) (any, error) {
+				if i%10 == 0 {
+					return nil, errors.New("failed")
+				}
+				return i, nil
+			})
+			if i%10 == 0 {
+				if err == nil {
+					t.Errorf("Do %d: expected error", i)
+				}
+				return
+			}
+			if v != i || err != nil {
+				t.Errorf("Do %d: unexpected result %v, %v", i, v, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := d.Do(context.Background(), func(context.Context) (any, error) {
+		panic("boom")
+	}); err == nil {
+		t.Fatalf("expected recovered panic")
+	}
+
+	SetDefaultOptions(WithTimeout(10 * time.Millisecond))
+	defer SetDefaultOptions()
+	if _, err := d.Do(context.Background(), func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected default timeout to apply, got %v", err)
+	}
+
+	d.Close()
+	if _, err := d.Do(context.Background(), func(context.Context) (any, error) {
+		return nil, nil
+	}); !errors.Is(err, ErrDispatcherClosed) {
+		t.Fatalf("expected ErrDispatcherClosed, got %v", err)
+	}
+}
+
+func BenchmarkDispatcherDo(b *testing.B) {
+	d := NewDispatcher(0)
+	defer d.Close()
+	task := func(context.Context) (any, error) {
+		return nil, nil
+	}
+	b.Run("Future", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			NewFuture(context.Background(), task, WithDispatcher(d)).Result()
+		}
+	})
+	b.Run("Do", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			d.Do(context.Background(), task)
+		}
+	})
+}
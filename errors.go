@@ -0,0 +1,76 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// PanicError is the error of a future whose task panicked.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic occurred: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// WithErrorDecorator applies fn to every error the future settles with,
+// whether the task returned it or it comes from a timeout, abort, or panic,
+// after the package's own wrapping. Decorators apply in the order given. A
+// decorator cannot erase a failure: if fn returns nil, the error is kept as
+// it was.
+func WithErrorDecorator(fn func(err error) error) Option {
+	return func(f *Future) {
+		if fn == nil {
+			f.invalidOption("WithErrorDecorator", "nil decorator")
+			return
+		}
+		f.errorDecorators = append(f.errorDecorators, fn)
+	}
+}
+
+// decorate applies the error decorators to err.
+func (f *Future) decorate(err error) error {
+	for _, fn := range f.errorDecorators {
+		if decorated := fn(err); decorated != nil {
+			err = decorated
+		}
+	}
+	return err
+}
+
+// IsTimeout reports whether err, or any error it wraps or joins, is a
+// timeout: a *TaskTimeoutError, a *WaitTimeoutError, or any other
+// context.DeadlineExceeded.
+func IsTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// IsAborted reports whether err, or any error it wraps or joins, comes from
+// cancellation: Abort, a cancelled parent context, or a group cancelling
+// its members. Errors passed to AbortWithError are the caller's own and are
+// not recognized.
+func IsAborted(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
+
+// IsPanic reports whether err, or any error it wraps or joins, is a
+// *PanicError.
+func IsPanic(err error) bool {
+	var panicErr *PanicError
+	return errors.As(err, &panicErr)
+}
+
+// IsPoolClosed reports whether err, or any error it wraps or joins, is
+// ErrPoolClosed.
+func IsPoolClosed(err error) bool {
+	return errors.Is(err, ErrPoolClosed)
+}
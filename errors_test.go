@@ -0,0 +1,244 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestErrorClassification(t *testing.T) {
+	errOther := errors.New("other")
+	taskTimeout := &TaskTimeoutError{Limit: time.Second, Elapsed: time.Second}
+	waitTimeout := &WaitTimeoutError{Limit: time.Second, Elapsed: time.Second}
+	panicked := &PanicError{Value: "boom"}
+	wrap := func(err error) error {
+		return fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", err))
+	}
+
+	tests := map[string]struct {
+		err                                 error
+		timeout, aborted, panic, poolClosed bool
+	}{
+		"nil":                   {err: nil},
+		"other":                 {err: errOther},
+		"task timeout":          {err: taskTimeout, timeout: true},
+		"wait timeout":          {err: waitTimeout, timeout: true},
+		"deadline":              {err: context.DeadlineExceeded, timeout: true},
+		"double wrapped":        {err: wrap(taskTimeout), timeout: true},
+		"aborted":               {err: context.Canceled, aborted: true},
+		"wrapped abort":         {err: wrap(context.Canceled), aborted: true},
+		"panic":                 {err: panicked, panic: true},
+		"wrapped panic":         {err: wrap(panicked), panic: true},
+		"pool closed":           {err: ErrPoolClosed, poolClosed: true},
+		"wrapped pool closed":   {err: wrap(ErrPoolClosed), poolClosed: true},
+		"joined":                {err: errors.Join(errOther, wrap(panicked), waitTimeout), timeout: true, panic: true},
+		"joined and wrapped":    {err: wrap(errors.Join(context.Canceled, ErrPoolClosed)), aborted: true, poolClosed: true},
+		"panic with error":      {err: &PanicError{Value: context.Canceled}, aborted: true, panic: true},
+		"member of a group":     {err: &MemberError{Index: 1, Err: taskTimeout}, timeout: true},
+		"stage of a pipeline":   {err: &StageError{Stage: "fetch", Err: panicked}, panic: true},
+		"named future's errors": {err: fmt.Errorf("future %q: %w", "fetch", waitTimeout), timeout: true},
+	}
+	for name, tt := range tests {
+		if got := IsTimeout(tt.err); got != tt.timeout {
+			t.Errorf("%s: IsTimeout = %v, want %v", name, got, tt.timeout)
+		}
+		if got := IsAborted(tt.err); got != tt.aborted {
+			t.Errorf("%s: IsAborted = %v, want %v", name, got, tt.aborted)
+		}
+		if got := IsPanic(tt.err); got != tt.panic {
+			t.Errorf("%s: IsPanic = %v, want %v", name, got, tt.panic)
+		}
+		if got := IsPoolClosed(tt.err); got != tt.poolClosed {
+			t.Errorf("%s: IsPoolClosed = %v, want %v", name, got, tt.poolClosed)
+		}
+	}
+}
+
+func TestErrorClassification_Futures(t *testing.T) {
+	panicky := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		panic("boom")
+	})
+	if _, err := panicky.Result(); !IsPanic(err) || err.Error() != "panic occurred: boom" {
+		t.Fatalf("expected a panic error, got %v", err)
+	}
+
+	aborted := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	aborted.Abort()
+	if _, err := aborted.Result(); !IsAborted(err) {
+		t.Fatalf("expected an abort error, got %v", err)
+	}
+
+	p := NewPool(1)
+	p.Shutdown(context.Background())
+	if _, err := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}).Result(); !IsPoolClosed(err) {
+		t.Fatalf("expected a pool closed error, got %v", err)
+	}
+}
+
+func TestErrorUnwrapping(t *testing.T) {
+	errBoom := errors.New("boom")
+	errStop := errors.New("stop")
+	blocked := func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	tests := map[string]struct {
+		future func() *Future
+		target error
+	}{
+		"abort": {func() *Future {
+			f := NewFuture(context.Background(), blocked, WithName("job"))
+			f.Abort()
+			return f
+		}, context.Canceled},
+		"abort with error": {func() *Future {
+			f := NewFuture(context.Background(), blocked, WithName("job"))
+			f.AbortWithError(errStop)
+			return f
+		}, errStop},
+		"parent cancel": {func() *Future {
+			ctx, cancel := context.WithCancel(context.Background())
+			f := NewFuture(ctx, blocked, WithName("job"))
+			cancel()
+			return f
+		}, context.Canceled},
+		"parent cancel cause": {func() *Future {
+			ctx, cancel := context.WithCancelCause(context.Background())
+			cancel(errStop)
+			return NewFuture(ctx, blocked, WithName("job"))
+		}, errStop},
+		"failed parent future": {func() *Future {
+			parent := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+				return nil, errBoom
+			}, WithName("parent"))
+			return parent.Child(func(ctx context.Context, v any) (any, error) {
+				return v, nil
+			}, WithName("job"))
+		}, errBoom},
+		"timeout": {func() *Future {
+			return NewFuture(context.Background(), blocked, WithName("job"), WithTimeout(time.Millisecond))
+		}, context.DeadlineExceeded},
+		"timeout cause": {func() *Future {
+			return NewFuture(context.Background(), blocked, WithName("job"), WithTimeoutCause(time.Millisecond, errStop))
+		}, errStop},
+		"panic with an error value": {func() *Future {
+			return NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+				panic(fmt.Errorf("handler: %w", errBoom))
+			}, WithName("job"))
+		}, errBoom},
+		"pool closed": {func() *Future {
+			p := NewPool(1)
+			p.Shutdown(context.Background())
+			return p.Submit(context.Background(), blocked, WithName("job"))
+		}, ErrPoolClosed},
+		"invalid option": {func() *Future {
+			return NewFuture(context.Background(), blocked, WithName("job"), WithTimeout(-time.Second))
+		}, ErrInvalidOption},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := test.future().Result()
+			if !errors.Is(err, test.target) {
+				t.Fatalf("expected %v to be reachable from %v", test.target, err)
+			}
+		})
+	}
+}
+
+// codedError is an error with a status code, as a team's own taxonomy
+// would attach.
+type codedError struct {
+	code int
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return fmt.Sprintf("code %d: %v", e.code, e.err)
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+func TestWithErrorDecorator(t *testing.T) {
+	errFailed := errors.New("failed")
+	withCode := WithErrorDecorator(func(err error) error {
+		return &codedError{code: 13, err: err}
+	})
+	started := make(chan struct{})
+	futures := map[string]*Future{
+		"task error": NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			return nil, errFailed
+		}, withCode, WithName("job")),
+		"panic": NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			panic("boom")
+		}, withCode),
+		"timeout": NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, withCode, WithTimeout(time.Millisecond)),
+		"abort": NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, withCode),
+	}
+	<-started
+	futures["abort"].Abort()
+
+	for name, f := range futures {
+		_, err := f.Result()
+		var coded *codedError
+		if !errors.As(err, &coded) || coded.code != 13 {
+			t.Fatalf("%s: expected a decorated error, got %v", name, err)
+		}
+	}
+
+	// Decoration comes after the package's own wrapping
+	_, err := futures["task error"].Result()
+	if err.Error() != `code 13: future "job": failed` || !errors.Is(err, errFailed) {
+		t.Fatalf("unexpected decorated error %v", err)
+	}
+	if _, err := futures["panic"].Result(); !IsPanic(err) {
+		t.Fatalf("expected the panic to stay classifiable, got %v", err)
+	}
+}
+
+func TestWithErrorDecorator_CannotEraseFailure(t *testing.T) {
+	errFailed := errors.New("failed")
+	var calls []string
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, errFailed
+	}, WithErrorDecorator(func(err error) error {
+		calls = append(calls, "erase")
+		return nil
+	}), WithErrorDecorator(func(err error) error {
+		calls = append(calls, "wrap")
+		return fmt.Errorf("wrapped: %w", err)
+	}))
+	if _, err := f.Result(); err == nil || err.Error() != "wrapped: failed" {
+		t.Fatalf("expected the failure to survive a nil decorator, got %v", err)
+	}
+	if strings.Join(calls, ",") != "erase,wrap" {
+		t.Fatalf("expected decorators in order, got %v", calls)
+	}
+
+	// Successful results are left alone
+	ok := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "ok", nil
+	}, WithErrorDecorator(func(err error) error {
+		t.Error("decorator called without an error")
+		return err
+	}))
+	if v, err := ok.Result(); v != "ok" || err != nil {
+		t.Fatalf("expected ok, got %v, %v", v, err)
+	}
+}
@@ -0,0 +1,56 @@
+package A_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	A "github.com/ongniud/future"
+)
+
+// A task checks between phases whether it has been aborted, and stops with
+// the error it was aborted with.
+func ExampleCheckpoint() {
+	errStale := errors.New("input changed")
+	phaseOne, resume, stopped := make(chan struct{}), make(chan struct{}), make(chan error)
+	f := A.NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		close(phaseOne)
+		<-resume
+		if err := A.Checkpoint(ctx); err != nil {
+			stopped <- err
+			return nil, err
+		}
+		return "phase two", nil
+	})
+
+	<-phaseOne
+	f.AbortWithError(errStale)
+	close(resume)
+	fmt.Println("stopped before phase two:", <-stopped)
+	// Output:
+	// stopped before phase two: input changed
+}
+
+// A hot loop only looks at its context on every 100th item, so it notices
+// the cancellation at item 250 when it reaches item 299.
+func ExampleCheckpointEvery() {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan int)
+	A.NewFuture(ctx, func(ctx context.Context) (any, error) {
+		checkpoint := A.CheckpointEvery(ctx, 100)
+		for i := range 1000 {
+			if err := checkpoint(); err != nil {
+				stopped <- i
+				return nil, err
+			}
+			if i == 250 {
+				cancel()
+			}
+		}
+		return nil, nil
+	})
+
+	fmt.Println("stopped at item", <-stopped)
+	// Output:
+	// stopped at item 299
+}
@@ -0,0 +1,16 @@
+package A
+
+// Executor runs the tasks of futures created WithExecutor. Execute must call
+// run exactly once, on any goroutine, at any later time.
+type Executor interface {
+	Execute(run func())
+}
+
+// WithExecutor hands the task to e instead of starting a goroutine for it,
+// e.g. to step tasks by hand in tests. The executor bounds concurrency
+// itself, so the future is not governed.
+func WithExecutor(e Executor) Option {
+	return func(f *Future) {
+		f.executor = e
+	}
+}
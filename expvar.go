@@ -0,0 +1,64 @@
+package A
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+)
+
+// stats are package-wide counters, always maintained and published through
+// expvar by EnableExpvar.
+var stats struct {
+	created     atomic.Uint64
+	inFlight    atomic.Int64
+	maxInFlight atomic.Int64
+	settled     [TimedOut + 1]atomic.Uint64
+	poolsOpen   atomic.Int64
+	groups      atomic.Uint64
+}
+
+var expvarOnce sync.Once
+
+// EnableExpvar publishes package statistics as the expvar variable "future":
+// futures created, in flight (created but not settled), the maximum observed
+// in flight, futures settled by outcome, open pools, and groups created.
+// Calling it more than once has no further effect.
+func EnableExpvar() {
+	expvarOnce.Do(func() {
+		expvar.Publish("future", expvar.Func(expvarStats))
+	})
+}
+
+// expvarStats returns the statistics published by EnableExpvar.
+func expvarStats() any {
+	settled := make(map[string]uint64, len(stats.settled))
+	for outcome := range stats.settled {
+		settled[Outcome(outcome).String()] = stats.settled[outcome].Load()
+	}
+	return map[string]any{
+		"created":        stats.created.Load(),
+		"in_flight":      stats.inFlight.Load(),
+		"max_in_flight":  stats.maxInFlight.Load(),
+		"settled":        settled,
+		"pools_open":     stats.poolsOpen.Load(),
+		"groups_created": stats.groups.Load(),
+	}
+}
+
+// countCreated records a new future.
+func countCreated() {
+	stats.created.Add(1)
+	n := stats.inFlight.Add(1)
+	for {
+		max := stats.maxInFlight.Load()
+		if n <= max || stats.maxInFlight.CompareAndSwap(max, n) {
+			return
+		}
+	}
+}
+
+// countSettled records a settled future.
+func countSettled(outcome Outcome) {
+	stats.inFlight.Add(-1)
+	stats.settled[outcome].Add(1)
+}
@@ -0,0 +1,63 @@
+package A
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestEnableExpvar(t *testing.T) {
+	EnableExpvar()
+	EnableExpvar()
+
+	read := func() map[string]any {
+		t.Helper()
+		var vars map[string]any
+		if err := json.Unmarshal([]byte(expvar.Get("future").String()), &vars); err != nil {
+			t.Fatalf("expected valid JSON, got %v", err)
+		}
+		return vars
+	}
+	before := read()
+
+	release := make(chan struct{})
+	pending := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	})
+	NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		panic("boom")
+	}).Result()
+	p := NewPool(1)
+	NewGroup(context.Background())
+
+	// Other tests may run futures concurrently, so only check that the
+	// counters moved by at least our share
+	during := read()
+	if got := during["created"].(float64) - before["created"].(float64); got < 2 {
+		t.Fatalf("expected at least 2 created futures, got %v", got)
+	}
+	if got := during["max_in_flight"].(float64); got < 1 {
+		t.Fatalf("expected a max in flight of at least 1, got %v", got)
+	}
+	settled := func(vars map[string]any, outcome Outcome) float64 {
+		return vars["settled"].(map[string]any)[outcome.String()].(float64)
+	}
+	if got := settled(during, Panicked) - settled(before, Panicked); got < 1 {
+		t.Fatalf("expected a panicked future to be counted, got %v", got)
+	}
+	if got := during["pools_open"].(float64) - before["pools_open"].(float64); got < 1 {
+		t.Fatalf("expected an open pool to be counted, got %v", got)
+	}
+	if got := during["groups_created"].(float64) - before["groups_created"].(float64); got < 1 {
+		t.Fatalf("expected a group to be counted, got %v", got)
+	}
+
+	close(release)
+	pending.Result()
+	p.Shutdown(context.Background())
+	if got := settled(read(), Succeeded) - settled(during, Succeeded); got < 1 {
+		t.Fatalf("expected a succeeded future to be counted, got %v", got)
+	}
+}
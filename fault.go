@@ -0,0 +1,139 @@
+package A
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// faultInjection gates WithFaultInjection.
+var faultInjection atomic.Bool
+
+// EnableFaultInjection turns WithFaultInjection on or off for the whole
+// process. It is off by default, so an injector left in production code
+// never fires unless a test or chaos run enables it.
+func EnableFaultInjection(enabled bool) {
+	faultInjection.Store(enabled)
+}
+
+// Fault is what a FaultInjector does to one execution of a task. The zero
+// Fault runs the task normally.
+type Fault struct {
+	// Latency delays the execution, or the failure, by this long. The
+	// delay ends early with the context's error if the context is done.
+	Latency time.Duration
+	// Err, if non-nil, is returned instead of running the task.
+	Err error
+	// Panic, if non-nil, is the value the execution panics with instead of
+	// running the task.
+	Panic any
+}
+
+// FaultInjector decides the fault for each execution of a task.
+type FaultInjector interface {
+	Inject(ctx context.Context) Fault
+}
+
+// WithFaultInjection lets fi fail, delay, or panic executions of the task,
+// to exercise error handling. Faults act inside any middleware, so retry or
+// metrics middleware sees them like real failures. It has no effect unless
+// EnableFaultInjection(true) has been called when the future is created.
+func WithFaultInjection(fi FaultInjector) Option {
+	return func(f *Future) {
+		if fi == nil {
+			f.invalidOption("WithFaultInjection", "nil injector")
+			return
+		}
+		f.faults = fi
+	}
+}
+
+// injectFaults wraps the task so every execution consults the injector.
+func (f *Future) injectFaults(task Task) Task {
+	fi := f.faults
+	return func(ctx context.Context) (any, error) {
+		fault := fi.Inject(ctx)
+		if fault.Latency > 0 {
+			if err := f.sleep(ctx, fault.Latency); err != nil {
+				return nil, err
+			}
+		}
+		if fault.Panic != nil {
+			panic(fault.Panic)
+		}
+		if fault.Err != nil {
+			return nil, fault.Err
+		}
+		return task(ctx)
+	}
+}
+
+// sleep waits for d on the future's clock, or until ctx is done.
+func (f *Future) sleep(ctx context.Context, d time.Duration) error {
+	if f.clock == nil {
+		return SleepCtx(ctx, d)
+	}
+	elapsed := make(chan struct{})
+	timer := f.afterFunc(d, func() {
+		close(elapsed)
+	})
+	defer timer.Stop()
+	select {
+	case <-elapsed:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// ScriptedFaults returns a FaultInjector that applies faults[i] to the i-th
+// execution it sees, counting from zero, and no fault after the script
+// runs out. For example, ScriptedFaults(Fault{Err: err}, Fault{Err: err})
+// fails the first two executions and lets the third succeed.
+func ScriptedFaults(faults ...Fault) FaultInjector {
+	return &scriptedFaults{faults: faults}
+}
+
+type scriptedFaults struct {
+	mu     sync.Mutex
+	faults []Fault
+	next   int
+}
+
+func (s *scriptedFaults) Inject(context.Context) Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.next >= len(s.faults) {
+		return Fault{}
+	}
+	s.next++
+	return s.faults[s.next-1]
+}
+
+// RandomFaults returns a FaultInjector that applies fault to each execution
+// with probability p. The same seed gives the same sequence of decisions.
+func RandomFaults(seed uint64, p float64, fault Fault) FaultInjector {
+	return &randomFaults{
+		rng:   rand.New(rand.NewPCG(seed, seed)),
+		p:     p,
+		fault: fault,
+	}
+}
+
+type randomFaults struct {
+	mu    sync.Mutex
+	rng   *rand.Rand
+	p     float64
+	fault Fault
+}
+
+func (r *randomFaults) Inject(context.Context) Fault {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.rng.Float64() < r.p {
+		return r.fault
+	}
+	return Fault{}
+}
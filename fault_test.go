@@ -0,0 +1,103 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+// retryMiddleware retries a failing task up to attempts times, waiting
+// backoff between attempts, the way callers compose retries today.
+func retryMiddleware(attempts int, clock *fakeclock.Clock, backoff time.Duration, waits *[]time.Time) Middleware {
+	return func(next Task) Task {
+		return func(ctx context.Context) (v any, err error) {
+			for i := 0; i < attempts; i++ {
+				if i > 0 {
+					*waits = append(*waits, clock.Now())
+					clock.Advance(backoff)
+				}
+				if v, err = next(ctx); err == nil {
+					return v, nil
+				}
+			}
+			return nil, err
+		}
+	}
+}
+
+func TestFaultInjection_Scripted(t *testing.T) {
+	EnableFaultInjection(true)
+	defer EnableFaultInjection(false)
+
+	errInjected := errors.New("injected")
+	clock := fakeclock.New(time.Now())
+	var waits []time.Time
+	runs := 0
+	f := NewFuture(context.Background(), func(context.Context) (any, error) {
+		runs++
+		return "ok", nil
+	}, WithSync(), WithClock(clock),
+		WithFaultInjection(ScriptedFaults(Fault{Err: errInjected}, Fault{Err: errInjected})),
+		WithMiddleware(retryMiddleware(3, clock, time.Second, &waits)))
+
+	if v, err := f.Result(); v != "ok" || err != nil {
+		t.Fatalf("expected the third attempt to succeed, got %v, %v", v, err)
+	}
+	if runs != 1 || len(waits) != 2 || waits[1].Sub(waits[0]) != time.Second {
+		t.Fatalf("expected two backed-off retries before one real run, got %d runs, waits %v", runs, waits)
+	}
+}
+
+func TestFaultInjection_LatencyAndPanic(t *testing.T) {
+	EnableFaultInjection(true)
+	defer EnableFaultInjection(false)
+
+	clock := fakeclock.New(time.Now())
+	f := NewFuture(context.Background(), func(context.Context) (any, error) {
+		return "ok", nil
+	}, WithClock(clock), WithFaultInjection(ScriptedFaults(Fault{Latency: time.Minute, Panic: "chaos"})))
+	clock.BlockUntil(1)
+	if f.Ready() {
+		t.Fatal("expected the injected latency to hold the task")
+	}
+	clock.Advance(time.Minute)
+	if _, err := f.Result(); err == nil || !f.Panicked() {
+		t.Fatalf("expected an injected panic, got %v", err)
+	}
+}
+
+func TestFaultInjection_Random(t *testing.T) {
+	decisions := func() []bool {
+		fi := RandomFaults(42, 0.5, Fault{Err: errors.New("injected")})
+		var failed []bool
+		for range 100 {
+			failed = append(failed, fi.Inject(context.Background()).Err != nil)
+		}
+		return failed
+	}
+	first, second := decisions(), decisions()
+	n := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatal("expected the same seed to give the same faults")
+		}
+		if first[i] {
+			n++
+		}
+	}
+	if n < 30 || n > 70 {
+		t.Fatalf("expected about half the executions to fail, got %d", n)
+	}
+}
+
+func TestFaultInjection_Disabled(t *testing.T) {
+	f := NewFuture(context.Background(), func(context.Context) (any, error) {
+		return "ok", nil
+	}, WithFaultInjection(ScriptedFaults(Fault{Err: errors.New("injected")})))
+	if v, err := f.Result(); v != "ok" || err != nil {
+		t.Fatalf("expected no fault without EnableFaultInjection, got %v, %v", v, err)
+	}
+}
@@ -2,13 +2,40 @@ package A
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"runtime/trace"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// ErrReleased is returned by Result after Release has dropped the result.
+var ErrReleased = errors.New("future result released")
+
+// ErrAlreadyStarted is returned by SetTask for a future that already has a
+// task, has started, or has settled.
+var ErrAlreadyStarted = errors.New("future already started")
+
 // Option defines functional options for Future.
 type Option func(*Future)
 
+// defaultOptions holds the options applied to every new future.
+var defaultOptions atomic.Pointer[[]Option]
+
+// SetDefaultOptions applies opts to every future created afterwards,
+// including pool and group members. Defaults are applied first, so options
+// passed at the call site override them; pool defaults such as
+// WithDefaultTaskTimeout sit in between. Calling it with no options removes
+// the defaults, which tests can use to reset them.
+func SetDefaultOptions(opts ...Option) {
+	opts = slices.Clone(opts)
+	defaultOptions.Store(&opts)
+}
+
 // WithLazy enables lazy execution of the Future.
 func WithLazy() Option {
 	return func(f *Future) {
@@ -16,102 +43,1009 @@ func WithLazy() Option {
 	}
 }
 
+// WithSync runs the task inline on the goroutine that starts the future:
+// inside NewFuture for eager futures, inside the first Result for lazy ones.
+// It suits cheap tasks and deterministic tests. Panics are still recovered,
+// and a future aborted before it starts never runs its task. Dispatchers
+// and governors are bypassed.
+func WithSync() Option {
+	return func(f *Future) {
+		f.inline = true
+	}
+}
+
+// WithTimeout bounds the task's execution time. The deadline is measured
+// from the moment the task starts executing, and when it passes the future
+// settles with a *TaskTimeoutError, which wraps context.DeadlineExceeded,
+// even if the task ignores its context. A deadline of the context the
+// future was created with that passes first is not a timeout of the task:
+// the future fails with that context's error instead.
+// A zero duration means no timeout; a negative one is invalid.
+func WithTimeout(d time.Duration) Option {
+	return func(f *Future) {
+		if d < 0 {
+			f.invalidOption("WithTimeout", "negative duration %v", d)
+			return
+		}
+		f.timeout, f.deadline, f.timeoutCause = d, time.Time{}, nil
+	}
+}
+
+// WithTimeoutCause is like WithTimeout, but the task's context is cancelled
+// with cause when the timeout passes, so context.Cause inside the task
+// returns it and the future's *TaskTimeoutError wraps it. Use it to tell
+// nested timeouts apart from the error chain alone.
+func WithTimeoutCause(d time.Duration, cause error) Option {
+	return func(f *Future) {
+		WithTimeout(d)(f)
+		f.timeoutCause = cause
+	}
+}
+
+// WithDeadlineCause bounds the task's execution by the time t instead of a
+// duration, cancelling the task's context with cause when t passes. A task
+// starting after t times out at once.
+func WithDeadlineCause(t time.Time, cause error) Option {
+	return func(f *Future) {
+		f.timeout, f.deadline, f.timeoutCause = 0, t, cause
+	}
+}
+
+// WithResultCleanup calls fn, exactly once, with any non-nil value the task
+// produces successfully that does not become the future's result, such as
+// a value returned after the future was aborted or timed out. Use it to
+// close connections or files that nobody will ever read.
+func WithResultCleanup(fn func(value any)) Option {
+	return func(f *Future) {
+		if fn == nil {
+			f.invalidOption("WithResultCleanup", "nil function")
+			return
+		}
+		f.cleanup = fn
+	}
+}
+
+// WithResultClone makes every read of a non-nil result return clone(value)
+// instead of the shared value: Result, ResultTimeout, ResultMeta,
+// Completion, OnComplete, Child tasks, and DelayResult's future. A consumer
+// mutating a map or slice then cannot corrupt what other consumers see; the
+// future keeps the original. Every read pays for a copy, so reserve it for
+// results that consumers actually mutate.
+func WithResultClone(clone func(value any) any) Option {
+	return func(f *Future) {
+		if clone == nil {
+			f.invalidOption("WithResultClone", "nil function")
+			return
+		}
+		f.clone = clone
+	}
+}
+
+// cloned returns v as a consumer should see it, copied with the
+// WithResultClone function if there is one.
+func (f *Future) cloned(v any) any {
+	if f.clone == nil || v == nil {
+		return v
+	}
+	return f.clone(v)
+}
+
+// State is the lifecycle stage of a Future.
+type State int
+
+const (
+	// Pending futures have not started their task yet: they are lazy,
+	// queued, or waiting for a limiter, semaphore, or governor.
+	Pending State = iota
+	// Running futures are executing their task.
+	Running
+	// Settled futures have a result.
+	Settled
+)
+
+func (s State) String() string {
+	switch s {
+	case Pending:
+		return "Pending"
+	case Running:
+		return "Running"
+	case Settled:
+		return "Settled"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// WithName names the future for diagnostics. The name appears in String,
+// in pending snapshots, and as a prefix of the future's errors, as in
+// `future "fetch": connection refused`; WithoutErrorWrapping turns the prefix
+// off.
+func WithName(name string) Option {
+	return func(f *Future) {
+		f.name = name
+	}
+}
+
+// WithoutErrorWrapping keeps a named future's errors exactly as the task
+// returned them, for callers that compare error strings.
+func WithoutErrorWrapping() Option {
+	return func(f *Future) {
+		f.noWrap = true
+	}
+}
+
+// WithNamef is like WithName but formats the name with fmt.Sprintf.
+func WithNamef(format string, args ...any) Option {
+	return WithName(fmt.Sprintf(format, args...))
+}
+
 type Future struct {
-	task func(context.Context) (any, error)
-	lazy bool
+	task        Task
+	middleware  []Middleware
+	faults      FaultInjector
+	taskContext func(context.Context) context.Context
+	propagate   []any
+	decorators  []func(context.Context) context.Context
+	runOnDone   bool
+	noAbort     bool
+	leak        *leakState
+	cleanup     func(any)
+	clone       func(any) any
+	invalid     []error
+	name        string
+	noWrap      bool
+	lazy        bool
+	inline      bool
+	callerInfo  bool
+	origin      string
+	timeout     time.Duration
+	sem         Semaphore
+	weight      int64
+	limiter     Limiter
+
+	errorDecorators []func(error) error
+
+	retries      int
+	retryPanics  bool
+	retryBackoff time.Duration
+	hedgeDelay   time.Duration
+	hedges       int
+
+	// awaitingTask is set for lazy futures created without a task, and
+	// startWanted once something tried to start one; see SetTask.
+	awaitingTask bool
+	startWanted  bool
+
+	deadline     time.Time
+	timeoutCause error
+
+	clock      Clock
+	governor   *Governor
+	dispatcher *Dispatcher
+	executor   Executor
+	registry   *Registry
+	metrics    Metrics
+
+	logger    *slog.Logger
+	logLevels *LogLevels
+	tracer    Tracer
+	span      Span
+
+	pprof       bool
+	pprofLabels map[string]string
+
+	runtimeTrace bool
+	traceTask    *trace.Task
+
+	slowAfter time.Duration
+	slowEvery time.Duration
+	onSlow    func(f *Future, running time.Duration)
+	slowTimer Timer
 
-	item interface{}
-	err  error
+	abortGrace time.Duration
+	onStuck    func(f *Future, stack []byte)
+	stuckTimer Timer
+	goid       uint64
 
+	progress *progress
+
+	// parent is the future a Child waits for; children are the unsettled
+	// futures derived from this one with Child.
+	parent   *Future
+	children []*Future
+
+	item     interface{}
+	err      error
+	settled  bool
+	hooks    []func()
+	running  bool
+	released []func()
+	exited   chan struct{}
+	panicked bool
+	outcome  Outcome
+	attempts int
+	onPanic  func(recovered any, stack []byte)
+
+	createdAt   time.Time
+	requestedAt time.Time // when a lazy future was first asked to start
+	startedAt   time.Time
+	settledAt   time.Time
+
+	member string // the name a group attributes errors to; see GoNamed
+
+	singleConsumer bool
+	unclaimed      *unclaimed // a single-consumer value nobody has taken
+
+	deps       []*Future
+	dependents int // unsettled futures declaring this one a dependency
+
+	key        string
+	keyed      bool
+	priority   int
+	seq        uint64
+	queued     bool // holds a pool queue slot; guarded by the pool lock
+	enqueuedAt time.Time
+	queueWait  time.Duration
+
+	base   context.Context
 	ctx    context.Context
-	cancel context.CancelFunc
+	cancel context.CancelCauseFunc
 	mu     sync.Mutex
 	once   sync.Once
-	closed sync.Once
-	done   chan struct{}
+	ready  atomic.Bool
+	done   chan struct{} // created on demand; guarded by mu
+	signal chan struct{} // see Dispatcher.Do
 }
 
-// NewFuture creates a new Future.
+// NewFuture creates a new Future. If an option is misused, the future fails
+// with an error wrapping ErrInvalidOption without running the task; use
+// NewFutureE to get that error up front. A lazy future may be created with
+// a nil task and given one later with SetTask. A nil ctx is treated as
+// context.Background, here and in every other constructor.
 func NewFuture(ctx context.Context, task func(context.Context) (any, error), opts ...Option) *Future {
-	newCtx, cancel := context.WithCancel(ctx)
-	f := &Future{
-		ctx:    newCtx,
-		cancel: cancel,
-		task:   task,
-		done:   make(chan struct{}),
+	f := newFuture(ctx, task, opts...)
+	if task == nil {
+		f.awaitTask()
+	}
+	if f.rejectInvalid() {
+		return f
+	}
+	if !f.lazy {
+		f.once.Do(f.start)
+	}
+	return f
+}
+
+// newFuture creates a Future without starting it.
+func newFuture(ctx context.Context, task func(context.Context) (any, error), opts ...Option) *Future {
+	f := &Future{}
+	f.init(ctx, task, opts...)
+	return f
+}
+
+// init prepares a zero Future.
+func (f *Future) init(ctx context.Context, task func(context.Context) (any, error), opts ...Option) {
+	ctx = orBackground(ctx)
+	f.task = task
+	f.governor = defaultGovernor
+	f.metrics = loadDefaultMetrics()
+	f.logger = defaultLogger.Load()
+	if defaults := defaultOptions.Load(); defaults != nil {
+		for _, opt := range *defaults {
+			opt(f)
+		}
 	}
 	for _, opt := range opts {
 		opt(f)
 	}
+	f.createdAt = f.now()
+	if f.callerInfo {
+		f.origin = callerOrigin()
+	}
+	if report := leakReport.Load(); report != nil {
+		f.trackLeak(*report)
+	}
+	if f.task != nil {
+		f.task = f.wrapTask(f.task)
+	}
+	if f.runtimeTrace {
+		ctx, f.traceTask = trace.NewTask(ctx, f.traceName())
+	}
+	f.base = ctx
+	ctx = f.taskCtx(ctx)
+	if f.progress != nil {
+		ctx = context.WithValue(ctx, progressKey{}, f)
+	}
+	if f.noAbort {
+		f.ctx = ctx
+	} else {
+		f.ctx, f.cancel = context.WithCancelCause(ctx)
+	}
+	countCreated()
+	if f.registry != nil {
+		f.registry.add(f)
+	}
+	if f.deps != nil {
+		f.dependOn()
+	}
+}
+
+// awaitTask lets a lazy future created with a nil task wait for SetTask.
+// An eager future needs its task up front.
+func (f *Future) awaitTask() {
 	if !f.lazy {
-		f.once.Do(f.start)
+		f.invalidOption("NewFuture", "nil task without WithLazy")
+		return
+	}
+	f.awaitingTask = true
+}
+
+// wrapTask applies fault injection and middleware to task.
+func (f *Future) wrapTask(task Task) Task {
+	if f.faults != nil && faultInjection.Load() {
+		task = f.injectFaults(task)
 	}
+	task = wrap(task, f.middleware)
+	f.middleware = nil
+	return task
+}
+
+// newSettled creates a Future that is already settled with the given result.
+func newSettled(ctx context.Context, item any, err error) *Future {
+	// There is no task to cancel.
+	f := newFuture(ctx, nil, WithoutAbort())
+	f.once.Do(func() {})
+	f.settle(item, err)
 	return f
 }
 
 // Result waits for the result to be ready and returns it.
 func (f *Future) Result() (interface{}, error) {
+	f.consume()
 	f.once.Do(f.start)
-	<-f.done
+	if !f.ready.Load() {
+		<-f.doneChan()
+	}
 
+	f.mu.Lock()
+	item, err := f.take()
+	f.mu.Unlock()
+	return f.cloned(item), err
+}
+
+// peek returns the stored result without waiting.
+func (f *Future) peek() (any, error) {
+	f.consume()
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	return f.item, f.err
 }
 
+// Release drops the stored result of a settled future, so a future kept
+// around after its owner has consumed the result does not pin it. Result
+// returns ErrReleased afterwards. It has no effect on an unsettled future.
+// The task itself is dropped automatically once it has returned. The value
+// of a WithSingleConsumer future that nobody took goes to the
+// WithResultCleanup function.
+func (f *Future) Release() {
+	f.consume()
+	f.mu.Lock()
+	if !f.settled {
+		f.mu.Unlock()
+		return
+	}
+	var item any
+	if f.singleConsumer {
+		item, _ = f.take()
+	}
+	f.item, f.err = nil, ErrReleased
+	f.mu.Unlock()
+	if item != nil {
+		f.discard(item, nil)
+	}
+}
+
 // Ready returns true if the result is available.
 func (f *Future) Ready() bool {
-	select {
-	case <-f.done:
-		return true
-	default:
-		return false
-	}
+	return f.ready.Load()
 }
 
 // Done returns a channel that is closed when the result is ready.
 func (f *Future) Done() <-chan struct{} {
+	f.consume()
+	return f.doneChan()
+}
+
+// closedChan stands in for the done channel of futures that were ready
+// before anyone asked for one.
+var closedChan = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// doneChan returns the done channel, creating it on first use, so futures
+// that are only consumed once ready never allocate one.
+func (f *Future) doneChan() chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.done == nil {
+		if f.ready.Load() {
+			return closedChan
+		}
+		f.done = make(chan struct{})
+	}
 	return f.done
 }
 
+// Panicked returns true if the task panicked.
+func (f *Future) Panicked() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.panicked
+}
+
+// QueueWait returns how long the future waited in a pool queue before a
+// worker started it. It is zero for futures that were not queued.
+func (f *Future) QueueWait() time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.queueWait
+}
+
+// Name returns the name given with WithName.
+func (f *Future) Name() string {
+	return f.name
+}
+
+// String describes the future for debug logs, for example
+// "Future(name=load-user state=Running age=1.2s)".
+func (f *Future) String() string {
+	f.mu.Lock()
+	state := f.state()
+	f.mu.Unlock()
+	age := f.since(f.createdAt).Round(time.Millisecond)
+	if f.name == "" {
+		return fmt.Sprintf("Future(state=%s age=%s)", state, age)
+	}
+	return fmt.Sprintf("Future(name=%s state=%s age=%s)", f.name, state, age)
+}
+
+// State returns the lifecycle stage of the future.
+func (f *Future) State() State {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state()
+}
+
+// state returns the lifecycle stage. It must be called with f.mu held.
+func (f *Future) state() State {
+	switch {
+	case f.settled:
+		return Settled
+	case f.running:
+		return Running
+	}
+	return Pending
+}
+
+// aborted reports whether the future was settled by an abort.
+func (f *Future) aborted() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.settled && f.outcome == Aborted
+}
+
+// CreatedAt returns when the future was created.
+func (f *Future) CreatedAt() time.Time {
+	return f.createdAt
+}
+
+// StartedAt returns when the task started executing, after any lazy, queue,
+// limiter, or semaphore wait. It reports false if the task has not started.
+func (f *Future) StartedAt() (time.Time, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.startedAt, !f.startedAt.IsZero()
+}
+
+// CompletedAt returns when the future settled. It reports false if the
+// future has not settled.
+func (f *Future) CompletedAt() (time.Time, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.settledAt, f.settled
+}
+
+// ExecDuration returns how long the task executed, from StartedAt to
+// CompletedAt, excluding any time spent waiting to start. It reports false
+// until the future has settled, and for futures that settled without
+// starting.
+func (f *Future) ExecDuration() (time.Duration, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.settled || f.startedAt.IsZero() {
+		return 0, false
+	}
+	return f.settledAt.Sub(f.startedAt), true
+}
+
+// Priority returns the priority the future was submitted with.
+func (f *Future) Priority() int {
+	return f.priority
+}
+
 // Abort cancels the task execution.
 func (f *Future) Abort() {
+	f.AbortWithError(context.Canceled)
+}
+
+// AbortWithError cancels the task execution with err as the context cause
+// and settles the future with err. A nil err means context.Canceled.
+func (f *Future) AbortWithError(err error) {
+	f.consume()
+	if err == nil {
+		err = context.Canceled
+	}
+	if f.cancel != nil {
+		f.cancel(err)
+	}
+	f.store(nil, err, Aborted)
+
+	// Children are aborted even if f has already succeeded.
 	f.mu.Lock()
-	defer f.mu.Unlock()
-	select {
-	case <-f.done:
-		return
-	default:
-		if f.cancel != nil {
-			f.cancel()
+	children := slices.Clone(f.children)
+	f.mu.Unlock()
+	for _, child := range children {
+		child.AbortWithError(err)
+	}
+}
+
+// Child creates a future whose task runs with f's value once f succeeds.
+// The child's context is derived from the context f was created with, and
+// aborting f aborts the child with the same cause, whether or not f has
+// settled. If f fails, the child settles with f's error without running.
+//
+// The child starts as soon as f succeeds. A child of a lazy future does not
+// start f; calling Result on the child does. WithLazy has no effect on the
+// child itself.
+func (f *Future) Child(task func(ctx context.Context, parentValue any) (any, error), opts ...Option) *Future {
+	var child *Future
+	child = newFuture(f.base, func(ctx context.Context) (any, error) {
+		value, _ := f.peek()
+		return task(ctx, f.cloned(value))
+	}, opts...)
+	if child.rejectInvalid() {
+		return child
+	}
+	child.parent = f
+	f.mu.Lock()
+	f.children = append(f.children, child)
+	f.mu.Unlock()
+	child.whenDone(func() {
+		f.removeChild(child)
+	})
+	f.whenDone(func() {
+		if _, err := f.peek(); err != nil {
+			child.AbortWithError(err)
+			return
 		}
-		f.item, f.err = nil, context.Canceled
-		f.markDone()
+		child.launch()
+	})
+	if !f.lazy {
+		child.once.Do(child.start)
 	}
+	return child
 }
 
-// start executes the task and stores the result.
+// removeChild forgets a settled child.
+func (f *Future) removeChild(child *Future) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if i := slices.Index(f.children, child); i >= 0 {
+		f.children = slices.Delete(f.children, i, i+1)
+	}
+}
+
+// AbortAndWait aborts the future and waits until its task function has
+// actually returned, or until ctx is done. Since a task that ignores its
+// context cannot be stopped, a non-nil error means the task is still running.
+func (f *Future) AbortAndWait(ctx context.Context) error {
+	f.Abort()
+	return f.waitExit(ctx)
+}
+
+// SetTask gives a lazy future created with a nil task its task. The future
+// then starts like any lazy one, or at once if Result or a Child has
+// already tried to start it. Until SetTask is called, such callers block,
+// so abort a future whose task will never come. SetTask
+// returns ErrAlreadyStarted if the future already has a task or has
+// settled, and ErrInvalidOption for a nil task.
+func (f *Future) SetTask(task func(context.Context) (any, error)) error {
+	if task == nil {
+		return fmt.Errorf("%w: SetTask: nil task", ErrInvalidOption)
+	}
+	f.mu.Lock()
+	if !f.awaitingTask || f.settled {
+		f.mu.Unlock()
+		return ErrAlreadyStarted
+	}
+	f.awaitingTask = false
+	f.task = f.wrapTask(task)
+	start := f.startWanted
+	f.mu.Unlock()
+	if start {
+		f.launch()
+	}
+	return nil
+}
+
+// start executes the task in a new goroutine, once the governor admits it.
+// A child instead starts its parent and runs once the parent succeeds.
 func (f *Future) start() {
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				f.mu.Lock()
-				f.err = fmt.Errorf("panic occurred: %v", r)
-				f.mu.Unlock()
-				f.markDone()
+	f.startDependencies()
+	if f.parent != nil {
+		f.parent.once.Do(f.parent.start)
+		return
+	}
+	f.mu.Lock()
+	if f.lazy {
+		f.requestedAt = f.now()
+	}
+	// Without a task yet, SetTask launches the future once it arrives.
+	awaiting := f.awaitingTask
+	f.startWanted = awaiting
+	f.mu.Unlock()
+	if awaiting {
+		return
+	}
+	f.launch()
+}
+
+// launch runs the task inline, on its executor or dispatcher, or in a new
+// goroutine once the governor admits it.
+func (f *Future) launch() {
+	if f.inline {
+		f.run()
+		return
+	}
+	if f.executor != nil {
+		f.executor.Execute(f.run)
+		return
+	}
+	if f.dispatcher != nil {
+		f.dispatcher.dispatch(f)
+		return
+	}
+	if f.governor != nil {
+		f.governor.launch(f)
+		return
+	}
+	go f.run()
+}
+
+// run executes the task on the calling goroutine and stores the result.
+func (f *Future) run() {
+	if f.Ready() {
+		// Aborted before the task got a chance to run.
+		return
+	}
+	if f.ctx.Err() != nil && !f.runOnDone {
+		// The caller is gone; skip whatever setup the task would do.
+		f.settle(nil, context.Cause(f.ctx))
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			f.mu.Lock()
+			f.panicked = true
+			f.mu.Unlock()
+			if f.onPanic != nil {
+				f.onPanic(r, debug.Stack())
 			}
-		}()
-		res, err := f.task(f.ctx)
-		f.mu.Lock()
-		f.item, f.err = res, err
-		f.mu.Unlock()
-		f.markDone()
+			f.store(nil, f.attribute(&PanicError{Value: r}), Panicked)
+		}
 	}()
+	if f.limiter != nil {
+		if err := f.limiter.Wait(f.ctx); err != nil {
+			f.settle(nil, err)
+			return
+		}
+	}
+	if f.sem != nil {
+		if err := f.sem.Acquire(f.ctx, f.weight); err != nil {
+			f.settle(nil, err)
+			return
+		}
+		defer f.sem.Release(f.weight)
+	}
+	if !f.begin() {
+		// Aborted while waiting to start.
+		return
+	}
+	defer f.exit()
+	if f.metrics != nil {
+		f.metrics.FutureStarted(f.name)
+	}
+	if f.logger != nil {
+		f.logStart()
+	}
+	if f.onSlow != nil && f.slowAfter > 0 {
+		f.watch()
+	}
+	ctx := f.ctx
+	limit, bounded := f.limit()
+	// Only the task's own deadline, recognised by its cause, is a timeout;
+	// a parent's deadline propagates as the parent's error.
+	var cause error
+	if bounded {
+		cause = f.timeoutCause
+		if cause == nil {
+			cause = &deadlineCause{}
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = f.withTimeoutCause(ctx, limit, cause)
+		defer cancel()
+		stop := context.AfterFunc(ctx, func() {
+			if context.Cause(ctx) == cause {
+				f.store(nil, f.timedOut(limit), TimedOut)
+			}
+		})
+		defer stop()
+	}
+	res, err := f.attempt(ctx)
+	if bounded && context.Cause(ctx) == cause {
+		// Report the timeout the same way whether or not the task noticed.
+		f.store(nil, f.timedOut(limit), TimedOut)
+		f.discard(res, err)
+		return
+	}
+	if err != nil {
+		err = f.attribute(err)
+	}
+	if !f.settle(res, err) {
+		f.discard(res, err)
+	}
 }
 
-// markDone marks the future as done and closes the done channel.
-func (f *Future) markDone() {
-	f.closed.Do(func() {
-		close(f.done)
+// discard hands a result that did not settle the future to the cleanup.
+func (f *Future) discard(res any, err error) {
+	if f.cleanup != nil && res != nil && err == nil {
+		f.cleanup(res)
+	}
+}
+
+// call runs the task with the requested instrumentation.
+func (f *Future) call(ctx context.Context) (any, error) {
+	return f.callTask(ctx, f.task)
+}
+
+// callTask runs task with the requested instrumentation. Calls made off
+// the run goroutine, such as hedges, pass a task captured under f.mu,
+// since f.task is dropped once the run goroutine exits.
+func (f *Future) callTask(ctx context.Context, task Task) (res any, err error) {
+	if !f.runtimeTrace {
+		return f.callLabeled(ctx, task)
+	}
+	f.mu.Lock()
+	attempt := f.attempts
+	f.mu.Unlock()
+	trace.Logf(ctx, "future", "attempt %d started after %v", attempt, f.since(f.createdAt))
+	trace.WithRegion(ctx, "attempt", func() {
+		res, err = f.callLabeled(ctx, task)
 	})
+	return res, err
+}
+
+// callLabeled runs task under pprof labels if requested.
+func (f *Future) callLabeled(ctx context.Context, task Task) (any, error) {
+	if f.pprof {
+		return f.runLabeled(ctx, task)
+	}
+	return task(ctx)
+}
+
+// attribute prefixes err with the future's name, if it has one.
+func (f *Future) attribute(err error) error {
+	if f.name == "" || f.noWrap {
+		return err
+	}
+	return fmt.Errorf("future %q: %w", f.name, err)
+}
+
+// begin marks the task as running unless the future has already settled.
+func (f *Future) begin() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.settled {
+		return false
+	}
+	f.running = true
+	f.attempts++
+	f.startedAt = f.now()
+	if f.onStuck != nil {
+		f.goid = goroutineID()
+	}
+	return true
+}
+
+// exit marks the task as no longer running and releases exit waiters.
+func (f *Future) exit() {
+	f.mu.Lock()
+	f.running = false
+	if f.stuckTimer != nil {
+		f.stuckTimer.Stop()
+		f.stuckTimer = nil
+	}
+	if f.exited != nil {
+		close(f.exited)
+		f.exited = nil
+	}
+	var released []func()
+	if f.settled {
+		released, f.released = f.released, nil
+		// Drop the closure and whatever it captured.
+		f.task = nil
+	}
+	f.mu.Unlock()
+	for _, fn := range released {
+		fn()
+	}
+}
+
+// waitExit blocks until the task function is not running, or until ctx is
+// done. It returns immediately if the task never started or has returned.
+func (f *Future) waitExit(ctx context.Context) error {
+	f.mu.Lock()
+	if !f.running {
+		f.mu.Unlock()
+		return nil
+	}
+	if f.exited == nil {
+		f.exited = make(chan struct{})
+	}
+	exited := f.exited
+	f.mu.Unlock()
+	select {
+	case <-exited:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// setQueueWait records the time spent in a pool queue.
+func (f *Future) setQueueWait(d time.Duration) {
+	f.mu.Lock()
+	f.queueWait = d
+	f.mu.Unlock()
+}
+
+// whenDone registers fn to run once the future settles, before its waiters
+// are released. If the future has already settled, fn runs immediately.
+func (f *Future) whenDone(fn func()) {
+	f.mu.Lock()
+	if f.settled {
+		f.mu.Unlock()
+		fn()
+		return
+	}
+	f.hooks = append(f.hooks, fn)
+	f.mu.Unlock()
+}
+
+// whenReleased registers fn to run once the future has settled and its task
+// function is not running: when the task returns, or at settlement if the
+// task never started. Unlike whenDone hooks, it waits for a task that
+// ignores its context after a timeout or abort.
+func (f *Future) whenReleased(fn func()) {
+	f.mu.Lock()
+	if f.settled && !f.running {
+		f.mu.Unlock()
+		fn()
+		return
+	}
+	f.released = append(f.released, fn)
+	f.mu.Unlock()
+}
+
+// settle stores the result unless the future has already been settled.
+// It reports whether the result was stored.
+func (f *Future) settle(item any, err error) bool {
+	outcome := Succeeded
+	if err != nil {
+		outcome = Failed
+	}
+	return f.store(item, err, outcome)
+}
+
+// store settles the future like settle, recording how it settled.
+func (f *Future) store(item any, err error, outcome Outcome) bool {
+	f.mu.Lock()
+	if f.settled {
+		f.mu.Unlock()
+		return false
+	}
+	f.settled = true
+	if err != nil && f.errorDecorators != nil {
+		err = f.decorate(err)
+	}
+	if f.leak != nil {
+		f.leak.settled.Store(true)
+	}
+	f.outcome = outcome
+	f.settledAt = f.now()
+	f.item, f.err = item, err
+	if f.singleConsumer && err == nil {
+		f.holdUnclaimed(item)
+	}
+	hooks := f.hooks
+	f.hooks = nil
+	if !f.running {
+		hooks = append(hooks, f.released...)
+		f.released = nil
+		f.task = nil
+	}
+	requestedAt, startedAt, settledAt, attempts := f.requestedAt, f.startedAt, f.settledAt, f.attempts
+	span := f.span
+	f.span = nil
+	if f.slowTimer != nil {
+		f.slowTimer.Stop()
+		f.slowTimer = nil
+	}
+	if f.progress != nil {
+		f.finishProgress()
+	}
+	if f.running && f.onStuck != nil {
+		f.watchStuck()
+	}
+	f.mu.Unlock()
+	countSettled(outcome)
+
+	if f.cancel != nil {
+		f.cancel(context.Canceled)
+	}
+	if f.traceTask != nil {
+		trace.WithRegion(f.base, "callbacks", func() {
+			for _, fn := range hooks {
+				fn()
+			}
+		})
+		f.traceTask.End()
+	} else {
+		for _, fn := range hooks {
+			fn()
+		}
+	}
+	execTime, waitTime := execWait(f.createdAt, startedAt, settledAt)
+	if f.metrics != nil {
+		if wm, ok := f.metrics.(WaitMetrics); ok {
+			idleTime, startDelay := splitWait(f.createdAt, requestedAt, startedAt, settledAt)
+			wm.FutureWaited(f.name, idleTime, startDelay)
+		}
+		f.metrics.FutureSettled(f.name, outcome, execTime, waitTime)
+		if cm, ok := f.metrics.(CompletionMetrics); ok {
+			cm.FutureCompleted(f.settledCompletion())
+		}
+	}
+	if span != nil {
+		span.End(err)
+	}
+	f.markDone()
+	if f.logger != nil {
+		// Log after releasing waiters, so a slow handler cannot delay them.
+		f.logSettled(outcome, err, execTime, waitTime, attempts)
+	}
+	return true
+}
+
+// markDone marks the future as ready and closes the done channel, if
+// anyone has asked for it.
+func (f *Future) markDone() {
+	f.mu.Lock()
+	f.ready.Store(true)
+	done := f.done
+	f.mu.Unlock()
+	if done != nil {
+		close(done)
+	}
 }
@@ -2,8 +2,15 @@ package A
 
 import (
 	"context"
+	"errors"
+	"maps"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
 )
 
 func TestFuture_Result(t *testing.T) {
@@ -144,3 +151,686 @@ func TestFuture_Don(t *testing.T) {
 		t.Fatal("expected done channel to be closed")
 	}
 }
+
+func TestFuture_Timeout(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	seen := make(chan error, 1)
+	task := func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		seen <- ctx.Err()
+		return nil, ctx.Err()
+	}
+
+	future := NewFuture(context.Background(), task, WithTimeout(50*time.Millisecond), WithClock(clock))
+	clock.BlockUntil(1)
+	clock.Advance(49 * time.Millisecond)
+	if future.Ready() {
+		t.Fatal("expected the future to run until its deadline")
+	}
+	clock.Advance(time.Millisecond)
+
+	// The task should be cut short by its timeout
+	_, err := future.Result()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if err := <-seen; err != context.DeadlineExceeded {
+		t.Fatalf("expected the task context to report context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestFuture_AbortAndWait(t *testing.T) {
+	exited := make(chan struct{})
+	started := make(chan struct{})
+	task := func(ctx context.Context) (any, error) {
+		close(started)
+		defer close(exited)
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond) // Simulate cleanup
+		return nil, ctx.Err()
+	}
+
+	future := NewFuture(context.Background(), task)
+	<-started
+
+	// AbortAndWait returns only once the task has returned
+	if err := future.AbortAndWait(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	select {
+	case <-exited:
+	default:
+		t.Fatal("expected the task to have returned")
+	}
+}
+
+func TestFuture_AbortAndWaitStuck(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	task := func(ctx context.Context) (any, error) {
+		close(started)
+		<-release // Ignore cancellation
+		return nil, nil
+	}
+
+	future := NewFuture(context.Background(), task)
+	<-started
+
+	// A task that ignores cancellation makes AbortAndWait give up
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := future.AbortAndWait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if !future.Ready() {
+		t.Fatal("expected the future to be settled regardless")
+	}
+}
+
+func TestFuture_Child(t *testing.T) {
+	parent := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return 20, nil
+	})
+	child := parent.Child(func(ctx context.Context, parentValue any) (any, error) {
+		return parentValue.(int) + 1, nil
+	})
+	grandchild := child.Child(func(ctx context.Context, parentValue any) (any, error) {
+		return parentValue.(int) * 2, nil
+	})
+
+	result, err := grandchild.Result()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != 42 {
+		t.Fatalf("expected result 42, got %v", result)
+	}
+}
+
+func TestFuture_ChildFailure(t *testing.T) {
+	errParent := errors.New("parent failed")
+	parent := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, errParent
+	})
+	child := parent.Child(func(ctx context.Context, parentValue any) (any, error) {
+		t.Error("child should not run after its parent failed")
+		return nil, nil
+	})
+	if _, err := child.Result(); !errors.Is(err, errParent) {
+		t.Fatalf("expected the parent error, got %v", err)
+	}
+}
+
+func TestFuture_ChildAbortChain(t *testing.T) {
+	errShutdown := errors.New("shutting down")
+	root := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "root", nil
+	}, WithLazy())
+
+	// Children may be registered before a lazy parent starts
+	child := root.Child(func(ctx context.Context, parentValue any) (any, error) {
+		t.Error("child should not run after the root was aborted")
+		return nil, nil
+	})
+	grandchild := child.Child(func(ctx context.Context, parentValue any) (any, error) {
+		t.Error("grandchild should not run after the root was aborted")
+		return nil, nil
+	})
+	if root.State() != Pending {
+		t.Fatal("expected a child not to start its lazy parent")
+	}
+
+	root.AbortWithError(errShutdown)
+	for _, f := range []*Future{root, child, grandchild} {
+		if _, err := f.Result(); !errors.Is(err, errShutdown) {
+			t.Fatalf("expected the abort cause, got %v", err)
+		}
+	}
+}
+
+func TestFuture_ChildAbortAfterParentSettled(t *testing.T) {
+	parent := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	})
+	parent.Result()
+
+	causes := make(chan error, 1)
+	started := make(chan struct{})
+	child := parent.Child(func(ctx context.Context, parentValue any) (any, error) {
+		close(started)
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return nil, ctx.Err()
+	})
+	<-started
+
+	// The linkage outlives the parent's own settlement
+	parent.Abort()
+	if _, err := child.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if cause := <-causes; !errors.Is(cause, context.Canceled) {
+		t.Fatalf("expected the child context to be cancelled, got %v", cause)
+	}
+}
+
+func TestFuture_ChildStartsLazyParent(t *testing.T) {
+	parent := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "parent", nil
+	}, WithLazy())
+	child := parent.Child(func(ctx context.Context, parentValue any) (any, error) {
+		return parentValue, nil
+	})
+
+	// Waiting on the child starts the lazy parent
+	if result, _ := child.Result(); result != "parent" {
+		t.Fatalf("expected result 'parent', got %v", result)
+	}
+}
+
+func TestFuture_Name(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}, WithNamef("load-user-%d", 7))
+	<-started
+
+	if name := future.Name(); name != "load-user-7" {
+		t.Fatalf("expected name 'load-user-7', got %q", name)
+	}
+	if s := future.String(); !strings.HasPrefix(s, "Future(name=load-user-7 state=Running age=") {
+		t.Fatalf("unexpected String output %q", s)
+	}
+	close(release)
+	future.Result()
+	if s := future.String(); !strings.Contains(s, "state=Settled") {
+		t.Fatalf("unexpected String output %q", s)
+	}
+}
+
+func TestFuture_NameInErrors(t *testing.T) {
+	panicky := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		panic("boom")
+	}, WithName("parse"))
+	if _, err := panicky.Result(); err == nil || err.Error() != `future "parse": panic occurred: boom` {
+		t.Fatalf("expected the panic error to name the future, got %v", err)
+	}
+
+	slow := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, WithName("fetch"), WithTimeout(10*time.Millisecond))
+	_, err := slow.Result()
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), `future "fetch"`) {
+		t.Fatalf("expected the timeout error to name the future, got %v", err)
+	}
+}
+
+func TestFuture_ErrorWrapping(t *testing.T) {
+	errRefused := errors.New("connection refused")
+	refuse := func(ctx context.Context) (any, error) {
+		return nil, errRefused
+	}
+
+	_, err := NewFuture(context.Background(), refuse, WithName("fetch")).Result()
+	if !errors.Is(err, errRefused) || err.Error() != `future "fetch": connection refused` {
+		t.Fatalf("expected the task error to name the future, got %v", err)
+	}
+
+	// Unnamed futures and the opt-out keep the error as is
+	if _, err := NewFuture(context.Background(), refuse).Result(); err != errRefused {
+		t.Fatalf("expected the bare error, got %v", err)
+	}
+	_, err = NewFuture(context.Background(), refuse, WithName("fetch"), WithoutErrorWrapping()).Result()
+	if err != errRefused {
+		t.Fatalf("expected the bare error with WithoutErrorWrapping, got %v", err)
+	}
+
+	// A group adds the member's index on top
+	g := NewGroup(context.Background())
+	g.Go(func(ctx context.Context) (any, error) {
+		return "ok", nil
+	})
+	g.Go(refuse, WithName("fetch"))
+	err = g.Wait()
+	var memberErr *MemberError
+	if !errors.As(err, &memberErr) || memberErr.Index != 1 || !errors.Is(err, errRefused) {
+		t.Fatalf("expected a MemberError for member 1, got %v", err)
+	}
+	if err.Error() != `group member 1: future "fetch": connection refused` {
+		t.Fatalf("unexpected error message %q", err.Error())
+	}
+}
+
+func TestFuture_Timing(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		clock.Advance(20 * time.Millisecond)
+		return nil, nil
+	}, WithLazy(), WithClock(clock))
+	clock.Advance(30 * time.Millisecond)
+	if _, ok := future.StartedAt(); ok {
+		t.Fatal("expected a lazy future not to have started")
+	}
+	if _, ok := future.ExecDuration(); ok {
+		t.Fatal("expected no execution time before settlement")
+	}
+	future.Result()
+
+	// The lazy wait is not part of the execution time
+	startedAt, ok := future.StartedAt()
+	if !ok || startedAt.Sub(future.CreatedAt()) != 30*time.Millisecond {
+		t.Fatalf("expected the start to come after the lazy wait, got %v", startedAt.Sub(future.CreatedAt()))
+	}
+	completedAt, ok := future.CompletedAt()
+	if !ok || completedAt.Before(startedAt) {
+		t.Fatalf("expected completion after the start, got %v", completedAt)
+	}
+	exec, ok := future.ExecDuration()
+	if !ok || exec != 20*time.Millisecond {
+		t.Fatalf("expected execution time to exclude the lazy wait, got %v", exec)
+	}
+
+	aborted := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithLazy())
+	aborted.Abort()
+	if _, ok := aborted.ExecDuration(); ok {
+		t.Fatal("expected no execution time for a future that never started")
+	}
+}
+
+func TestSetDefaultOptions(t *testing.T) {
+	SetDefaultOptions(WithTimeout(10*time.Millisecond), WithName("default"))
+	defer SetDefaultOptions()
+
+	slow := func(ctx context.Context) (any, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+			return "done", nil
+		}
+	}
+
+	// Defaults apply to every future
+	defaulted := NewFuture(context.Background(), slow)
+	if _, err := defaulted.Result(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the default timeout, got %v", err)
+	}
+	if name := defaulted.Name(); name != "default" {
+		t.Fatalf("expected the default name, got %q", name)
+	}
+
+	// Call-site options are applied after the defaults
+	overridden := NewFuture(context.Background(), slow, WithTimeout(time.Second))
+	if result, err := overridden.Result(); err != nil || result != "done" {
+		t.Fatalf("expected the call-site timeout to win, got %v, %v", result, err)
+	}
+
+	// Resetting removes the defaults
+	SetDefaultOptions()
+	if name := NewFuture(context.Background(), slow).Name(); name != "" {
+		t.Fatalf("expected no default name after reset, got %q", name)
+	}
+}
+
+func TestFuture_TaskCollectable(t *testing.T) {
+	payload := new([1 << 20]byte)
+	collected := make(chan struct{})
+	runtime.AddCleanup(payload, func(struct{}) {
+		close(collected)
+	}, struct{}{})
+	f := NewFuture(context.Background(), func(context.Context) (any, error) {
+		return len(payload), nil
+	})
+	payload = nil
+	if _, err := f.Result(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The task may still be returning after the result is delivered.
+	f.waitExit(context.Background())
+	for range 10 {
+		runtime.GC()
+		select {
+		case <-collected:
+			runtime.KeepAlive(f)
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatalf("task closure still reachable from a settled future")
+}
+
+func TestFuture_Release(t *testing.T) {
+	f := NewFuture(context.Background(), func(context.Context) (any, error) {
+		return "value", nil
+	}, WithLazy())
+	f.Release()
+	if v, err := f.Result(); v != "value" || err != nil {
+		t.Fatalf("Release before settlement dropped the result: %v, %v", v, err)
+	}
+	f.Release()
+	if v, err := f.Result(); v != nil || !errors.Is(err, ErrReleased) {
+		t.Fatalf("expected ErrReleased, got %v, %v", v, err)
+	}
+}
+
+func TestFuture_ResultClone(t *testing.T) {
+	original := map[string]int{"hits": 1}
+	f := NewFuture(context.Background(), func(context.Context) (any, error) {
+		return original, nil
+	}, WithResultClone(func(v any) any {
+		return maps.Clone(v.(map[string]int))
+	}))
+
+	// Concurrent consumers each mutate their own copy
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, _ := f.Result()
+			m := v.(map[string]int)
+			if m["hits"] != 1 {
+				t.Errorf("expected an untouched copy, got %v", m)
+			}
+			m["hits"] = i + 100
+		}()
+	}
+	wg.Wait()
+	if original["hits"] != 1 {
+		t.Fatalf("expected the original to be untouched, got %v", original)
+	}
+
+	v, _, _ := f.ResultMeta()
+	c := f.Completion()
+	child, _ := f.Child(func(ctx context.Context, v any) (any, error) {
+		return v, nil
+	}).Result()
+	for _, got := range []any{v, c.Value, child} {
+		m := got.(map[string]int)
+		m["hits"]++
+		if m["hits"] != 2 {
+			t.Fatalf("expected independent copies, got %v", m)
+		}
+	}
+}
+
+func TestFuture_SetTask(t *testing.T) {
+	upper := func(next Task) Task {
+		return func(ctx context.Context) (any, error) {
+			v, err := next(ctx)
+			return strings.ToUpper(v.(string)), err
+		}
+	}
+	f := NewFuture(context.Background(), nil, WithLazy(), WithMiddleware(upper))
+	completions := make(chan Completion, 1)
+	f.OnComplete(func(c Completion) {
+		completions <- c
+	})
+
+	// Result blocks until the task arrives
+	results := make(chan any, 1)
+	go func() {
+		v, _ := f.Result()
+		results <- v
+	}()
+	select {
+	case v := <-results:
+		t.Fatalf("expected Result to wait for SetTask, got %v", v)
+	case <-time.After(10 * time.Millisecond):
+	}
+	if err := f.SetTask(func(ctx context.Context) (any, error) {
+		return "done", nil
+	}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if v := <-results; v != "DONE" {
+		t.Fatalf("expected the task to run with its middleware, got %v", v)
+	}
+	if c := <-completions; c.Value != "DONE" {
+		t.Fatalf("unexpected completion %+v", c)
+	}
+
+	// SetTask before anyone waits leaves the future lazy
+	later := NewFuture(context.Background(), nil, WithLazy())
+	later.SetTask(func(ctx context.Context) (any, error) {
+		return "later", nil
+	})
+	if later.State() != Pending {
+		t.Fatalf("expected the future to stay lazy, got %v", later.State())
+	}
+	if v, _ := later.Result(); v != "later" {
+		t.Fatalf("expected later, got %v", v)
+	}
+}
+
+func TestFuture_SetTaskErrors(t *testing.T) {
+	task := func(ctx context.Context) (any, error) {
+		return nil, nil
+	}
+	if _, err := NewFuture(context.Background(), nil).Result(); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected a nil eager task to be invalid, got %v", err)
+	}
+	if _, err := NewFutureE(context.Background(), nil); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected a nil eager task to be invalid, got %v", err)
+	}
+
+	f := NewFuture(context.Background(), nil, WithLazy())
+	if err := f.SetTask(nil); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected a nil task to be rejected, got %v", err)
+	}
+	if err := f.SetTask(task); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := f.SetTask(task); !errors.Is(err, ErrAlreadyStarted) {
+		t.Fatalf("expected a second task to be rejected, got %v", err)
+	}
+	if err := NewFuture(context.Background(), task, WithLazy()).SetTask(task); !errors.Is(err, ErrAlreadyStarted) {
+		t.Fatalf("expected a future with a task to reject another, got %v", err)
+	}
+
+	aborted := NewFuture(context.Background(), nil, WithLazy())
+	aborted.Abort()
+	if err := aborted.SetTask(task); !errors.Is(err, ErrAlreadyStarted) {
+		t.Fatalf("expected a settled future to reject a task, got %v", err)
+	}
+}
+
+func TestFuture_ResultCleanupAfterAbort(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	cleaned := make(chan any, 2)
+	f := NewFuture(context.Background(), func(context.Context) (any, error) {
+		close(started)
+		<-release
+		return "conn", nil
+	}, WithResultCleanup(func(v any) {
+		cleaned <- v
+	}))
+	<-started
+	f.Abort()
+	close(release)
+	if err := f.waitExit(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case v := <-cleaned:
+		if v != "conn" {
+			t.Fatalf("unexpected cleaned value: %v", v)
+		}
+	default:
+		t.Fatalf("late result was not cleaned up")
+	}
+	if len(cleaned) != 0 {
+		t.Fatalf("cleanup called more than once")
+	}
+}
+
+func TestFuture_ResultCleanupAfterTimeout(t *testing.T) {
+	cleaned := make(chan any, 1)
+	f := NewFuture(context.Background(), func(context.Context) (any, error) {
+		time.Sleep(20 * time.Millisecond)
+		return "file", nil
+	}, WithTimeout(time.Millisecond), WithResultCleanup(func(v any) {
+		cleaned <- v
+	}))
+	f.Result()
+	f.waitExit(context.Background())
+	if len(cleaned) != 1 {
+		t.Fatalf("timed-out result was not cleaned up")
+	}
+}
+
+func TestFuture_ResultCleanupNotCalledForResult(t *testing.T) {
+	f := NewFuture(context.Background(), func(context.Context) (any, error) {
+		return "kept", nil
+	}, WithResultCleanup(func(v any) {
+		t.Errorf("cleanup called for the observable result %v", v)
+	}))
+	f.Result()
+	f.waitExit(context.Background())
+}
+
+func TestFuture_WithoutAbortAllocs(t *testing.T) {
+	task := func(ctx context.Context) (any, error) {
+		return nil, nil
+	}
+	plain := testing.AllocsPerRun(100, func() {
+		newFuture(context.Background(), task)
+	})
+	noAbort := testing.AllocsPerRun(100, func() {
+		newFuture(context.Background(), task, WithoutAbort())
+	})
+	t.Logf("allocations per future: %v plain, %v WithoutAbort", plain, noAbort)
+	if noAbort >= plain {
+		t.Fatalf("WithoutAbort did not save allocations: %v >= %v", noAbort, plain)
+	}
+}
+
+func TestFuture_WithoutAbort(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-release
+		return nil, ctx.Err()
+	}, WithoutAbort())
+	<-started
+	f.Abort()
+	if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected Abort to settle the future, got %v", err)
+	}
+	if err := f.Context().Err(); err != nil {
+		t.Fatalf("expected task context to stay live, got %v", err)
+	}
+	close(release)
+}
+
+func BenchmarkResolved(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		newSettled(context.Background(), i, nil)
+	}
+}
+
+func BenchmarkResultHotPath(b *testing.B) {
+	f := newSettled(context.Background(), "value", nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f.Result()
+	}
+}
+
+func TestFuture_DoneAnyTime(t *testing.T) {
+	release := make(chan struct{})
+	f := NewFuture(context.Background(), func(context.Context) (any, error) {
+		<-release
+		return nil, nil
+	})
+	before := f.Done()
+	waiters := make(chan struct{})
+	for range 4 {
+		go func() {
+			<-f.Done()
+			waiters <- struct{}{}
+		}()
+	}
+	close(release)
+	<-before
+	for range 4 {
+		<-waiters
+	}
+	after := f.Done()
+	select {
+	case <-after:
+	default:
+		t.Fatalf("Done after settlement returned an open channel")
+	}
+	if !f.Ready() {
+		t.Fatalf("expected future to be ready")
+	}
+}
+
+func TestFuture_ResultWithoutDoneChannel(t *testing.T) {
+	task := func(ctx context.Context) (any, error) {
+		return nil, nil
+	}
+	// A settled future must not allocate a channel for Result or Done.
+	allocs := testing.AllocsPerRun(100, func() {
+		f := newSettled(context.Background(), nil, nil)
+		f.Result()
+		<-f.Done()
+	})
+	settledOnly := testing.AllocsPerRun(100, func() {
+		newSettled(context.Background(), nil, nil)
+	})
+	if allocs != settledOnly {
+		t.Fatalf("Result on a ready future allocated: %v vs %v", allocs, settledOnly)
+	}
+	f := newFuture(context.Background(), task)
+	if f.done != nil {
+		t.Fatalf("done channel allocated eagerly")
+	}
+}
+
+func TestFuture_WithSync(t *testing.T) {
+	ran := false
+	f := NewFuture(context.Background(), func(context.Context) (any, error) {
+		ran = true
+		return "inline", nil
+	}, WithSync())
+	if !ran || !f.Ready() {
+		t.Fatalf("eager sync future did not run inside NewFuture")
+	}
+	select {
+	case <-f.Done():
+	default:
+		t.Fatalf("Done not closed when NewFuture returned")
+	}
+	if v, err := f.Result(); v != "inline" || err != nil {
+		t.Fatalf("unexpected result: %v, %v", v, err)
+	}
+
+	lazy := NewFuture(context.Background(), func(context.Context) (any, error) {
+		panic("boom")
+	}, WithSync(), WithLazy())
+	if lazy.Ready() {
+		t.Fatalf("lazy sync future ran before Result")
+	}
+	if _, err := lazy.Result(); err == nil || !lazy.Panicked() {
+		t.Fatalf("expected recovered panic, got %v", err)
+	}
+
+	aborted := NewFuture(context.Background(), func(context.Context) (any, error) {
+		t.Errorf("task of a future aborted before start ran")
+		return nil, nil
+	}, WithSync(), WithLazy())
+	aborted.Abort()
+	if _, err := aborted.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
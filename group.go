@@ -0,0 +1,504 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// ErrGroupActive is returned when changing the limit of a group that has
+// members which have not settled yet.
+var ErrGroupActive = errors.New("group has active members")
+
+// GroupOption defines functional options for Group.
+type GroupOption func(*Group)
+
+// WithCancelOnError cancels the group context as soon as any member fails,
+// with a cause naming the failing member, and aborts every member that has
+// not settled yet. Without it, siblings of a failed member run to completion.
+func WithCancelOnError() GroupOption {
+	return func(g *Group) {
+		g.cancelOnError = true
+	}
+}
+
+// WithoutResultRetention stops the group from holding on to settled members,
+// so huge groups where only the error matters do not keep every value alive.
+// Results returns nil for such groups.
+func WithoutResultRetention() GroupOption {
+	return func(g *Group) {
+		g.discard = true
+	}
+}
+
+// Result is the Completion of a group member. For groups created with
+// WithoutResultRetention, only Err is set.
+type Result = Completion
+
+// WithContainedErrors keeps a child group's failures from propagating to its
+// parent. It only has an effect on groups created with Child.
+func WithContainedErrors() GroupOption {
+	return func(g *Group) {
+		g.contained = true
+	}
+}
+
+// MemberError identifies the group member that produced an error.
+type MemberError struct {
+	// Index is the position of the member in Go-call order.
+	Index int
+	// Name is the member's name as given to GoNamed, or "" for members
+	// started otherwise. A name reused within the group gets a "#n"
+	// suffix from its second member on, as in "fetch#2".
+	Name string
+	// Err is the error the member settled with.
+	Err error
+}
+
+func (e *MemberError) Error() string {
+	return fmt.Sprintf("group member %s: %v", e.member(), e.Err)
+}
+
+// member identifies the member by name, or by index if it has no name.
+func (e *MemberError) member() string {
+	if e.Name != "" {
+		return e.Name
+	}
+	return strconv.Itoa(e.Index)
+}
+
+func (e *MemberError) Unwrap() error {
+	return e.Err
+}
+
+// Group runs a set of futures under a shared context and waits for all of
+// them, like errgroup, while keeping each member's result accessible through
+// its Future.
+type Group struct {
+	ctx           context.Context
+	cancel        context.CancelCauseFunc
+	cancelOnError bool
+	discard       bool
+	contained     bool
+
+	// member represents a child group in its parent, and memberErr is the
+	// failure it settles with.
+	member    *Future
+	memberErr error
+
+	mu       sync.Mutex
+	futures  []*Future
+	firstErr error
+	errs     []*MemberError
+	active   int
+	sem      chan struct{}
+	waited   bool
+	aborted  bool
+	children map[int]*Group
+	names    map[string]int // how many members used each name
+
+	// settled records members in settlement order; changed is closed and
+	// replaced whenever a member settles.
+	settled []completion
+	changed chan struct{}
+}
+
+// completion is a settled member.
+type completion struct {
+	index int
+	err   error
+}
+
+// NewGroup creates a Group whose members run under a context derived from ctx.
+// The context is cancelled when Wait returns.
+func NewGroup(ctx context.Context, opts ...GroupOption) *Group {
+	ctx = orBackground(ctx)
+	newCtx, cancel := context.WithCancelCause(ctx)
+	g := &Group{
+		ctx:     newCtx,
+		cancel:  cancel,
+		changed: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	stats.groups.Add(1)
+	return g
+}
+
+// Context returns the context shared by the group's members.
+func (g *Group) Context() context.Context {
+	return g.ctx
+}
+
+// SetLimit bounds the number of members that may be outstanding at once.
+// A slot frees when a member's task function returns, so a member that
+// timed out or was aborted but ignores its context keeps its slot until it
+// actually stops; a member that settles before starting frees it at once.
+// Zero or a negative n removes the limit. It fails with ErrGroupActive if
+// any member has not settled yet.
+func (g *Group) SetLimit(n int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.active > 0 {
+		return ErrGroupActive
+	}
+	if n <= 0 {
+		g.sem = nil
+		return nil
+	}
+	g.sem = make(chan struct{}, n)
+	return nil
+}
+
+// Go starts the task as a member of the group and returns its Future. If
+// the group has a limit, Go blocks until a slot frees; if the group context
+// is done first, the returned future fails with its cause and is not a
+// member.
+func (g *Group) Go(task func(context.Context) (any, error), opts ...Option) *Future {
+	g.mu.Lock()
+	sem := g.sem
+	g.mu.Unlock()
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+		case <-g.ctx.Done():
+			return newSettled(g.ctx, nil, context.Cause(g.ctx))
+		}
+	}
+	return g.add(sem, task, opts...)
+}
+
+// GoNamed starts the task like Go, as a member named name. Errors from the
+// group identify the member by name rather than index; a name used more
+// than once gets a "#n" suffix from its second member on. The name appears
+// in Results, Completed, and Pending through the future's name. The
+// member's own errors are not prefixed with the name, since the group
+// attributes them.
+func (g *Group) GoNamed(name string, task func(context.Context) (any, error), opts ...Option) *Future {
+	return g.Go(task, append(opts, WithName(name), WithoutErrorWrapping(), asMember(name))...)
+}
+
+// asMember names f for error attribution within its group.
+func asMember(name string) Option {
+	return func(f *Future) {
+		f.member = name
+	}
+}
+
+// TryGo starts the task like Go, but returns false instead of blocking when
+// no slot is available.
+func (g *Group) TryGo(task func(context.Context) (any, error), opts ...Option) (*Future, bool) {
+	g.mu.Lock()
+	sem := g.sem
+	g.mu.Unlock()
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+		default:
+			return nil, false
+		}
+	}
+	return g.add(sem, task, opts...), true
+}
+
+// Child creates a group nested under g. The child runs under a context
+// derived from g's, it counts as one member of g, and g's Wait also waits
+// for the child's members. Its member does not take a slot of g's limit,
+// and it settles once every member added to the child so far has settled,
+// or when the child's Wait returns.
+//
+// Cancellation flows down: aborting g, or g cancelling on error, aborts
+// every descendant future. Failures flow up: a child's first failure settles
+// its member in g as a *MemberError, unless the child was created with
+// WithContainedErrors. Aborting the child itself is not a failure and does
+// not propagate.
+func (g *Group) Child(opts ...GroupOption) *Group {
+	child := NewGroup(g.ctx, opts...)
+	child.member = newFuture(g.ctx, nil)
+	child.member.once.Do(func() {})
+	index := g.register(child.member, nil)
+	g.mu.Lock()
+	if g.children == nil {
+		g.children = make(map[int]*Group)
+	}
+	g.children[index] = child
+	g.mu.Unlock()
+	return child
+}
+
+// Abort cancels the group context and aborts every member and descendant
+// group that has not settled yet.
+func (g *Group) Abort() {
+	g.mu.Lock()
+	g.aborted = true
+	g.mu.Unlock()
+	g.cancel(context.Canceled)
+	g.abortMembers()
+}
+
+// abortMembers aborts every member and child group.
+func (g *Group) abortMembers() {
+	g.mu.Lock()
+	members := slices.Clone(g.futures)
+	children := make([]*Group, 0, len(g.children))
+	for _, child := range g.children {
+		children = append(children, child)
+	}
+	g.mu.Unlock()
+	for _, m := range members {
+		if m != nil {
+			m.Abort()
+		}
+	}
+	for _, child := range children {
+		child.Abort()
+	}
+}
+
+// add registers and starts a member that holds a slot of sem, if any.
+func (g *Group) add(sem chan struct{}, task func(context.Context) (any, error), opts ...Option) *Future {
+	f := newFuture(g.ctx, task, opts...)
+	g.register(f, sem)
+	if !f.rejectInvalid() && !f.lazy {
+		f.once.Do(f.start)
+	}
+	return f
+}
+
+// register adds f as a member that holds a slot of sem, if any, and
+// returns its index.
+func (g *Group) register(f *Future, sem chan struct{}) int {
+	g.mu.Lock()
+	index := len(g.futures)
+	g.futures = append(g.futures, f)
+	g.active++
+	name := g.label(f.member)
+	g.mu.Unlock()
+	f.whenDone(func() {
+		_, err := f.peek()
+		g.mu.Lock()
+		g.active--
+		idle := g.active == 0
+		if g.discard {
+			g.futures[index] = nil
+		}
+		g.settled = append(g.settled, completion{index: index, err: err})
+		close(g.changed)
+		g.changed = make(chan struct{})
+		g.mu.Unlock()
+		g.record(index, name, f)
+		if idle {
+			// The child settles in its parent once its members have, so the
+			// parent's Completed and SetLimit need not wait for Wait.
+			g.settleMember()
+		}
+	})
+	if sem != nil {
+		f.whenReleased(func() {
+			<-sem
+		})
+	}
+	return index
+}
+
+// label returns the name a member named name goes by in errors, counting
+// the name's use. It must be called with g.mu held.
+func (g *Group) label(name string) string {
+	if name == "" {
+		return ""
+	}
+	if g.names == nil {
+		g.names = make(map[string]int)
+	}
+	g.names[name]++
+	if n := g.names[name]; n > 1 {
+		return name + "#" + strconv.Itoa(n)
+	}
+	return name
+}
+
+// Pending returns a snapshot of the members that have not settled, oldest
+// first. Members of child groups are included, but not the members that
+// represent the child groups themselves.
+func (g *Group) Pending() []FutureInfo {
+	return pendingInfo(g.unsettled(nil))
+}
+
+// unsettled appends the members of g and its descendants that may not have
+// settled to futures.
+func (g *Group) unsettled(futures []*Future) []*Future {
+	g.mu.Lock()
+	members := slices.Clone(g.futures)
+	children := maps.Clone(g.children)
+	g.mu.Unlock()
+	for i, f := range members {
+		if child := children[i]; child != nil {
+			futures = child.unsettled(futures)
+			continue
+		}
+		if f != nil && !f.Ready() {
+			futures = append(futures, f)
+		}
+	}
+	return futures
+}
+
+// Wait blocks until every member has settled, starting lazy members, and
+// returns the first error to occur, as a *MemberError naming the member.
+func (g *Group) Wait() error {
+	g.wait()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.firstErr
+}
+
+// WaitAll blocks like Wait but returns every failure, joined with
+// errors.Join in Go-call order. Each joined error is a *MemberError naming
+// the member that produced it. It returns nil if no member failed.
+func (g *Group) WaitAll() error {
+	g.wait()
+	g.mu.Lock()
+	memberErrs := slices.Clone(g.errs)
+	g.mu.Unlock()
+	slices.SortFunc(memberErrs, func(a, b *MemberError) int {
+		return a.Index - b.Index
+	})
+	errs := make([]error, len(memberErrs))
+	for i, err := range memberErrs {
+		errs[i] = err
+	}
+	return errors.Join(errs...)
+}
+
+// Results returns the Completion of every member in Go-call order. It
+// returns nil until Wait or WaitAll has returned, and always for groups
+// created with WithoutResultRetention.
+func (g *Group) Results() []Result {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.waited || g.discard {
+		return nil
+	}
+	results := make([]Result, len(g.futures))
+	for i, f := range g.futures {
+		results[i] = f.completion()
+	}
+	return results
+}
+
+// Completed returns an iterator over members in the order they settle,
+// yielding each member's Go-call index and result. Lazy members are started
+// when iteration begins. Iteration ends once every member has settled, or
+// when the group context is done and no more settled members are pending.
+// For groups created with WithoutResultRetention, only the error is yielded.
+func (g *Group) Completed() iter.Seq2[int, Result] {
+	return func(yield func(int, Result) bool) {
+		g.mu.Lock()
+		members := slices.Clone(g.futures)
+		g.mu.Unlock()
+		for _, f := range members {
+			if f != nil {
+				f.once.Do(f.start)
+			}
+		}
+
+		for pos := 0; ; pos++ {
+			g.mu.Lock()
+			for pos == len(g.settled) {
+				if g.active == 0 || g.ctx.Err() != nil {
+					g.mu.Unlock()
+					return
+				}
+				changed := g.changed
+				g.mu.Unlock()
+				select {
+				case <-changed:
+				case <-g.ctx.Done():
+				}
+				g.mu.Lock()
+			}
+			c := g.settled[pos]
+			f := g.futures[c.index]
+			g.mu.Unlock()
+
+			result := Result{Err: c.err}
+			if f != nil {
+				result = f.completion()
+			}
+			if !yield(c.index, result) {
+				return
+			}
+		}
+	}
+}
+
+// wait blocks until every member, including ones added while waiting, and
+// every descendant has settled, then cancels the group context.
+func (g *Group) wait() {
+	for i := 0; ; i++ {
+		g.mu.Lock()
+		if i == len(g.futures) {
+			g.waited = true
+			g.mu.Unlock()
+			g.cancel(context.Canceled)
+			g.settleMember()
+			return
+		}
+		f := g.futures[i]
+		child := g.children[i]
+		g.mu.Unlock()
+		if child != nil {
+			child.wait()
+		}
+		if f != nil {
+			f.Result()
+		}
+	}
+}
+
+// record notes a settled member's error, cancelling the group on the first
+// failure if WithCancelOnError is set.
+func (g *Group) record(index int, name string, f *Future) {
+	_, err := f.peek()
+	if err == nil {
+		return
+	}
+	g.mu.Lock()
+	memberErr := &MemberError{Index: index, Name: name, Err: err}
+	first := g.firstErr == nil
+	if first {
+		g.firstErr = memberErr
+	}
+	g.errs = append(g.errs, memberErr)
+	propagate := first && g.member != nil && !g.contained && !g.aborted
+	if propagate {
+		g.memberErr = memberErr
+	}
+	g.mu.Unlock()
+
+	if first && g.cancelOnError {
+		g.cancel(fmt.Errorf("group member %s failed: %w", memberErr.member(), err))
+		g.abortMembers()
+	}
+	if propagate {
+		g.settleMember()
+	}
+}
+
+// settleMember settles a child group's member in its parent with the
+// child's propagated failure, if any.
+func (g *Group) settleMember() {
+	if g.member == nil {
+		return
+	}
+	g.mu.Lock()
+	err := g.memberErr
+	g.mu.Unlock()
+	g.member.settle(nil, err)
+}
@@ -0,0 +1,774 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGroup_Wait(t *testing.T) {
+	g := NewGroup(context.Background())
+
+	var futures []*Future
+	for i := 0; i < 3; i++ {
+		i := i
+		futures = append(futures, g.Go(func(ctx context.Context) (any, error) {
+			return i * 10, nil
+		}))
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Individual results remain accessible
+	for i, f := range futures {
+		if !f.Ready() {
+			t.Fatalf("expected member %d to be settled after Wait", i)
+		}
+		if result, _ := f.Result(); result != i*10 {
+			t.Fatalf("expected result %d, got %v", i*10, result)
+		}
+	}
+}
+
+func TestGroup_FirstError(t *testing.T) {
+	g := NewGroup(context.Background())
+
+	errFirst := errors.New("first")
+	errSecond := errors.New("second")
+	g.Go(func(ctx context.Context) (any, error) {
+		return nil, errFirst
+	})
+	g.Go(func(ctx context.Context) (any, error) {
+		time.Sleep(50 * time.Millisecond)
+		return nil, errSecond
+	})
+	ok := g.Go(func(ctx context.Context) (any, error) {
+		return "ok", nil
+	})
+
+	// Wait returns the first error to occur, not the last
+	if err := g.Wait(); !errors.Is(err, errFirst) {
+		t.Fatalf("expected first error, got %v", err)
+	}
+	if result, _ := ok.Result(); result != "ok" {
+		t.Fatalf("expected result 'ok', got %v", result)
+	}
+}
+
+func TestGroup_Context(t *testing.T) {
+	g := NewGroup(context.Background())
+
+	seen := make(chan context.Context, 1)
+	lazy := g.Go(func(ctx context.Context) (any, error) {
+		seen <- ctx
+		return nil, nil
+	}, WithLazy())
+
+	// Wait starts lazy members
+	if err := g.Wait(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !lazy.Ready() {
+		t.Fatal("expected lazy member to be settled after Wait")
+	}
+
+	// Members run under the group context, which Wait cancels
+	ctx := <-seen
+	if ctx.Err() == nil {
+		t.Fatal("expected member context to be cancelled after Wait")
+	}
+	if g.Context().Err() == nil {
+		t.Fatal("expected group context to be cancelled after Wait")
+	}
+}
+
+func TestGroup_CancelOnError(t *testing.T) {
+	g := NewGroup(context.Background(), WithCancelOnError())
+
+	errFailed := errors.New("failed")
+	sibling := g.Go(func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	lazy := g.Go(func(ctx context.Context) (any, error) {
+		t.Error("lazy member should be aborted before it starts")
+		return nil, nil
+	}, WithLazy())
+	g.Go(func(ctx context.Context) (any, error) {
+		return nil, errFailed
+	})
+
+	// Wait reports the failing member's error, not the induced cancellation
+	if err := g.Wait(); !errors.Is(err, errFailed) {
+		t.Fatalf("expected failing member's error, got %v", err)
+	}
+	if _, err := sibling.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected sibling to be cancelled, got %v", err)
+	}
+	if _, err := lazy.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected lazy member to be aborted, got %v", err)
+	}
+
+	// The cause names the failing member
+	cause := context.Cause(g.Context())
+	if !errors.Is(cause, errFailed) || cause.Error() != "group member 2 failed: failed" {
+		t.Fatalf("expected cause naming member 2, got %v", cause)
+	}
+}
+
+func TestGroup_NoCancelOnError(t *testing.T) {
+	g := NewGroup(context.Background())
+
+	errFailed := errors.New("failed")
+	g.Go(func(ctx context.Context) (any, error) {
+		return nil, errFailed
+	})
+	sibling := g.Go(func(ctx context.Context) (any, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+			return "completed", nil
+		}
+	})
+
+	// Without the option, siblings run to completion
+	if err := g.Wait(); !errors.Is(err, errFailed) {
+		t.Fatalf("expected failing member's error, got %v", err)
+	}
+	if result, err := sibling.Result(); err != nil || result != "completed" {
+		t.Fatalf("expected sibling to complete, got %v, %v", result, err)
+	}
+}
+
+func TestGroup_WaitAll(t *testing.T) {
+	g := NewGroup(context.Background())
+
+	errFirst := errors.New("first")
+	errThird := errors.New("third")
+	g.Go(func(ctx context.Context) (any, error) {
+		return nil, errFirst
+	})
+	g.Go(func(ctx context.Context) (any, error) {
+		return "ok", nil
+	})
+	g.Go(func(ctx context.Context) (any, error) {
+		return nil, errThird
+	})
+
+	// Every failure is reported, attributed to its member
+	err := g.WaitAll()
+	if !errors.Is(err, errFirst) || !errors.Is(err, errThird) {
+		t.Fatalf("expected both failures, got %v", err)
+	}
+	var member *MemberError
+	if !errors.As(err, &member) || member.Index != 0 {
+		t.Fatalf("expected a MemberError for member 0, got %v", err)
+	}
+	expected := "group member 0: first\ngroup member 2: third"
+	if err.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestGroup_WaitAllStructure(t *testing.T) {
+	g := NewGroup(context.Background())
+	errFailed := errors.New("failed")
+	g.Go(func(ctx context.Context) (any, error) {
+		return "ok", nil
+	})
+	g.Go(func(ctx context.Context) (any, error) {
+		panic("boom")
+	})
+	g.Go(func(ctx context.Context) (any, error) {
+		return nil, errFailed
+	})
+
+	// The joined error has one *MemberError per failure, in Go-call order
+	joined, ok := g.WaitAll().(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("expected an errors.Join error")
+	}
+	errs := joined.Unwrap()
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 joined errors, got %d", len(errs))
+	}
+	for i, want := range []int{1, 2} {
+		member, ok := errs[i].(*MemberError)
+		if !ok || member.Index != want {
+			t.Fatalf("expected member %d at %d, got %v", want, i, errs[i])
+		}
+	}
+	var panicErr *PanicError
+	if !errors.As(errs[0], &panicErr) || panicErr.Value != "boom" {
+		t.Fatalf("expected a PanicError inside member 1, got %v", errs[0])
+	}
+	if !errors.Is(errs[1], errFailed) {
+		t.Fatalf("expected member 2 to wrap its failure, got %v", errs[1])
+	}
+	if !errors.As(g.WaitAll(), &panicErr) {
+		t.Fatalf("expected the PanicError to be reachable through the join")
+	}
+}
+
+func TestGroup_WaitAllSuccess(t *testing.T) {
+	g := NewGroup(context.Background())
+	g.Go(func(ctx context.Context) (any, error) {
+		return "ok", nil
+	})
+	if err := g.WaitAll(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestGroup_SetLimit(t *testing.T) {
+	g := NewGroup(context.Background())
+	if err := g.SetLimit(3); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	for i := 0; i < 50; i++ {
+		g.Go(func(ctx context.Context) (any, error) {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil, nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if peak > 3 {
+		t.Fatalf("expected at most 3 members running at once, got %d", peak)
+	}
+}
+
+func TestGroup_SetLimitStraggler(t *testing.T) {
+	g := NewGroup(context.Background())
+	g.SetLimit(1)
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	track := func(delta int) {
+		mu.Lock()
+		running += delta
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+	}
+	release := make(chan struct{})
+	straggler := g.Go(func(ctx context.Context) (any, error) {
+		track(1)
+		defer track(-1)
+		<-release // ignores its context
+		return nil, nil
+	}, WithTimeout(10*time.Millisecond))
+	if _, err := straggler.Result(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// The timed-out member keeps its slot until its task returns
+	next := make(chan *Future)
+	go func() {
+		next <- g.Go(func(ctx context.Context) (any, error) {
+			track(1)
+			defer track(-1)
+			return nil, nil
+		})
+	}()
+	select {
+	case <-next:
+		t.Fatal("expected Go to block while the straggler is still running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	(<-next).Result()
+	g.Wait()
+	if peak > 1 {
+		t.Fatalf("expected at most 1 member running at once, got %d", peak)
+	}
+}
+
+func TestGroup_SetLimitActive(t *testing.T) {
+	g := NewGroup(context.Background())
+
+	release := make(chan struct{})
+	g.Go(func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	})
+
+	// The limit cannot change while members are outstanding
+	if err := g.SetLimit(1); !errors.Is(err, ErrGroupActive) {
+		t.Fatalf("expected ErrGroupActive, got %v", err)
+	}
+	close(release)
+	g.Wait()
+}
+
+func TestGroup_TryGo(t *testing.T) {
+	g := NewGroup(context.Background())
+	g.SetLimit(1)
+
+	release := make(chan struct{})
+	if _, ok := g.TryGo(func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	}); !ok {
+		t.Fatal("expected TryGo to start the first member")
+	}
+
+	// No slot is available while the first member runs
+	if _, ok := g.TryGo(func(ctx context.Context) (any, error) {
+		t.Error("member should not start without a slot")
+		return nil, nil
+	}); ok {
+		t.Fatal("expected TryGo to fail without a free slot")
+	}
+
+	close(release)
+	g.Wait()
+}
+
+func TestGroup_GoBlockedCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := NewGroup(ctx)
+	g.SetLimit(1)
+
+	release := make(chan struct{})
+	defer close(release)
+	g.Go(func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	})
+
+	// A blocked Go gives up when the group context is done
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	future := g.Go(func(ctx context.Context) (any, error) {
+		t.Error("member should not start after the group context is done")
+		return nil, nil
+	})
+	if _, err := future.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestGroup_Results(t *testing.T) {
+	g := NewGroup(context.Background())
+
+	errFailed := errors.New("failed")
+	g.Go(func(ctx context.Context) (any, error) {
+		time.Sleep(20 * time.Millisecond)
+		return "slow", nil
+	})
+	g.Go(func(ctx context.Context) (any, error) {
+		return nil, errFailed
+	})
+	g.Go(func(ctx context.Context) (any, error) {
+		return "fast", nil
+	})
+
+	// Results are unavailable until Wait returns
+	if results := g.Results(); results != nil {
+		t.Fatalf("expected no results before Wait, got %v", results)
+	}
+	g.Wait()
+
+	// Results come back in Go-call order, not completion order
+	results := g.Results()
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[0].Value != "slow" || results[0].Err != nil {
+		t.Fatalf("expected 'slow', got %+v", results[0])
+	}
+	if results[1].Value != nil || !errors.Is(results[1].Err, errFailed) {
+		t.Fatalf("expected failure, got %+v", results[1])
+	}
+	if results[2].Value != "fast" || results[2].Err != nil {
+		t.Fatalf("expected 'fast', got %+v", results[2])
+	}
+	if !results[0].Succeeded() || results[1].Outcome != Failed || results[2].Meta.Attempts != 1 {
+		t.Fatalf("expected results to carry how members settled, got %+v", results)
+	}
+}
+
+func TestGroup_WithoutResultRetention(t *testing.T) {
+	g := NewGroup(context.Background(), WithoutResultRetention())
+
+	errFailed := errors.New("failed")
+	for i := 0; i < 100; i++ {
+		i := i
+		g.Go(func(ctx context.Context) (any, error) {
+			if i == 42 {
+				return nil, errFailed
+			}
+			return make([]byte, 1024), nil
+		})
+	}
+
+	// Errors are still reported, but values are not retained
+	err := g.WaitAll()
+	var member *MemberError
+	if !errors.As(err, &member) || member.Index != 42 {
+		t.Fatalf("expected a MemberError for member 42, got %v", err)
+	}
+	if results := g.Results(); results != nil {
+		t.Fatalf("expected no retained results, got %d", len(results))
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, f := range g.futures {
+		if f != nil {
+			t.Fatalf("expected member %d to be released", i)
+		}
+	}
+}
+
+func TestGroup_Completed(t *testing.T) {
+	g := NewGroup(context.Background())
+
+	delays := []time.Duration{60 * time.Millisecond, 0, 30 * time.Millisecond}
+	for _, delay := range delays {
+		delay := delay
+		g.Go(func(ctx context.Context) (any, error) {
+			time.Sleep(delay)
+			return delay, nil
+		})
+	}
+
+	// Members are yielded in completion order
+	var order []int
+	for i, result := range g.Completed() {
+		if result.Err != nil {
+			t.Fatalf("expected no error, got %v", result.Err)
+		}
+		if result.Value != delays[i] {
+			t.Fatalf("expected value %v for member %d, got %v", delays[i], i, result.Value)
+		}
+		order = append(order, i)
+	}
+	expected := []int{1, 2, 0}
+	if len(order) != len(expected) {
+		t.Fatalf("expected order %v, got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected order %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestGroup_CompletedBreak(t *testing.T) {
+	g := NewGroup(context.Background())
+
+	release := make(chan struct{})
+	g.Go(func(ctx context.Context) (any, error) {
+		return "first", nil
+	})
+	g.Go(func(ctx context.Context) (any, error) {
+		<-release
+		return "second", nil
+	})
+
+	// Breaking early returns without waiting for the rest
+	for _, result := range g.Completed() {
+		if result.Value != "first" {
+			t.Fatalf("expected 'first', got %v", result.Value)
+		}
+		break
+	}
+	close(release)
+	g.Wait()
+}
+
+func TestGroup_CompletedContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := NewGroup(ctx)
+
+	release := make(chan struct{})
+	defer close(release)
+	g.Go(func(ctx context.Context) (any, error) {
+		// Ignore cancellation
+		<-release
+		return nil, nil
+	})
+
+	// Iteration ends when the group context dies
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	done := make(chan struct{})
+	go func() {
+		for range g.Completed() {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected iteration to end when the group context is done")
+	}
+}
+
+func TestGroup_ChildTree(t *testing.T) {
+	errLeaf := errors.New("leaf failed")
+	errMid := errors.New("mid failed")
+	blocked := func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	root := NewGroup(context.Background(), WithCancelOnError())
+	rootSibling := root.Go(blocked)
+	mid := root.Child(WithCancelOnError())
+	midSibling := mid.Go(blocked)
+	leaf := mid.Child(WithCancelOnError(), WithContainedErrors())
+	leafSibling := leaf.Go(blocked)
+	leafLazy := leaf.Go(func(ctx context.Context) (any, error) {
+		t.Error("lazy leaf member should be aborted before it starts")
+		return nil, nil
+	}, WithLazy())
+
+	// A contained failure at the leaf cancels only the leaf
+	leaf.Go(func(ctx context.Context) (any, error) {
+		return nil, errLeaf
+	})
+	if _, err := leafSibling.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected leaf sibling to be cancelled, got %v", err)
+	}
+	if _, err := leafLazy.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected lazy leaf member to be aborted, got %v", err)
+	}
+	if mid.Context().Err() != nil || root.Context().Err() != nil {
+		t.Fatal("expected a contained failure not to cancel its ancestors")
+	}
+
+	// An uncontained failure at the middle level propagates to the root
+	mid.Go(func(ctx context.Context) (any, error) {
+		return nil, errMid
+	})
+	if _, err := midSibling.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected mid sibling to be cancelled, got %v", err)
+	}
+	if _, err := rootSibling.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected root sibling to be cancelled, got %v", err)
+	}
+
+	// The root reports the middle failure, attributed through the tree
+	err := root.Wait()
+	if !errors.Is(err, errMid) || errors.Is(err, errLeaf) {
+		t.Fatalf("expected the middle failure only, got %v", err)
+	}
+	if !errors.Is(context.Cause(root.Context()), errMid) {
+		t.Fatalf("expected root cause to be the middle failure, got %v", context.Cause(root.Context()))
+	}
+	if err := leaf.Wait(); !errors.Is(err, errLeaf) {
+		t.Fatalf("expected the leaf to keep its own failure, got %v", err)
+	}
+}
+
+func TestGroup_ChildAbort(t *testing.T) {
+	root := NewGroup(context.Background())
+	mid := root.Child()
+	leaf := mid.Child()
+
+	started := make(chan struct{})
+	running := leaf.Go(func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	lazy := leaf.Go(func(ctx context.Context) (any, error) {
+		t.Error("lazy member should be aborted before it starts")
+		return nil, nil
+	}, WithLazy())
+	<-started
+
+	// Aborting the root aborts every descendant future
+	root.Abort()
+	for _, f := range []*Future{running, lazy} {
+		select {
+		case <-f.Done():
+		case <-time.After(time.Second):
+			t.Fatal("expected descendant to be aborted")
+		}
+		if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	}
+	root.Wait()
+}
+
+func TestGroup_ChildAbortDoesNotPropagate(t *testing.T) {
+	root := NewGroup(context.Background(), WithCancelOnError())
+	sibling := root.Go(func(ctx context.Context) (any, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(20 * time.Millisecond):
+			return "ok", nil
+		}
+	})
+	child := root.Child()
+	child.Go(func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	// Aborting a child is deliberate, not a failure of the parent
+	child.Abort()
+	if err := root.Wait(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result, _ := sibling.Result(); result != "ok" {
+		t.Fatalf("expected sibling to complete, got %v", result)
+	}
+}
+
+func TestGroup_ChildSettlesWithMembers(t *testing.T) {
+	root := NewGroup(context.Background())
+	root.Go(func(ctx context.Context) (any, error) {
+		return "sibling", nil
+	})
+	child := root.Child()
+	child.Go(func(ctx context.Context) (any, error) {
+		return "nested", nil
+	})
+
+	// Iterating the parent ends without anyone calling the child's Wait
+	done := make(chan int)
+	go func() {
+		n := 0
+		for range root.Completed() {
+			n++
+		}
+		done <- n
+	}()
+	select {
+	case n := <-done:
+		if n != 2 {
+			t.Fatalf("expected 2 settled members, got %d", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected iteration to end once the child's members settled")
+	}
+
+	// The parent is no longer active, so its limit can change
+	if err := root.SetLimit(1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := root.Wait(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestGroup_Pending(t *testing.T) {
+	g := NewGroup(context.Background())
+
+	release := make(chan struct{})
+	g.Go(func(ctx context.Context) (any, error) {
+		return nil, nil
+	}).Result()
+	g.Go(func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	}, WithName("outer"))
+	g.Child().Go(func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	}, WithName("inner"))
+
+	// Members of child groups are listed, settled members are not
+	infos := g.Pending()
+	if len(infos) != 2 || infos[0].Name != "outer" || infos[1].Name != "inner" {
+		t.Fatalf("expected the outer and inner members, got %+v", infos)
+	}
+	close(release)
+	g.Wait()
+	if infos := g.Pending(); len(infos) != 0 {
+		t.Fatalf("expected no pending members, got %+v", infos)
+	}
+}
+
+func TestGroup_GoNamed(t *testing.T) {
+	g := NewGroup(context.Background())
+
+	errDown := errors.New("down")
+	fail := func(ctx context.Context) (any, error) {
+		return nil, errDown
+	}
+	release := make(chan struct{})
+	g.GoNamed("users", fail)
+	g.Go(fail)
+	g.GoNamed("users", fail)
+	g.GoNamed("orders", func(ctx context.Context) (any, error) {
+		<-release
+		return "ok", nil
+	})
+
+	// Pending members are listed by name
+	for len(g.Pending()) > 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if infos := g.Pending(); len(infos) != 1 || infos[0].Name != "orders" {
+		t.Fatalf("expected orders to be pending, got %+v", infos)
+	}
+	close(release)
+
+	// A reused name is disambiguated, unnamed members keep their index
+	err := g.WaitAll()
+	want := "group member users: down\ngroup member 1: down\ngroup member users#2: down"
+	if err == nil || err.Error() != want {
+		t.Fatalf("expected %q, got %v", want, err)
+	}
+	var memberErr *MemberError
+	if !errors.As(err, &memberErr) || memberErr.Name != "users" || memberErr.Index != 0 {
+		t.Fatalf("expected a MemberError for member users, got %#v", memberErr)
+	}
+
+	names := []string{"users", "", "users", "orders"}
+	for i, result := range g.Results() {
+		if result.Meta.Name != names[i] {
+			t.Fatalf("expected result %d to be named %q, got %q", i, names[i], result.Meta.Name)
+		}
+	}
+	for i, result := range g.Completed() {
+		if result.Meta.Name != names[i] {
+			t.Fatalf("expected completion %d to be named %q, got %q", i, names[i], result.Meta.Name)
+		}
+	}
+}
+
+func TestGroup_GoNamedCancelCause(t *testing.T) {
+	g := NewGroup(context.Background(), WithCancelOnError())
+
+	errDown := errors.New("down")
+	g.GoNamed("users", func(ctx context.Context) (any, error) {
+		return nil, errDown
+	})
+	if err := g.Wait(); err == nil || err.Error() != "group member users: down" {
+		t.Fatalf("expected the named member error, got %v", err)
+	}
+	cause := context.Cause(g.Context())
+	if cause == nil || cause.Error() != "group member users failed: down" || !errors.Is(cause, errDown) {
+		t.Fatalf("expected the cause to name the member, got %v", cause)
+	}
+}
@@ -0,0 +1,116 @@
+package A
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// WithHedge starts a second, concurrent call of the task if an attempt has
+// not returned after delay, for tail latency. The first call to succeed
+// settles the future, and the other is cancelled and its value discarded.
+// If both fail, the attempt fails with the first call's error; a panicking
+// hedge counts as a failed one. Hedges are not attempts: with WithRetry,
+// each attempt may be hedged once, and ResultMeta counts hedges launched
+// separately from attempts. WithTimeout bounds hedges like attempts.
+func WithHedge(delay time.Duration) Option {
+	return func(f *Future) {
+		if delay <= 0 {
+			f.invalidOption("WithHedge", "non-positive delay %v", delay)
+			return
+		}
+		f.hedgeDelay = delay
+	}
+}
+
+// hedge is the state shared by an attempt's first call and its hedge.
+type hedge struct {
+	mu          sync.Mutex
+	primaryDone bool // the first call has returned
+	launched    bool
+	won         bool // the hedge settled the future
+	claimed     bool // the first call failed and waits for the hedge
+
+	finished chan struct{} // closed once the hedge has returned
+	value    any
+	err      error
+}
+
+// hedged calls call, hedging it as WithHedge asks.
+func (f *Future) hedged(ctx context.Context, call func(context.Context) (any, error)) (any, error) {
+	if f.hedgeDelay <= 0 {
+		return call(ctx)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	h := &hedge{finished: make(chan struct{})}
+	timer := f.afterFunc(f.hedgeDelay, func() {
+		// The run goroutine drops f.task once it exits, which a hedge may
+		// outlive, so the hedge gets its own copy.
+		f.mu.Lock()
+		task := f.task
+		f.mu.Unlock()
+		h.mu.Lock()
+		if h.primaryDone || ctx.Err() != nil || task == nil {
+			h.mu.Unlock()
+			return
+		}
+		h.launched = true
+		h.mu.Unlock()
+		f.mu.Lock()
+		f.hedges++
+		f.mu.Unlock()
+		go f.runHedge(ctx, h, task)
+	})
+	defer timer.Stop()
+
+	res, err := call(ctx)
+	h.mu.Lock()
+	h.primaryDone = true
+	h.claimed = err != nil && h.launched && !h.won
+	claimed := h.claimed
+	h.mu.Unlock()
+	if !claimed {
+		return res, err
+	}
+	<-h.finished
+	if h.err == nil {
+		f.discard(res, nil)
+		return h.value, nil
+	}
+	return res, err
+}
+
+// runHedge makes the hedge call. If it succeeds while the first call is
+// still running, it settles the future itself.
+func (f *Future) runHedge(ctx context.Context, h *hedge, task Task) {
+	value, err := f.callRecovered(ctx, task)
+	h.mu.Lock()
+	h.value, h.err = value, err
+	h.won = err == nil && !h.primaryDone
+	won, unwanted := h.won, h.primaryDone && !h.claimed
+	h.mu.Unlock()
+	close(h.finished)
+	switch {
+	case won:
+		if !f.settle(value, nil) {
+			f.discard(value, nil)
+		}
+	case unwanted:
+		f.discard(value, err)
+	}
+}
+
+// callRecovered calls task, turning a panic into a *PanicError.
+func (f *Future) callRecovered(ctx context.Context, task Task) (res any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if f.onPanic != nil {
+				f.onPanic(r, debug.Stack())
+			}
+			res, err = nil, &PanicError{Value: r}
+		}
+	}()
+	return f.callTask(ctx, task)
+}
@@ -0,0 +1,221 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+// sequenced returns a task whose nth call runs calls[n], and the number of
+// calls made so far.
+func sequenced(calls ...func(ctx context.Context) (any, error)) (func(ctx context.Context) (any, error), *atomic.Int32) {
+	var n atomic.Int32
+	return func(ctx context.Context) (any, error) {
+		return calls[n.Add(1)-1](ctx)
+	}, &n
+}
+
+// waitCalls waits until a sequenced task has been called n times.
+func waitCalls(calls *atomic.Int32, n int32) {
+	for calls.Load() < n {
+		runtime.Gosched()
+	}
+}
+
+func stuckCall(ctx context.Context) (any, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestWithHedge(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	task, calls := sequenced(stuckCall, func(context.Context) (any, error) {
+		return "hedge", nil
+	})
+	f := NewFuture(context.Background(), task, WithClock(clock), WithHedge(time.Second))
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+
+	// The hedge settles the future while the first call is still running
+	v, err, meta := f.ResultMeta()
+	if v != "hedge" || err != nil {
+		t.Fatalf("expected the hedge to win, got %v, %v", v, err)
+	}
+	if meta.Attempts != 1 || meta.Hedges != 1 || calls.Load() != 2 {
+		t.Fatalf("expected 1 attempt and 1 hedge, got %+v", meta)
+	}
+
+	// A call that returns in time is never hedged
+	fast := NewFuture(context.Background(), func(context.Context) (any, error) {
+		return "fast", nil
+	}, WithClock(clock), WithHedge(time.Second))
+	if _, _, meta := fast.ResultMeta(); meta.Hedges != 0 {
+		t.Fatalf("expected no hedge, got %+v", meta)
+	}
+}
+
+func TestWithHedge_Failures(t *testing.T) {
+	errFirst := errors.New("first failed")
+	errHedge := errors.New("hedge failed")
+	tests := map[string]struct {
+		hedge func(release chan struct{}) func(context.Context) (any, error)
+		value any
+		err   error
+	}{
+		"hedge succeeds after the first call fails": {
+			hedge: func(release chan struct{}) func(context.Context) (any, error) {
+				return func(context.Context) (any, error) {
+					<-release
+					return "hedge", nil
+				}
+			},
+			value: "hedge",
+		},
+		"both fail": {
+			hedge: func(chan struct{}) func(context.Context) (any, error) {
+				return func(context.Context) (any, error) {
+					return nil, errHedge
+				}
+			},
+			err: errFirst,
+		},
+		"hedge panics": {
+			hedge: func(chan struct{}) func(context.Context) (any, error) {
+				return func(context.Context) (any, error) {
+					panic("boom")
+				}
+			},
+			err: errFirst,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			clock := fakeclock.New(time.Now())
+			firstRelease, hedgeRelease := make(chan struct{}), make(chan struct{})
+			task, calls := sequenced(func(context.Context) (any, error) {
+				<-firstRelease
+				return nil, errFirst
+			}, test.hedge(hedgeRelease))
+			f := NewFuture(context.Background(), task, WithClock(clock), WithHedge(time.Second))
+			clock.BlockUntil(1)
+			clock.Advance(time.Second)
+			waitCalls(calls, 2)
+			close(firstRelease)
+			close(hedgeRelease)
+
+			v, err, meta := f.ResultMeta()
+			if v != test.value || !errors.Is(err, test.err) || (err == nil) != (test.err == nil) {
+				t.Fatalf("expected %v, %v, got %v, %v", test.value, test.err, v, err)
+			}
+			if meta.Attempts != 1 || meta.Hedges != 1 {
+				t.Fatalf("expected 1 attempt and 1 hedge, got %+v", meta)
+			}
+		})
+	}
+}
+
+func TestWithHedge_Retry(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	// Retries wrap the hedged call: each attempt may be hedged again
+	clock := fakeclock.New(time.Now())
+	release := make(chan struct{})
+	task, calls := sequenced(
+		func(context.Context) (any, error) { <-release; return nil, errFailed },
+		func(context.Context) (any, error) { return nil, errFailed },
+		stuckCall,
+		func(context.Context) (any, error) { return "retried hedge", nil },
+	)
+	f := NewFuture(context.Background(), task, WithClock(clock), WithHedge(time.Second), WithRetry(2))
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	waitCalls(calls, 2)
+	close(release)
+	waitCalls(calls, 3)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	if v, err, meta := f.ResultMeta(); v != "retried hedge" || err != nil || meta.Attempts != 2 || meta.Hedges != 2 {
+		t.Fatalf("expected the second attempt's hedge to win, got %v, %v, %+v", v, err, meta)
+	}
+
+	// A hedge fires for a later attempt even if the first was not hedged
+	clock = fakeclock.New(time.Now())
+	task, calls = sequenced(
+		func(context.Context) (any, error) { return nil, errFailed },
+		stuckCall,
+		func(context.Context) (any, error) { return "hedge", nil },
+	)
+	f = NewFuture(context.Background(), task, WithClock(clock), WithHedge(time.Second), WithRetry(2))
+	waitCalls(calls, 2)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	if v, err, meta := f.ResultMeta(); v != "hedge" || err != nil || meta.Attempts != 2 || meta.Hedges != 1 {
+		t.Fatalf("expected the retry's hedge to win, got %v, %v, %+v", v, err, meta)
+	}
+}
+
+func TestWithHedge_Timeout(t *testing.T) {
+	// The timeout bounds the hedge like the first call
+	clock := fakeclock.New(time.Now())
+	task, calls := sequenced(stuckCall, stuckCall)
+	f := NewFuture(context.Background(), task, WithClock(clock), WithHedge(time.Second), WithTimeout(3*time.Second))
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+	waitCalls(calls, 2)
+	clock.Advance(2 * time.Second)
+	if _, err, meta := f.ResultMeta(); !IsTimeout(err) || meta.Attempts != 1 || meta.Hedges != 1 {
+		t.Fatalf("expected a timeout after 1 attempt and 1 hedge, got %v, %+v", err, meta)
+	}
+}
+
+func TestWithHedge_OutlivesRun(t *testing.T) {
+	// The first call settles the future and the run goroutine exits while
+	// the hedge is still calling the task.
+	var returned atomic.Int32
+	task := func(ctx context.Context) (any, error) {
+		time.Sleep(3 * time.Millisecond)
+		returned.Add(1)
+		return "ok", nil
+	}
+	for range 20 {
+		returned.Store(0)
+		f := NewFuture(context.Background(), task, WithHedge(time.Millisecond))
+		if v, err := f.Result(); v != "ok" || err != nil {
+			t.Fatalf("expected ok, got %v, %v", v, err)
+		}
+		f.AbortAndWait(context.Background())
+		if _, _, meta := f.ResultMeta(); meta.Hedges == 1 {
+			waitCalls(&returned, 2)
+		}
+	}
+}
+
+func TestWithHedge_Dispatcher(t *testing.T) {
+	d := NewDispatcher(2)
+	defer d.Close()
+	SetDefaultOptions(WithHedge(time.Millisecond))
+	defer SetDefaultOptions()
+
+	// A hedge still running must keep the dispatcher from recycling the
+	// future under it.
+	var running atomic.Int32
+	for i := range 20 {
+		v, err := d.Do(context.Background(), func(ctx context.Context) (any, error) {
+			running.Add(1)
+			defer running.Add(-1)
+			time.Sleep(3 * time.Millisecond)
+			return i, nil
+		})
+		if v != i || err != nil {
+			t.Fatalf("Do %d: unexpected result %v, %v", i, v, err)
+		}
+	}
+	for running.Load() > 0 {
+		runtime.Gosched()
+	}
+}
@@ -0,0 +1,124 @@
+// Package fakeclock implements the fake clock behind testutil.FakeClock,
+// apart from testutil so the package's own tests can use it.
+package fakeclock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Timer is the timer type of A.Clock.
+type Timer = interface {
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Clock is an A.Clock whose time only moves when the test advances it.
+// Timers fire on the goroutine that calls Advance, in deadline order.
+type Clock struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+// New creates a Clock set to now.
+func New(now time.Time) *Clock {
+	c := &Clock{now: now}
+	c.changed = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the clock's current time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc arms a timer that calls f once the clock has advanced by d.
+func (c *Clock) AfterFunc(d time.Duration, f func()) Timer {
+	t := &fakeTimer{clock: c, f: f}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing every timer that comes due,
+// including timers armed or re-armed by the ones it fires.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	for {
+		i := -1
+		for j, t := range c.timers {
+			if !t.when.After(end) && (i < 0 || t.when.Before(c.timers[i].when)) {
+				i = j
+			}
+		}
+		if i < 0 {
+			break
+		}
+		t := c.timers[i]
+		c.timers = slices.Delete(c.timers, i, i+1)
+		if t.when.After(c.now) {
+			c.now = t.when
+		}
+		c.changed.Broadcast()
+		c.mu.Unlock()
+		t.f()
+		c.mu.Lock()
+	}
+	c.now = end
+	c.mu.Unlock()
+}
+
+// Timers returns the number of armed timers.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil waits until at least n timers are armed, for tests that must
+// let a future's goroutine arm its timers before advancing the clock.
+func (c *Clock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.changed.Wait()
+	}
+}
+
+// fakeTimer is a timer of a Clock.
+type fakeTimer struct {
+	clock *Clock
+	when  time.Time
+	f     func()
+}
+
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := slices.Index(c.timers, t)
+	if i < 0 {
+		return false
+	}
+	c.timers = slices.Delete(c.timers, i, i+1)
+	c.changed.Broadcast()
+	return true
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t.when = c.now.Add(d)
+	active := slices.Contains(c.timers, t)
+	if !active {
+		c.timers = append(c.timers, t)
+	}
+	c.changed.Broadcast()
+	return active
+}
@@ -0,0 +1,43 @@
+package fakeclock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New(start)
+	var fired []int
+	c.AfterFunc(2*time.Second, func() {
+		fired = append(fired, 2)
+	})
+	stopped := c.AfterFunc(time.Second, func() {
+		fired = append(fired, 0)
+	})
+	c.AfterFunc(time.Second, func() {
+		fired = append(fired, 1)
+		// Timers armed while advancing fire if they come due.
+		c.AfterFunc(500*time.Millisecond, func() {
+			fired = append(fired, 15)
+		})
+	})
+	if !stopped.Stop() || stopped.Stop() {
+		t.Fatalf("expected Stop to report an armed timer once")
+	}
+
+	c.Advance(1500 * time.Millisecond)
+	if len(fired) != 2 || fired[0] != 1 || fired[1] != 15 {
+		t.Fatalf("unexpected timers fired: %v", fired)
+	}
+	if now := c.Now(); !now.Equal(start.Add(1500 * time.Millisecond)) {
+		t.Fatalf("unexpected time %v", now)
+	}
+	if c.Timers() != 1 {
+		t.Fatalf("expected one armed timer, got %d", c.Timers())
+	}
+	c.Advance(time.Second)
+	if len(fired) != 3 || fired[2] != 2 {
+		t.Fatalf("unexpected timers fired: %v", fired)
+	}
+}
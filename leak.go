@@ -0,0 +1,66 @@
+package A
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// leakReport holds the callback set with EnableLeakDetection.
+var leakReport atomic.Pointer[func(FutureInfo)]
+
+// EnableLeakDetection reports futures that are garbage collected without
+// anyone having read their result, started them, or aborted them: lazy
+// futures nobody started and eager futures nobody awaited. report runs on
+// a runtime goroutine with the future's name, creation site, and whether it
+// had settled; FutureInfo.Future is nil. Detection costs a stack walk and a
+// cleanup per future, so it is meant for tests and debugging. Futures created
+// before the call are not tracked. A nil report disables it.
+func EnableLeakDetection(report func(info FutureInfo)) {
+	if report == nil {
+		leakReport.Store(nil)
+		return
+	}
+	leakReport.Store(&report)
+}
+
+// leakState is what a leak report needs to know about a future. It must not
+// reference the future, or the future could never be collected.
+type leakState struct {
+	name      string
+	origin    string
+	createdAt time.Time
+	settled   atomic.Bool
+	consumed  atomic.Bool
+}
+
+// trackLeak arranges for f to be reported if it is collected unconsumed.
+func (f *Future) trackLeak(report func(FutureInfo)) {
+	if f.origin == "" {
+		f.origin = callerOrigin()
+	}
+	f.leak = &leakState{name: f.name, origin: f.origin, createdAt: f.createdAt}
+	runtime.AddCleanup(f, func(l *leakState) {
+		if l.consumed.Load() {
+			return
+		}
+		state := Pending
+		if l.settled.Load() {
+			state = Settled
+		}
+		report(FutureInfo{
+			Name:      l.name,
+			State:     state,
+			CreatedAt: l.createdAt,
+			Age:       time.Since(l.createdAt),
+			Origin:    l.origin,
+		})
+	}, f.leak)
+}
+
+// consume marks the future's result as having been looked at.
+func (f *Future) consume() {
+	if f.leak != nil {
+		f.leak.consumed.Store(true)
+	}
+}
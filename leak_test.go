@@ -0,0 +1,55 @@
+package A
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLeakDetection(t *testing.T) {
+	reports := make(chan FutureInfo, 10)
+	EnableLeakDetection(func(info FutureInfo) {
+		reports <- info
+	})
+	defer EnableLeakDetection(nil)
+
+	task := func(context.Context) (any, error) {
+		return nil, nil
+	}
+	func() {
+		NewFuture(context.Background(), task, WithLazy(), WithName("abandoned"))
+		NewFuture(context.Background(), task, WithName("consumed")).Result()
+	}()
+
+	deadline := time.After(time.Second)
+	for {
+		runtime.GC()
+		select {
+		case info := <-reports:
+			if info.Name == "consumed" {
+				t.Fatalf("consumed future was reported")
+			}
+			if info.Name != "abandoned" {
+				// Left over from another test.
+				continue
+			}
+			if info.State != Pending || !strings.Contains(info.Origin, "leak_test.go") {
+				t.Fatalf("unexpected report: %+v", info)
+			}
+			// Give a wrong report for the consumed future a chance to show up.
+			runtime.GC()
+			time.Sleep(10 * time.Millisecond)
+			for len(reports) > 0 {
+				if info := <-reports; info.Name == "consumed" {
+					t.Fatalf("consumed future was reported")
+				}
+			}
+			return
+		case <-deadline:
+			t.Fatalf("abandoned lazy future was not reported")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package A
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// Semaphore is a weighted semaphore. *semaphore.Weighted from
+// golang.org/x/sync/semaphore satisfies it.
+type Semaphore interface {
+	Acquire(ctx context.Context, n int64) error
+	Release(n int64)
+}
+
+// WithSemaphore makes the future acquire weight from sem before running the
+// task and release it when the task returns, including on panic. Waiting for
+// the semaphore respects the future's context, so Abort settles a waiting
+// future promptly without leaking an acquisition.
+func WithSemaphore(sem Semaphore, weight int64) Option {
+	return func(f *Future) {
+		if sem == nil || weight <= 0 {
+			f.invalidOption("WithSemaphore", "nil semaphore or non-positive weight %d", weight)
+			return
+		}
+		f.sem, f.weight = sem, weight
+	}
+}
+
+// Limiter paces task starts. *rate.Limiter from golang.org/x/time/rate
+// satisfies it.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// WithLimiter makes the task wait on l before it starts executing. The wait
+// is cancelled by Abort and by the parent context.
+func WithLimiter(l Limiter) Option {
+	return func(f *Future) {
+		f.limiter = l
+	}
+}
+
+// defaultGovernor bounds every future that does not choose its own governor.
+var defaultGovernor = NewGovernor(0)
+
+// SetMaxInFlight caps how many futures created by NewFuture may run at once
+// across the whole process. Futures beyond the cap wait, without a
+// goroutine, until a slot frees or their context is done. Zero or a negative
+// n disables the cap, which is the default.
+func SetMaxInFlight(n int) {
+	defaultGovernor.SetMax(n)
+}
+
+// InFlight returns the number of futures currently running under the
+// package-level governor.
+func InFlight() int {
+	return defaultGovernor.InFlight()
+}
+
+// WithGovernor runs the future under g instead of the package-level
+// governor. A nil g exempts the future from any cap.
+func WithGovernor(g *Governor) Option {
+	return func(f *Future) {
+		f.governor = g
+	}
+}
+
+// Governor caps the number of futures running at once. Futures started by
+// a Pool are bounded by the pool and are not governed.
+type Governor struct {
+	mu       sync.Mutex
+	max      int
+	inFlight int
+	queue    list.List
+}
+
+// governed is a future waiting for a governor slot.
+type governed struct {
+	f    *Future
+	stop func() bool
+}
+
+// NewGovernor creates a Governor that allows max futures to run at once.
+// Zero or a negative max disables the cap.
+func NewGovernor(max int) *Governor {
+	return &Governor{max: max}
+}
+
+// SetMax changes the cap. Raising it starts waiting futures immediately.
+func (g *Governor) SetMax(n int) {
+	g.mu.Lock()
+	g.max = n
+	admitted := g.admit()
+	g.mu.Unlock()
+	for _, f := range admitted {
+		go g.run(f)
+	}
+}
+
+// InFlight returns the number of futures currently running.
+func (g *Governor) InFlight() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.inFlight
+}
+
+// Queued returns the number of futures waiting for a slot.
+func (g *Governor) Queued() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.queue.Len()
+}
+
+// launch runs f in a new goroutine if a slot is free and queues it otherwise.
+// A queued future settles with its context error if the context is done
+// before it is admitted.
+func (g *Governor) launch(f *Future) {
+	g.mu.Lock()
+	if g.max <= 0 || g.inFlight < g.max {
+		g.inFlight++
+		g.mu.Unlock()
+		go g.run(f)
+		return
+	}
+	entry := &governed{f: f}
+	elem := g.queue.PushBack(entry)
+	entry.stop = context.AfterFunc(f.ctx, func() {
+		g.mu.Lock()
+		g.queue.Remove(elem)
+		g.mu.Unlock()
+		f.settle(nil, f.ctx.Err())
+	})
+	g.mu.Unlock()
+}
+
+// run executes f and frees its slot once the task returns.
+func (g *Governor) run(f *Future) {
+	defer g.release()
+	f.run()
+}
+
+// release frees a slot and starts the next waiting futures.
+func (g *Governor) release() {
+	g.mu.Lock()
+	g.inFlight--
+	admitted := g.admit()
+	g.mu.Unlock()
+	for _, f := range admitted {
+		go g.run(f)
+	}
+}
+
+// admit takes waiting futures off the queue while slots are free. It must
+// be called with g.mu held.
+func (g *Governor) admit() []*Future {
+	var admitted []*Future
+	for g.queue.Len() > 0 && (g.max <= 0 || g.inFlight < g.max) {
+		entry := g.queue.Remove(g.queue.Front()).(*governed)
+		if !entry.stop() {
+			// The context is done and the AfterFunc settles the future.
+			continue
+		}
+		g.inFlight++
+		admitted = append(admitted, entry.f)
+	}
+	return admitted
+}
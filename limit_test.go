@@ -0,0 +1,353 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testSemaphore is a minimal weighted semaphore for tests.
+type testSemaphore struct {
+	mu       sync.Mutex
+	size     int64
+	cur      int64
+	released chan struct{}
+}
+
+func newTestSemaphore(size int64) *testSemaphore {
+	return &testSemaphore{size: size, released: make(chan struct{})}
+}
+
+func (s *testSemaphore) Acquire(ctx context.Context, n int64) error {
+	for {
+		s.mu.Lock()
+		if s.cur+n <= s.size {
+			s.cur += n
+			s.mu.Unlock()
+			return nil
+		}
+		released := s.released
+		s.mu.Unlock()
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *testSemaphore) Release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cur -= n
+	close(s.released)
+	s.released = make(chan struct{})
+}
+
+func (s *testSemaphore) held() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cur
+}
+
+func TestFuture_Semaphore(t *testing.T) {
+	sem := newTestSemaphore(2)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	first := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-release
+		return "first", nil
+	}, WithSemaphore(sem, 2))
+	<-started
+
+	// The second future cannot start until the first releases its weight
+	second := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "second", nil
+	}, WithSemaphore(sem, 1))
+	select {
+	case <-second.Done():
+		t.Fatal("expected second future to wait for the semaphore")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	first.Result()
+	result, err := second.Result()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != "second" {
+		t.Fatalf("expected result 'second', got %v", result)
+	}
+	if held := sem.held(); held != 0 {
+		t.Fatalf("expected semaphore to be fully released, %d still held", held)
+	}
+}
+
+func TestFuture_SemaphoreAbort(t *testing.T) {
+	sem := newTestSemaphore(1)
+	sem.Acquire(context.Background(), 1)
+
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		t.Error("task should not run after abort")
+		return nil, nil
+	}, WithSemaphore(sem, 1))
+
+	// Abort while the future waits for the semaphore
+	future.Abort()
+	select {
+	case <-future.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected aborted future to settle promptly")
+	}
+	if _, err := future.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// The aborted future must not leak an acquisition
+	sem.Release(1)
+	time.Sleep(10 * time.Millisecond)
+	if held := sem.held(); held != 0 {
+		t.Fatalf("expected no leaked acquisition, %d still held", held)
+	}
+}
+
+func TestFuture_SemaphorePanic(t *testing.T) {
+	sem := newTestSemaphore(1)
+
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		panic("unexpected error")
+	}, WithSemaphore(sem, 1))
+	if _, err := future.Result(); err == nil {
+		t.Fatal("expected panic error, got nil")
+	}
+
+	// The weight is released before the future settles
+	if held := sem.held(); held != 0 {
+		t.Fatalf("expected semaphore to be released after panic, %d still held", held)
+	}
+}
+
+// testLimiter allows one event per interval.
+type testLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func (l *testLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestFuture_Limiter(t *testing.T) {
+	limiter := &testLimiter{interval: 20 * time.Millisecond}
+
+	var mu sync.Mutex
+	var starts []time.Time
+	var futures []*Future
+	for i := 0; i < 4; i++ {
+		futures = append(futures, NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			mu.Lock()
+			starts = append(starts, time.Now())
+			mu.Unlock()
+			return nil, nil
+		}, WithLimiter(limiter)))
+	}
+	for _, f := range futures {
+		f.Result()
+	}
+
+	// Task starts are spaced out by the limiter
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i].Sub(starts[i-1]); gap < 15*time.Millisecond {
+			t.Fatalf("expected starts at least 15ms apart, got %v", gap)
+		}
+	}
+}
+
+func TestFuture_LimiterAbort(t *testing.T) {
+	limiter := &testLimiter{interval: time.Hour}
+	limiter.Wait(context.Background())
+
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		t.Error("task should not run after abort")
+		return nil, nil
+	}, WithLimiter(limiter))
+	future.Abort()
+	if _, err := future.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// The parent context cancels the wait too
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	future = NewFuture(ctx, func(ctx context.Context) (any, error) {
+		t.Error("task should not run after the parent context expired")
+		return nil, nil
+	}, WithLimiter(limiter))
+	if _, err := future.Result(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestGovernor(t *testing.T) {
+	g := NewGovernor(2)
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	release := make(chan struct{})
+	var futures []*Future
+	for i := 0; i < 5; i++ {
+		futures = append(futures, NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+			<-release
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil, nil
+		}, WithGovernor(g)))
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for g.InFlight() != 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if inFlight, queued := g.InFlight(), g.Queued(); inFlight != 2 || queued != 3 {
+		t.Fatalf("expected 2 in flight and 3 queued, got %d and %d", inFlight, queued)
+	}
+
+	close(release)
+	for _, f := range futures {
+		f.Result()
+	}
+	if peak > 2 {
+		t.Fatalf("expected at most 2 futures running at once, got %d", peak)
+	}
+	deadline = time.Now().Add(time.Second)
+	for g.InFlight() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := g.InFlight(); got != 0 {
+		t.Fatalf("expected no futures in flight, got %d", got)
+	}
+}
+
+func TestGovernor_QueuedAbort(t *testing.T) {
+	g := NewGovernor(1)
+
+	release := make(chan struct{})
+	defer close(release)
+	NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	}, WithGovernor(g))
+
+	// Queued futures settle promptly when aborted or when their context ends
+	aborted := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		t.Error("aborted task should not run")
+		return nil, nil
+	}, WithGovernor(g))
+	aborted.Abort()
+	if _, err := aborted.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	expired := NewFuture(ctx, func(ctx context.Context) (any, error) {
+		t.Error("expired task should not run")
+		return nil, nil
+	}, WithGovernor(g))
+	if _, err := expired.Result(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for g.Queued() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := g.Queued(); got != 0 {
+		t.Fatalf("expected settled futures to leave the queue, got %d queued", got)
+	}
+}
+
+func TestGovernor_SetMax(t *testing.T) {
+	g := NewGovernor(1)
+
+	release := make(chan struct{})
+	task := func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	}
+	first := NewFuture(context.Background(), task, WithGovernor(g))
+	second := NewFuture(context.Background(), task, WithGovernor(g))
+
+	// Disabling the cap admits the waiting future
+	g.SetMax(0)
+	deadline := time.Now().Add(time.Second)
+	for g.InFlight() != 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := g.InFlight(); got != 2 {
+		t.Fatalf("expected 2 futures in flight, got %d", got)
+	}
+	close(release)
+	first.Result()
+	second.Result()
+}
+
+func TestSetMaxInFlight(t *testing.T) {
+	SetMaxInFlight(1)
+	defer SetMaxInFlight(0)
+
+	release := make(chan struct{})
+	first := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	})
+	second := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "second", nil
+	})
+	exempt := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "exempt", nil
+	}, WithGovernor(nil))
+
+	// The exempt future runs despite the cap, the second one waits
+	if result, _ := exempt.Result(); result != "exempt" {
+		t.Fatalf("expected result 'exempt', got %v", result)
+	}
+	if got := InFlight(); got != 1 {
+		t.Fatalf("expected 1 future in flight, got %d", got)
+	}
+	if second.Ready() {
+		t.Fatal("expected second future to wait for the cap")
+	}
+	close(release)
+	first.Result()
+	if result, _ := second.Result(); result != "second" {
+		t.Fatalf("expected result 'second', got %v", result)
+	}
+}
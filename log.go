@@ -0,0 +1,113 @@
+package A
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// LogLevels are the levels at which a logged future reports its events.
+type LogLevels struct {
+	// Start is the level of the event logged when the task starts.
+	Start slog.Level
+	// Success is the level of the event logged when the future succeeds.
+	Success slog.Level
+	// Abort is the level of the event logged when the future is aborted.
+	Abort slog.Level
+	// Panic is the level of the event logged when the task panics.
+	Panic slog.Level
+	// Failure is the level of the event logged when the future fails or
+	// times out.
+	Failure slog.Level
+}
+
+// DefaultLogLevels logs starts and successes at debug level, aborts and
+// panics at warn level, and failures at error level.
+var DefaultLogLevels = LogLevels{
+	Start:   slog.LevelDebug,
+	Success: slog.LevelDebug,
+	Abort:   slog.LevelWarn,
+	Panic:   slog.LevelWarn,
+	Failure: slog.LevelError,
+}
+
+// WithLogger logs the future's start and settlement to l, with the future's
+// name, outcome, duration, and attempt count as attributes. It overrides the
+// default set with SetDefaultLogger; a nil l disables logging.
+func WithLogger(l *slog.Logger) Option {
+	return func(f *Future) {
+		f.logger = l
+	}
+}
+
+// WithLogLevels sets the levels used by the future's logger. It defaults to
+// DefaultLogLevels.
+func WithLogLevels(levels LogLevels) Option {
+	return func(f *Future) {
+		f.logLevels = &levels
+	}
+}
+
+// defaultLogger is the logger applied to new futures.
+var defaultLogger atomic.Pointer[slog.Logger]
+
+// SetDefaultLogger logs the events of futures created afterwards to l.
+// A nil l disables the default.
+func SetDefaultLogger(l *slog.Logger) {
+	defaultLogger.Store(l)
+}
+
+// levels returns the log levels of f.
+func (f *Future) levels() *LogLevels {
+	if f.logLevels != nil {
+		return f.logLevels
+	}
+	return &DefaultLogLevels
+}
+
+// logStart logs that the task started. It must not be called with f.mu held.
+func (f *Future) logStart() {
+	f.mu.Lock()
+	attempt := f.attempts
+	f.mu.Unlock()
+	f.logger.LogAttrs(f.logContext(), f.levels().Start, "future started",
+		slog.String("name", f.name),
+		slog.Int("attempt", attempt),
+	)
+}
+
+// logSettled logs how the future settled. It must not be called with f.mu
+// held.
+func (f *Future) logSettled(outcome Outcome, err error, execTime, waitTime time.Duration, attempts int) {
+	levels := f.levels()
+	level := levels.Failure
+	switch outcome {
+	case Succeeded:
+		level = levels.Success
+	case Aborted:
+		level = levels.Abort
+	case Panicked:
+		level = levels.Panic
+	}
+	attrs := []slog.Attr{
+		slog.String("name", f.name),
+		slog.String("outcome", outcome.String()),
+		slog.Duration("duration", execTime),
+		slog.Duration("wait", waitTime),
+		slog.Int("attempts", attempts),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	f.logger.LogAttrs(f.logContext(), level, "future settled", attrs...)
+}
+
+// logContext returns the context to log with: the one the future was
+// created with, so handlers can read request-scoped values.
+func (f *Future) logContext() context.Context {
+	if f.base != nil {
+		return f.base
+	}
+	return context.Background()
+}
@@ -0,0 +1,90 @@
+package A
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+// testLogBuffer is a concurrency-safe buffer for JSON log output.
+type testLogBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *testLogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// records decodes the logged records.
+func (b *testLogBuffer) records(t *testing.T) []map[string]any {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var records []map[string]any
+	dec := json.NewDecoder(bytes.NewReader(b.buf.Bytes()))
+	for dec.More() {
+		var record map[string]any
+		if err := dec.Decode(&record); err != nil {
+			t.Fatalf("expected valid JSON, got %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestFuture_Logger(t *testing.T) {
+	var buf testLogBuffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, errors.New("failed")
+	}, WithLogger(logger), WithName("load")).Result()
+
+	records := buf.records(t)
+	if len(records) != 2 {
+		t.Fatalf("expected a start and a settle record, got %v", records)
+	}
+	start, settled := records[0], records[1]
+	if start["msg"] != "future started" || start["level"] != "DEBUG" || start["name"] != "load" || start["attempt"] != 1.0 {
+		t.Fatalf("unexpected start record %v", start)
+	}
+	if settled["msg"] != "future settled" || settled["level"] != "ERROR" || settled["outcome"] != "Failed" || settled["error"] != `future "load": failed` {
+		t.Fatalf("unexpected settle record %v", settled)
+	}
+	if _, ok := settled["duration"]; !ok {
+		t.Fatalf("expected a duration attribute, got %v", settled)
+	}
+}
+
+func TestFuture_LogLevels(t *testing.T) {
+	var buf testLogBuffer
+	SetDefaultLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer SetDefaultLogger(nil)
+
+	// Only the abort is at or above the handler's info level
+	lazy := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithLazy())
+	lazy.Abort()
+	NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}).Result()
+	NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithLogLevels(LogLevels{Start: slog.LevelDebug, Success: slog.LevelInfo})).Result()
+
+	records := buf.records(t)
+	if len(records) != 2 || records[0]["level"] != "WARN" || records[0]["outcome"] != "Aborted" {
+		t.Fatalf("expected an abort warning first, got %v", records)
+	}
+	if records[1]["level"] != "INFO" || records[1]["outcome"] != "Succeeded" {
+		t.Fatalf("expected the raised success level to be logged, got %v", records)
+	}
+}
@@ -0,0 +1,373 @@
+package A
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoOption defines functional options for Memo.
+type MemoOption func(*Memo)
+
+// WithErrorCaching keeps failed results in the memo like successful ones.
+// By default a failure is forgotten once it settles, so the next Do for its
+// key runs the task again.
+func WithErrorCaching() MemoOption {
+	return func(m *Memo) {
+		m.cacheErrors = true
+	}
+}
+
+// WithStaleWhileRevalidate makes a cache keep serving an expired result
+// while a fresh execution runs in the background, instead of making callers
+// wait for it. If the fresh execution fails, the stale result is kept and
+// the next request tries again.
+func WithStaleWhileRevalidate() MemoOption {
+	return func(m *Memo) {
+		m.stale = true
+	}
+}
+
+// WithSweepInterval makes a cache evict expired results every d, in addition
+// to checking for expiry on access. Close stops the sweeper.
+func WithSweepInterval(d time.Duration) MemoOption {
+	return func(m *Memo) {
+		m.sweepEvery = d
+	}
+}
+
+// WithMaxEntries bounds the memo to n keys by evicting the least recently
+// used settled results. Executions in flight are never evicted, so the memo
+// may exceed n while more than n are running. Zero or a negative n means no
+// bound.
+func WithMaxEntries(n int) MemoOption {
+	return func(m *Memo) {
+		m.maxEntries = n
+	}
+}
+
+// WithOnEvict calls fn with the key and future of every result the memo
+// drops after remembering it: evicted by WithMaxEntries, expired, or
+// forgotten. Failures that are not cached are never remembered, so fn is
+// not called for them. fn runs without the memo's lock held.
+func WithOnEvict(fn func(key string, f *Future)) MemoOption {
+	return func(m *Memo) {
+		m.onEvict = fn
+	}
+}
+
+// WithMemoClock makes the memo read time from c instead of the real clock.
+func WithMemoClock(c Clock) MemoOption {
+	return func(m *Memo) {
+		m.clock = c
+	}
+}
+
+// MemoStats counts how requests to a Memo were served.
+type MemoStats struct {
+	// Hits is the number of requests that shared an execution in flight or
+	// a remembered result.
+	Hits uint64
+	// Misses is the number of requests that started an execution.
+	Misses uint64
+	// Stale is the number of requests served an expired result while it
+	// was being revalidated.
+	Stale uint64
+	// Evictions is the number of results evicted by WithMaxEntries.
+	Evictions uint64
+	// Expirations is the number of results dropped because they expired.
+	Expirations uint64
+}
+
+// Memo shares one task execution between concurrent and later requests for
+// the same key, and remembers its result until the key is forgotten or, for
+// a cache, until the result expires.
+type Memo struct {
+	cacheErrors bool
+	ttl         time.Duration
+	stale       bool
+	sweepEvery  time.Duration
+	maxEntries  int
+	onEvict     func(key string, f *Future)
+	clock       Clock
+
+	mu      sync.Mutex
+	entries map[string]*memoEntry
+	lru     list.List // of keys, most recently used first
+	stats   MemoStats
+	sweeper Timer
+	closed  bool
+}
+
+// memoEntry is the shared execution for a key and the number of callers
+// still waiting on it.
+type memoEntry struct {
+	future    *Future
+	callers   int
+	done      bool
+	settledAt time.Time
+	refresh   *Future
+	elem      *list.Element
+}
+
+// eviction is a result dropped from the memo, reported to WithOnEvict.
+type eviction struct {
+	key    string
+	future *Future
+}
+
+// NewMemo creates an empty Memo.
+func NewMemo(opts ...MemoOption) *Memo {
+	m := &Memo{entries: make(map[string]*memoEntry)}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// NewCache creates a Memo whose results expire ttl after they settle. The
+// first request after a result expires starts a fresh execution. A zero or
+// negative ttl means results never expire.
+func NewCache(ttl time.Duration, opts ...MemoOption) *Memo {
+	m := NewMemo(opts...)
+	m.ttl = ttl
+	if m.ttl > 0 && m.sweepEvery > 0 {
+		m.mu.Lock()
+		m.sweeper = m.afterFunc(m.sweepEvery, m.sweep)
+		m.mu.Unlock()
+	}
+	return m
+}
+
+// Do returns a future for the result of the task memoized under key. The
+// first call for a key starts the task with opts, under a context that
+// carries ctx's values but not its cancellation; later calls share that
+// execution and its result, and their task and opts are ignored.
+//
+// Every caller gets its own future, which fails with the cause of the
+// caller's ctx if it is done first. Aborting it withdraws only that caller's
+// interest: the shared task is aborted, and the key forgotten, once every
+// caller waiting on it has gone.
+func (m *Memo) Do(ctx context.Context, key string, task func(context.Context) (any, error), opts ...Option) *Future {
+	ctx = orBackground(ctx)
+	var evicted []eviction
+	m.mu.Lock()
+	e := m.entries[key]
+	switch {
+	case e == nil || m.expired(e) && !m.stale:
+		f := newFuture(context.WithoutCancel(ctx), task, opts...)
+		if f.rejectInvalid() {
+			m.mu.Unlock()
+			return f
+		}
+		if e != nil {
+			m.stats.Expirations++
+			evicted = m.remove(key, e, evicted)
+		}
+		m.stats.Misses++
+		e = &memoEntry{future: f, elem: m.lru.PushFront(key)}
+		m.entries[key] = e
+		evicted = m.evictLRU(evicted)
+		f.whenDone(func() {
+			m.finish(key, e)
+		})
+		f.once.Do(f.start)
+	case m.expired(e):
+		m.stats.Stale++
+		m.lru.MoveToFront(e.elem)
+		if e.refresh == nil {
+			m.revalidate(ctx, key, e, task, opts...)
+		}
+	default:
+		m.stats.Hits++
+		m.lru.MoveToFront(e.elem)
+	}
+	e.callers++
+	m.mu.Unlock()
+	m.evicted(evicted)
+
+	view := newFuture(ctx, nil)
+	view.once.Do(func() {})
+	view.whenDone(func() {
+		m.leave(key, e)
+	})
+	context.AfterFunc(view.ctx, func() {
+		view.settle(nil, context.Cause(view.ctx))
+	})
+	e.future.whenDone(func() {
+		view.settle(e.future.peek())
+	})
+	return view
+}
+
+// Forget drops the memoized result for key, so the next Do runs the task
+// again. An execution still in flight keeps running for its callers.
+func (m *Memo) Forget(key string) {
+	var evicted []eviction
+	m.mu.Lock()
+	if e := m.entries[key]; e != nil {
+		evicted = m.remove(key, e, evicted)
+	}
+	m.mu.Unlock()
+	m.evicted(evicted)
+}
+
+// Stats returns how requests to the memo have been served so far.
+func (m *Memo) Stats() MemoStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// Close stops the sweeper of a cache created with WithSweepInterval.
+// Results already remembered are still served.
+func (m *Memo) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	if m.sweeper != nil {
+		m.sweeper.Stop()
+	}
+}
+
+// Len returns the number of keys in the memo, in flight or settled.
+func (m *Memo) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+// finish records that the shared execution for key has settled, forgetting
+// it if it failed and errors are not cached.
+func (m *Memo) finish(key string, e *memoEntry) {
+	_, err := e.future.peek()
+	var evicted []eviction
+	m.mu.Lock()
+	if err != nil && !m.cacheErrors && m.entries[key] == e {
+		m.remove(key, e, nil)
+	}
+	e.done = true
+	e.settledAt = m.now()
+	evicted = m.evictLRU(evicted)
+	m.mu.Unlock()
+	m.evicted(evicted)
+}
+
+// leave withdraws a caller's interest in the execution for key, aborting it
+// when no caller is left waiting.
+func (m *Memo) leave(key string, e *memoEntry) {
+	m.mu.Lock()
+	e.callers--
+	abandoned := e.callers == 0 && !e.done
+	if abandoned && m.entries[key] == e {
+		m.remove(key, e, nil)
+	}
+	m.mu.Unlock()
+	if abandoned {
+		e.future.Abort()
+	}
+}
+
+// revalidate starts a fresh execution for the expired entry e, which
+// replaces e once it succeeds. The caller holds m.mu.
+func (m *Memo) revalidate(ctx context.Context, key string, e *memoEntry, task func(context.Context) (any, error), opts ...Option) {
+	f := newFuture(context.WithoutCancel(ctx), task, opts...)
+	if f.rejectInvalid() {
+		return
+	}
+	e.refresh = f
+	f.whenDone(func() {
+		_, err := f.peek()
+		var evicted []eviction
+		m.mu.Lock()
+		e.refresh = nil
+		if err == nil && m.entries[key] == e {
+			m.stats.Expirations++
+			evicted = append(evicted, eviction{key: key, future: e.future})
+			fresh := &memoEntry{future: f, done: true, settledAt: m.now(), elem: e.elem}
+			m.entries[key] = fresh
+		}
+		m.mu.Unlock()
+		m.evicted(evicted)
+	})
+	f.once.Do(f.start)
+}
+
+// expired reports whether the result of e has outlived the TTL. The caller
+// holds m.mu.
+func (m *Memo) expired(e *memoEntry) bool {
+	return m.ttl > 0 && e.done && m.now().Sub(e.settledAt) >= m.ttl
+}
+
+// sweep evicts expired results and reschedules itself.
+func (m *Memo) sweep() {
+	var evicted []eviction
+	m.mu.Lock()
+	for key, e := range m.entries {
+		if m.expired(e) && e.refresh == nil {
+			m.stats.Expirations++
+			evicted = m.remove(key, e, evicted)
+		}
+	}
+	if !m.closed {
+		m.sweeper.Reset(m.sweepEvery)
+	}
+	m.mu.Unlock()
+	m.evicted(evicted)
+}
+
+// evictLRU evicts the least recently used settled results until the memo
+// is within WithMaxEntries, appending them to evicted. The caller holds m.mu.
+func (m *Memo) evictLRU(evicted []eviction) []eviction {
+	if m.maxEntries <= 0 {
+		return evicted
+	}
+	for elem := m.lru.Back(); elem != nil && len(m.entries) > m.maxEntries; {
+		prev := elem.Prev()
+		key := elem.Value.(string)
+		if e := m.entries[key]; e.done {
+			m.stats.Evictions++
+			evicted = m.remove(key, e, evicted)
+		}
+		elem = prev
+	}
+	return evicted
+}
+
+// remove drops the entry e for key, appending it to evicted if it held a
+// settled result. The caller holds m.mu.
+func (m *Memo) remove(key string, e *memoEntry, evicted []eviction) []eviction {
+	delete(m.entries, key)
+	m.lru.Remove(e.elem)
+	if e.done {
+		evicted = append(evicted, eviction{key: key, future: e.future})
+	}
+	return evicted
+}
+
+// evicted reports dropped results to WithOnEvict.
+func (m *Memo) evicted(evicted []eviction) {
+	if m.onEvict == nil {
+		return
+	}
+	for _, ev := range evicted {
+		m.onEvict(ev.key, ev.future)
+	}
+}
+
+// now returns the current time on the memo's clock.
+func (m *Memo) now() time.Time {
+	if m.clock == nil {
+		return time.Now()
+	}
+	return m.clock.Now()
+}
+
+// afterFunc calls fn after d on the memo's clock.
+func (m *Memo) afterFunc(d time.Duration, fn func()) Timer {
+	if m.clock == nil {
+		return time.AfterFunc(d, fn)
+	}
+	return m.clock.AfterFunc(d, fn)
+}
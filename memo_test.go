@@ -0,0 +1,313 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+func TestMemo_SharesExecution(t *testing.T) {
+	m := NewMemo()
+	var runs atomic.Int32
+	release := make(chan struct{})
+	task := func(ctx context.Context) (any, error) {
+		runs.Add(1)
+		<-release
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	futures := make([]*Future, 10)
+	for i := range futures {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			futures[i] = m.Do(context.Background(), "key", task)
+		}()
+	}
+	wg.Wait()
+	close(release)
+	for _, f := range futures {
+		if v, err := f.Result(); v != "value" || err != nil {
+			t.Fatalf("expected shared result, got %v, %v", v, err)
+		}
+	}
+
+	// The result is remembered
+	if v, _ := m.Do(context.Background(), "key", task).Result(); v != "value" {
+		t.Fatalf("expected memoized result, got %v", v)
+	}
+	if n := runs.Load(); n != 1 {
+		t.Fatalf("expected 1 execution, got %d", n)
+	}
+}
+
+func TestMemo_ForgetsFailures(t *testing.T) {
+	errFailed := errors.New("failed")
+	var runs atomic.Int32
+	task := func(ctx context.Context) (any, error) {
+		runs.Add(1)
+		return nil, errFailed
+	}
+
+	m := NewMemo()
+	for range 2 {
+		if _, err := m.Do(context.Background(), "key", task).Result(); !errors.Is(err, errFailed) {
+			t.Fatalf("expected errFailed, got %v", err)
+		}
+	}
+	if n := runs.Load(); n != 2 {
+		t.Fatalf("expected the failure to be retried, got %d executions", n)
+	}
+
+	// WithErrorCaching remembers the failure
+	runs.Store(0)
+	m = NewMemo(WithErrorCaching())
+	for range 2 {
+		if _, err := m.Do(context.Background(), "key", task).Result(); !errors.Is(err, errFailed) {
+			t.Fatalf("expected errFailed, got %v", err)
+		}
+	}
+	if n := runs.Load(); n != 1 {
+		t.Fatalf("expected the failure to be cached, got %d executions", n)
+	}
+}
+
+func TestMemo_Forget(t *testing.T) {
+	m := NewMemo()
+	var runs atomic.Int32
+	task := func(ctx context.Context) (any, error) {
+		return runs.Add(1), nil
+	}
+	m.Do(context.Background(), "key", task).Result()
+	m.Forget("key")
+	if m.Len() != 0 {
+		t.Fatalf("expected an empty memo, got %d keys", m.Len())
+	}
+	if v, _ := m.Do(context.Background(), "key", task).Result(); v != int32(2) {
+		t.Fatalf("expected the task to run again, got %v", v)
+	}
+}
+
+func TestMemo_AbortWithdrawsInterest(t *testing.T) {
+	m := NewMemo()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	aborted := make(chan struct{})
+	task := func(ctx context.Context) (any, error) {
+		close(started)
+		select {
+		case <-release:
+			return "value", nil
+		case <-ctx.Done():
+			close(aborted)
+			return nil, ctx.Err()
+		}
+	}
+
+	first := m.Do(context.Background(), "key", task)
+	<-started
+	second := m.Do(context.Background(), "key", task)
+
+	// Aborting one caller leaves the shared execution running
+	first.Abort()
+	if _, err := first.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the aborted caller to fail, got %v", err)
+	}
+	close(release)
+	if v, err := second.Result(); v != "value" || err != nil {
+		t.Fatalf("expected the other caller to get the result, got %v, %v", v, err)
+	}
+	select {
+	case <-aborted:
+		t.Fatal("shared execution should not be aborted")
+	default:
+	}
+}
+
+func TestMemo_AbortsAbandonedExecution(t *testing.T) {
+	m := NewMemo()
+	started := make(chan struct{})
+	aborted := make(chan struct{})
+	task := func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		close(aborted)
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := m.Do(ctx, "key", task)
+	second := m.Do(context.Background(), "key", task)
+	<-started
+
+	// A caller whose context is done fails with its cause
+	cancel()
+	if _, err := first.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// Once the last caller goes, the execution is aborted and forgotten
+	second.Abort()
+	<-aborted
+	if m.Len() != 0 {
+		t.Fatalf("expected the key to be forgotten, got %d keys", m.Len())
+	}
+}
+
+func TestCache_Expiry(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	m := NewCache(time.Minute, WithMemoClock(clock))
+	var runs atomic.Int32
+	task := func(ctx context.Context) (any, error) {
+		return runs.Add(1), nil
+	}
+
+	m.Do(context.Background(), "key", task).Result()
+	clock.Advance(59 * time.Second)
+	if v, _ := m.Do(context.Background(), "key", task).Result(); v != int32(1) {
+		t.Fatalf("expected the cached result before expiry, got %v", v)
+	}
+
+	// The first access after expiry runs the task again
+	clock.Advance(time.Second)
+	if v, _ := m.Do(context.Background(), "key", task).Result(); v != int32(2) {
+		t.Fatalf("expected a fresh result after expiry, got %v", v)
+	}
+	if stats := m.Stats(); stats != (MemoStats{Hits: 1, Misses: 2, Expirations: 1}) {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestCache_StaleWhileRevalidate(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	m := NewCache(time.Minute, WithMemoClock(clock), WithStaleWhileRevalidate())
+	var runs atomic.Int32
+	release := make(chan struct{})
+	task := func(ctx context.Context) (any, error) {
+		n := runs.Add(1)
+		if n > 1 {
+			<-release
+		}
+		return n, nil
+	}
+
+	m.Do(context.Background(), "key", task).Result()
+	clock.Advance(time.Minute)
+
+	// Callers are served the stale value while a single refresh runs
+	for range 3 {
+		if v, _ := m.Do(context.Background(), "key", task).Result(); v != int32(1) {
+			t.Fatalf("expected the stale result, got %v", v)
+		}
+	}
+	close(release)
+
+	// The refreshed result replaces the stale one once it settles
+	for {
+		if v, _ := m.Do(context.Background(), "key", task).Result(); v == int32(2) {
+			break
+		}
+		runtime.Gosched()
+	}
+	if n := runs.Load(); n != 2 {
+		t.Fatalf("expected a single refresh, got %d executions", n)
+	}
+	if stats := m.Stats(); stats.Misses != 1 || stats.Stale < 3 || stats.Hits < 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestCache_Sweeper(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	m := NewCache(time.Minute, WithMemoClock(clock), WithSweepInterval(30*time.Second))
+	defer m.Close()
+	task := func(ctx context.Context) (any, error) {
+		return "value", nil
+	}
+	m.Do(context.Background(), "key", task).Result()
+
+	clock.Advance(30 * time.Second)
+	if m.Len() != 1 {
+		t.Fatalf("expected the result to be kept before expiry, got %d keys", m.Len())
+	}
+	clock.Advance(30 * time.Second)
+	if m.Len() != 0 {
+		t.Fatalf("expected the sweeper to evict the result, got %d keys", m.Len())
+	}
+
+	// Close stops the sweeper
+	m.Close()
+	if n := clock.Timers(); n != 0 {
+		t.Fatalf("expected no armed timers, got %d", n)
+	}
+}
+
+func TestMemo_MaxEntries(t *testing.T) {
+	var evicted []string
+	m := NewMemo(WithMaxEntries(2), WithOnEvict(func(key string, f *Future) {
+		v, _ := f.Result()
+		evicted = append(evicted, fmt.Sprintf("%s=%v", key, v))
+	}))
+	value := func(v string) func(context.Context) (any, error) {
+		return func(ctx context.Context) (any, error) {
+			return v, nil
+		}
+	}
+
+	m.Do(context.Background(), "a", value("1")).Result()
+	m.Do(context.Background(), "b", value("2")).Result()
+	m.Do(context.Background(), "a", value("ignored")).Result()
+
+	// b is the least recently used
+	m.Do(context.Background(), "c", value("3")).Result()
+	if !slices.Equal(evicted, []string{"b=2"}) {
+		t.Fatalf("expected b to be evicted, got %v", evicted)
+	}
+	if m.Len() != 2 {
+		t.Fatalf("expected 2 keys, got %d", m.Len())
+	}
+	if stats := m.Stats(); stats.Evictions != 1 {
+		t.Fatalf("expected 1 eviction, got %+v", stats)
+	}
+
+	// Forgetting a result reports it too
+	m.Forget("a")
+	if !slices.Equal(evicted, []string{"b=2", "a=1"}) {
+		t.Fatalf("expected a to be reported, got %v", evicted)
+	}
+}
+
+func TestMemo_MaxEntriesKeepsInFlight(t *testing.T) {
+	m := NewMemo(WithMaxEntries(1))
+	release := make(chan struct{})
+	blocked := func(ctx context.Context) (any, error) {
+		<-release
+		return "slow", nil
+	}
+	slow := m.Do(context.Background(), "slow", blocked)
+	fast := m.Do(context.Background(), "fast", func(ctx context.Context) (any, error) {
+		return "fast", nil
+	})
+	fast.Result()
+
+	// The settled result goes, the execution in flight stays
+	if m.Len() != 1 {
+		t.Fatalf("expected 1 key, got %d", m.Len())
+	}
+	close(release)
+	if v, err := slow.Result(); v != "slow" || err != nil {
+		t.Fatalf("expected the execution in flight to complete, got %v, %v", v, err)
+	}
+	if v, _ := m.Do(context.Background(), "slow", blocked).Result(); v != "slow" {
+		t.Fatalf("expected the result to be remembered, got %v", v)
+	}
+}
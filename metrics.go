@@ -0,0 +1,188 @@
+package A
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Outcome is how a future settled.
+type Outcome int
+
+const (
+	// Succeeded futures settled with a nil error.
+	Succeeded Outcome = iota
+	// Failed futures settled with an error returned by their task, or by
+	// the pool, limiter, or context that kept them from running.
+	Failed
+	// Aborted futures were settled by Abort or AbortWithError.
+	Aborted
+	// Panicked futures had a task that panicked.
+	Panicked
+	// TimedOut futures exceeded their WithTimeout deadline.
+	TimedOut
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Succeeded:
+		return "Succeeded"
+	case Failed:
+		return "Failed"
+	case Aborted:
+		return "Aborted"
+	case Panicked:
+		return "Panicked"
+	case TimedOut:
+		return "TimedOut"
+	}
+	return fmt.Sprintf("Outcome(%d)", int(o))
+}
+
+// Metrics receives counting and timing events for futures. Implementations
+// must be safe for concurrent use and should not block, since they are
+// called on the task's goroutine.
+type Metrics interface {
+	// FutureStarted is called when a task starts executing.
+	FutureStarted(name string)
+	// FutureSettled is called when a future settles, with how long its task
+	// executed and how long it waited to start. Both are zero for the parts
+	// of the lifecycle the future never reached.
+	FutureSettled(name string, outcome Outcome, execTime, waitTime time.Duration)
+}
+
+// WaitMetrics is implemented by Metrics that also want the wait time of
+// FutureSettled split in two. FutureWaited is called just before
+// FutureSettled.
+type WaitMetrics interface {
+	// FutureWaited is called when a future settles, with how long it sat
+	// idle, as a lazy future that nothing had asked to start, and how long
+	// it then took to start: pool queue, governor, limiter, and semaphore
+	// waits. The two add up to FutureSettled's waitTime.
+	FutureWaited(name string, idleTime, startDelay time.Duration)
+}
+
+// CompletionMetrics is implemented by Metrics that want the whole
+// Completion of a settled future rather than FutureSettled's summary.
+// FutureCompleted is called just after FutureSettled. The Completion does
+// not take the value of a WithSingleConsumer future.
+type CompletionMetrics interface {
+	// FutureCompleted is called when a future settles.
+	FutureCompleted(c Completion)
+}
+
+// WithMetrics reports the future's events to m, overriding the default set
+// with SetDefaultMetrics. A nil m disables reporting for the future.
+func WithMetrics(m Metrics) Option {
+	return func(f *Future) {
+		f.metrics = m
+	}
+}
+
+// defaultMetrics holds the Metrics applied to new futures.
+var defaultMetrics atomic.Pointer[metricsBox]
+
+// metricsBox lets a Metrics interface value be stored atomically.
+type metricsBox struct {
+	m Metrics
+}
+
+// SetDefaultMetrics reports the events of futures created afterwards to m.
+// A nil m disables the default.
+func SetDefaultMetrics(m Metrics) {
+	if m == nil {
+		defaultMetrics.Store(nil)
+		return
+	}
+	defaultMetrics.Store(&metricsBox{m: m})
+}
+
+// loadDefaultMetrics returns the default Metrics, or nil.
+func loadDefaultMetrics() Metrics {
+	if box := defaultMetrics.Load(); box != nil {
+		return box.m
+	}
+	return nil
+}
+
+// execWait splits a future's lifetime into execution and wait time.
+func execWait(createdAt, startedAt, settledAt time.Time) (execTime, waitTime time.Duration) {
+	if startedAt.IsZero() {
+		return 0, settledAt.Sub(createdAt)
+	}
+	return settledAt.Sub(startedAt), startedAt.Sub(createdAt)
+}
+
+// splitWait splits a future's wait time into idle time and start delay.
+// requestedAt is zero for futures that were asked to start on creation.
+func splitWait(createdAt, requestedAt, startedAt, settledAt time.Time) (idleTime, startDelay time.Duration) {
+	end := startedAt
+	if end.IsZero() {
+		end = settledAt
+	}
+	switch {
+	case requestedAt.IsZero():
+		requestedAt = createdAt
+	case requestedAt.After(end):
+		requestedAt = end
+	}
+	return requestedAt.Sub(createdAt), end.Sub(requestedAt)
+}
+
+// MemoryMetrics is a Metrics that keeps counters in memory, for tests and
+// simple status pages. The zero value is ready to use.
+type MemoryMetrics struct {
+	started  atomic.Uint64
+	settled  [TimedOut + 1]atomic.Uint64
+	execTime atomic.Int64
+	waitTime atomic.Int64
+	idleTime atomic.Int64
+}
+
+// FutureStarted implements Metrics.
+func (m *MemoryMetrics) FutureStarted(name string) {
+	m.started.Add(1)
+}
+
+// FutureWaited implements WaitMetrics.
+func (m *MemoryMetrics) FutureWaited(name string, idleTime, startDelay time.Duration) {
+	m.idleTime.Add(int64(idleTime))
+}
+
+// FutureSettled implements Metrics.
+func (m *MemoryMetrics) FutureSettled(name string, outcome Outcome, execTime, waitTime time.Duration) {
+	if outcome >= 0 && int(outcome) < len(m.settled) {
+		m.settled[outcome].Add(1)
+	}
+	m.execTime.Add(int64(execTime))
+	m.waitTime.Add(int64(waitTime))
+}
+
+// Started returns the number of tasks that started executing.
+func (m *MemoryMetrics) Started() uint64 {
+	return m.started.Load()
+}
+
+// Settled returns the number of futures that settled with outcome.
+func (m *MemoryMetrics) Settled(outcome Outcome) uint64 {
+	if outcome < 0 || int(outcome) >= len(m.settled) {
+		return 0
+	}
+	return m.settled[outcome].Load()
+}
+
+// ExecTime returns the total execution time of settled futures.
+func (m *MemoryMetrics) ExecTime() time.Duration {
+	return time.Duration(m.execTime.Load())
+}
+
+// WaitTime returns the total time settled futures waited to start.
+func (m *MemoryMetrics) WaitTime() time.Duration {
+	return time.Duration(m.waitTime.Load())
+}
+
+// IdleTime returns the part of WaitTime that lazy futures spent before
+// anything asked them to start.
+func (m *MemoryMetrics) IdleTime() time.Duration {
+	return time.Duration(m.idleTime.Load())
+}
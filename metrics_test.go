@@ -0,0 +1,192 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+func TestMetrics(t *testing.T) {
+	m := &MemoryMetrics{}
+	run := func(task func(context.Context) (any, error), opts ...Option) *Future {
+		f := NewFuture(context.Background(), task, append(opts, WithMetrics(m))...)
+		f.Result()
+		return f
+	}
+	run(func(ctx context.Context) (any, error) {
+		time.Sleep(10 * time.Millisecond)
+		return nil, nil
+	})
+	run(func(ctx context.Context) (any, error) {
+		return nil, errors.New("failed")
+	})
+	run(func(ctx context.Context) (any, error) {
+		panic("boom")
+	})
+	run(func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, WithTimeout(time.Millisecond))
+	lazy := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithLazy(), WithMetrics(m))
+	lazy.Abort()
+
+	// The aborted lazy future never started
+	if got := m.Started(); got != 4 {
+		t.Fatalf("expected 4 started tasks, got %d", got)
+	}
+	for _, outcome := range []Outcome{Succeeded, Failed, Panicked, TimedOut, Aborted} {
+		if got := m.Settled(outcome); got != 1 {
+			t.Fatalf("expected 1 %v future, got %d", outcome, got)
+		}
+	}
+	if m.ExecTime() < 10*time.Millisecond {
+		t.Fatalf("expected execution time to be recorded, got %v", m.ExecTime())
+	}
+}
+
+// gateLimiter is a Limiter that admits every event once it is closed.
+type gateLimiter chan struct{}
+
+func (l gateLimiter) Wait(ctx context.Context) error {
+	<-l
+	return nil
+}
+
+func TestMetrics_WaitBreakdown(t *testing.T) {
+	task := func(ctx context.Context) (any, error) {
+		return "ok", nil
+	}
+	blocked := func(release chan struct{}) func(ctx context.Context) (any, error) {
+		return func(ctx context.Context) (any, error) {
+			<-release
+			return nil, nil
+		}
+	}
+	// Each start holds the future back until release is called.
+	tests := map[string]struct {
+		start                func(opts ...Option) (f *Future, release func())
+		idleTime, startDelay time.Duration
+	}{
+		"lazy": {func(opts ...Option) (*Future, func()) {
+			f := NewFuture(context.Background(), task, append(opts, WithLazy())...)
+			return f, func() { f.Result() }
+		}, 2 * time.Second, 0},
+		"semaphore": {func(opts ...Option) (*Future, func()) {
+			sem := newTestSemaphore(1)
+			sem.Acquire(context.Background(), 1)
+			f := NewFuture(context.Background(), task, append(opts, WithSemaphore(sem, 1))...)
+			return f, func() { sem.Release(1) }
+		}, 0, 2 * time.Second},
+		"limiter": {func(opts ...Option) (*Future, func()) {
+			limiter := make(gateLimiter)
+			f := NewFuture(context.Background(), task, append(opts, WithLimiter(limiter))...)
+			return f, func() { close(limiter) }
+		}, 0, 2 * time.Second},
+		"governor": {func(opts ...Option) (*Future, func()) {
+			g := NewGovernor(1)
+			busy := make(chan struct{})
+			NewFuture(context.Background(), blocked(busy), WithGovernor(g))
+			f := NewFuture(context.Background(), task, append(opts, WithGovernor(g))...)
+			return f, func() { close(busy) }
+		}, 0, 2 * time.Second},
+		"pool queue": {func(opts ...Option) (*Future, func()) {
+			p := NewPool(1)
+			busy := make(chan struct{})
+			p.Submit(context.Background(), blocked(busy))
+			f := p.Submit(context.Background(), task, opts...)
+			return f, func() { close(busy) }
+		}, 0, 2 * time.Second},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			clock := fakeclock.New(time.Now())
+			m := &MemoryMetrics{}
+			f, release := test.start(WithClock(clock), WithMetrics(m))
+			clock.Advance(2 * time.Second)
+			release()
+
+			_, _, meta := f.ResultMeta()
+			if meta.IdleTime != test.idleTime || meta.StartDelay != test.startDelay {
+				t.Fatalf("expected %v idle and %v start delay, got %+v", test.idleTime, test.startDelay, meta)
+			}
+			if meta.WaitTime != meta.IdleTime+meta.StartDelay || meta.ExecTime != 0 {
+				t.Fatalf("expected the wait to add up, got %+v", meta)
+			}
+			if name == "pool queue" && meta.QueueWait != 2*time.Second {
+				t.Fatalf("expected the pool queue wait to be recorded, got %+v", meta)
+			}
+			if m.IdleTime() != test.idleTime || m.WaitTime() != 2*time.Second {
+				t.Fatalf("expected the metrics to split the wait, got %v idle of %v", m.IdleTime(), m.WaitTime())
+			}
+		})
+	}
+}
+
+// completionMetrics records the Completions it receives.
+type completionMetrics struct {
+	MemoryMetrics
+	completions chan Completion
+}
+
+func (m *completionMetrics) FutureCompleted(c Completion) {
+	m.completions <- c
+}
+
+func TestMetrics_Completion(t *testing.T) {
+	m := &completionMetrics{completions: make(chan Completion, 1)}
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "conn", nil
+	}, WithName("dial"), WithMetrics(m), WithSingleConsumer())
+
+	c := <-m.completions
+	if c.Future != f || c.Value != "conn" || !c.Succeeded() || c.Meta.Name != "dial" {
+		t.Fatalf("unexpected completion %+v", c)
+	}
+	if m.Settled(Succeeded) != 1 {
+		t.Fatalf("expected FutureSettled to be called as well")
+	}
+	// The metrics hook did not take the single consumer's value
+	if v, err := f.Result(); v != "conn" || err != nil {
+		t.Fatalf("expected conn, got %v, %v", v, err)
+	}
+}
+
+func TestSetDefaultMetrics(t *testing.T) {
+	m := &MemoryMetrics{}
+	SetDefaultMetrics(m)
+	defer SetDefaultMetrics(nil)
+
+	NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}).Result()
+	NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithMetrics(nil)).Result()
+	if got := m.Settled(Succeeded); got != 1 {
+		t.Fatalf("expected only the future without an override to be counted, got %d", got)
+	}
+}
+
+func BenchmarkFuture_Metrics(b *testing.B) {
+	task := func(ctx context.Context) (any, error) {
+		return nil, nil
+	}
+	b.Run("Nil", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewFuture(context.Background(), task).Result()
+		}
+	})
+	b.Run("Memory", func(b *testing.B) {
+		m := &MemoryMetrics{}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewFuture(context.Background(), task, WithMetrics(m)).Result()
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package A
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+)
+
+// Task is the function a future runs.
+type Task func(ctx context.Context) (any, error)
+
+// Middleware wraps a task, for cross-cutting concerns such as auth checks,
+// metrics, or fault injection. It may call next with the same or a derived
+// context, or short-circuit by returning without calling it.
+type Middleware func(next Task) Task
+
+// WithMiddleware wraps the future's task in mw. The first middleware is the
+// outermost. Middlewares set with SetDefaultMiddleware wrap these. Panics
+// anywhere in the chain are recovered like panics in the task.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(f *Future) {
+		if slices.ContainsFunc(mw, func(m Middleware) bool { return m == nil }) {
+			f.invalidOption("WithMiddleware", "nil middleware")
+			return
+		}
+		f.middleware = append(f.middleware, mw...)
+	}
+}
+
+// defaultMiddleware holds the middlewares applied to every new future.
+var defaultMiddleware atomic.Pointer[[]Middleware]
+
+// SetDefaultMiddleware wraps the task of every future created afterwards,
+// including pool and group members, in mw, outside any per-future
+// middleware. Calling it with no arguments removes the default.
+func SetDefaultMiddleware(mw ...Middleware) {
+	mw = slices.Clone(mw)
+	defaultMiddleware.Store(&mw)
+}
+
+// wrap applies the default and per-future middlewares to task.
+func wrap(task Task, middleware []Middleware) Task {
+	var all []Middleware
+	if defaults := defaultMiddleware.Load(); defaults != nil {
+		all = append(all, *defaults...)
+	}
+	all = append(all, middleware...)
+	for i := len(all) - 1; i >= 0; i-- {
+		task = all[i](task)
+	}
+	return task
+}
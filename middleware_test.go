@@ -0,0 +1,83 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestFuture_Middleware(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next Task) Task {
+			return func(ctx context.Context) (any, error) {
+				mu.Lock()
+				calls = append(calls, name)
+				mu.Unlock()
+				return next(ctx)
+			}
+		}
+	}
+	SetDefaultMiddleware(trace("default"))
+	defer SetDefaultMiddleware()
+
+	// Defaults wrap per-future middlewares, which run in declared order
+	NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		mu.Lock()
+		calls = append(calls, "task")
+		mu.Unlock()
+		return nil, nil
+	}, WithMiddleware(trace("first"), trace("second"))).Result()
+	if want := []string{"default", "first", "second", "task"}; !slices.Equal(calls, want) {
+		t.Fatalf("expected calls %v, got %v", want, calls)
+	}
+
+	// Pool submissions get the default middleware too
+	calls = nil
+	p := NewPool(1)
+	defer p.Shutdown(context.Background())
+	p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}).Result()
+	if want := []string{"default"}; !slices.Equal(calls, want) {
+		t.Fatalf("expected calls %v, got %v", want, calls)
+	}
+}
+
+func TestFuture_MiddlewareShortCircuit(t *testing.T) {
+	errDenied := errors.New("denied")
+	deny := func(next Task) Task {
+		return func(ctx context.Context) (any, error) {
+			return nil, errDenied
+		}
+	}
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		t.Error("task should not run when a middleware short-circuits")
+		return nil, nil
+	}, WithMiddleware(deny))
+	if _, err := future.Result(); !errors.Is(err, errDenied) {
+		t.Fatalf("expected the middleware error, got %v", err)
+	}
+}
+
+func TestFuture_MiddlewarePanic(t *testing.T) {
+	boom := func(next Task) Task {
+		return func(ctx context.Context) (any, error) {
+			panic("middleware boom")
+		}
+	}
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithMiddleware(boom))
+
+	// A panicking middleware is handled like a panicking task
+	if _, err := future.Result(); err == nil || err.Error() != "panic occurred: middleware boom" {
+		t.Fatalf("expected the panic to be recovered, got %v", err)
+	}
+	if !future.Panicked() {
+		t.Fatal("expected the future to report the panic")
+	}
+}
@@ -0,0 +1,144 @@
+package A
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestNilContext checks that every constructor treats a nil context as
+// context.Background instead of panicking.
+func TestNilContext(t *testing.T) {
+	var nilCtx context.Context
+	ok := func(context.Context) (any, error) {
+		return "ok", nil
+	}
+	expectOK := func(t *testing.T, f *Future) {
+		t.Helper()
+		if v, err := f.Result(); v != "ok" || err != nil {
+			t.Fatalf("expected ok, got %v, %v", v, err)
+		}
+	}
+
+	tests := map[string]func(t *testing.T){
+		"NewFuture": func(t *testing.T) {
+			f := NewFuture(nilCtx, ok)
+			expectOK(t, f)
+			if f.Context() == nil {
+				t.Fatalf("expected a non-nil context")
+			}
+		},
+		"NewFutureE": func(t *testing.T) {
+			f, err := NewFutureE(nilCtx, ok)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectOK(t, f)
+		},
+		"Pool.Submit": func(t *testing.T) {
+			p := NewPool(1)
+			defer p.Shutdown(context.Background())
+			expectOK(t, p.Submit(nilCtx, ok))
+			expectOK(t, p.SubmitAll(nilCtx, []func(context.Context) (any, error){ok})[0])
+			expectOK(t, p.SubmitKeyed(nilCtx, "key", ok))
+		},
+		"Group.Go": func(t *testing.T) {
+			g := NewGroup(nilCtx)
+			expectOK(t, g.Go(ok))
+			if err := g.Wait(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		},
+		"After": func(t *testing.T) {
+			if _, err := After(nilCtx, time.Millisecond).Result(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		},
+		"At": func(t *testing.T) {
+			expectOK(t, At(nilCtx, time.Now().Add(time.Millisecond), ok))
+		},
+		"NewStream": func(t *testing.T) {
+			s := NewStream(nilCtx, 0, func(ctx context.Context, emit func(any) error) error {
+				return emit("ok")
+			})
+			if v, err := s.Next(context.Background()); v != "ok" || err != nil {
+				t.Fatalf("expected ok, got %v, %v", v, err)
+			}
+		},
+		"NewRefreshable": func(t *testing.T) {
+			if v, err := NewRefreshable(nilCtx, ok).Result(); v != "ok" || err != nil {
+				t.Fatalf("expected ok, got %v, %v", v, err)
+			}
+		},
+		"NewAutoRefresh": func(t *testing.T) {
+			a := NewAutoRefresh(nilCtx, ok, time.Hour)
+			defer a.Close()
+			if v, err := a.Result(); v != "ok" || err != nil {
+				t.Fatalf("expected ok, got %v, %v", v, err)
+			}
+		},
+		"Memo.Do": func(t *testing.T) {
+			expectOK(t, NewMemo().Do(nilCtx, "key", ok))
+		},
+		"Batcher.Load": func(t *testing.T) {
+			b := NewBatcher(1, time.Millisecond, func(ctx context.Context, keys []string) (map[string]any, error) {
+				return map[string]any{keys[0]: "ok"}, nil
+			})
+			expectOK(t, b.Load(nilCtx, "key"))
+		},
+		"Serial.Next": func(t *testing.T) {
+			expectOK(t, NewSerial().Next(nilCtx, ok))
+		},
+		"NewPipeline": func(t *testing.T) {
+			p := NewPipeline(nilCtx).Stage("echo", func(ctx context.Context, in any) (any, error) {
+				return in, nil
+			})
+			if v, err := p.Run("ok").Result(); v != "ok" || err != nil {
+				t.Fatalf("expected ok, got %v, %v", v, err)
+			}
+		},
+		"Process": func(t *testing.T) {
+			in := make(chan string, 1)
+			in <- "ok"
+			close(in)
+			out := Process(nilCtx, in, 1, func(ctx context.Context, item string) (string, error) {
+				return item, nil
+			})
+			for f := range out {
+				expectOK(t, f)
+			}
+		},
+		"ChunkedMap": func(t *testing.T) {
+			fs := ChunkedMap(nilCtx, []string{"ok"}, 1, 1, func(ctx context.Context, chunk []string) (any, error) {
+				return chunk[0], nil
+			})
+			expectOK(t, fs[0])
+		},
+		"Race": func(t *testing.T) {
+			f := NewFuture(context.Background(), ok)
+			if winner, err := Race(nilCtx, f); winner != 0 || err != nil {
+				t.Fatalf("expected winner 0, got %d, %v", winner, err)
+			}
+			expectOK(t, f)
+		},
+		"StartThrottled": func(t *testing.T) {
+			f := NewFuture(context.Background(), ok, WithLazy())
+			if _, err := StartThrottled(nilCtx, []*Future{f}, time.Millisecond, 1).Result(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectOK(t, f)
+		},
+		"Scope": func(t *testing.T) {
+			err := Scope(nilCtx, func(s *ScopeHandle) error {
+				_, err := s.Future(ok).Result()
+				return err
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, test)
+	}
+}
@@ -0,0 +1,47 @@
+// Package oteltrace traces futures with OpenTelemetry. It is a separate
+// package so that the future package itself does not depend on
+// OpenTelemetry.
+package oteltrace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	A "github.com/ongniud/future"
+)
+
+// Tracer adapts an OpenTelemetry tracer to A.Tracer:
+//
+//	f := A.NewFuture(ctx, task, A.WithTracer(oteltrace.New(otel.Tracer("app"))))
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// New creates a Tracer that starts its spans with t.
+func New(t trace.Tracer) *Tracer {
+	return &Tracer{tracer: t}
+}
+
+// StartSpan starts a span named name as a child of the span in ctx. It
+// implements A.Tracer.
+func (t *Tracer) StartSpan(ctx context.Context, name string) (context.Context, A.Span) {
+	ctx, span := t.tracer.Start(ctx, name)
+	return ctx, Span{span}
+}
+
+// Span is an OpenTelemetry span started by a Tracer.
+type Span struct {
+	trace.Span
+}
+
+// End ends the span. A non-nil err is recorded on the span as an error
+// event and sets its status to codes.Error. It implements A.Span.
+func (s Span) End(err error) {
+	if err != nil {
+		s.Span.RecordError(err)
+		s.Span.SetStatus(codes.Error, err.Error())
+	}
+	s.Span.End()
+}
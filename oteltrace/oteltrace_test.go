@@ -0,0 +1,83 @@
+package oteltrace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	A "github.com/ongniud/future"
+)
+
+// recordingTracer records the spans it starts.
+type recordingTracer struct {
+	noop.Tracer
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	span := &recordingSpan{name: name}
+	t.spans = append(t.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// recordingSpan records what is done to it.
+type recordingSpan struct {
+	noop.Span
+	name   string
+	err    error
+	status codes.Code
+	ended  bool
+}
+
+func (s *recordingSpan) RecordError(err error, opts ...trace.EventOption) {
+	s.err = err
+}
+
+func (s *recordingSpan) SetStatus(code codes.Code, description string) {
+	s.status = code
+}
+
+func (s *recordingSpan) End(opts ...trace.SpanEndOption) {
+	s.ended = true
+}
+
+func TestTracer(t *testing.T) {
+	errBoom := errors.New("boom")
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+	}{
+		{name: "success", wantStatus: codes.Unset},
+		{name: "failure", err: errBoom, wantStatus: codes.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := &recordingTracer{}
+			var inTask trace.Span
+			f := A.NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+				inTask = trace.SpanFromContext(ctx)
+				return nil, tt.err
+			}, A.WithName("fetch"), A.WithTracer(New(tracer)))
+			f.Result()
+
+			if len(tracer.spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(tracer.spans))
+			}
+			span := tracer.spans[0]
+			if span.name != "fetch" || !span.ended {
+				t.Fatalf("expected ended span fetch, got %q, ended %v", span.name, span.ended)
+			}
+			if inTask != trace.Span(span) {
+				t.Fatalf("task context does not carry the span")
+			}
+			if !errors.Is(span.err, tt.err) || span.status != tt.wantStatus {
+				t.Fatalf("expected error %v and status %v, got %v and %v", tt.err, tt.wantStatus, span.err, span.status)
+			}
+		})
+	}
+}
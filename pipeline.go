@@ -0,0 +1,166 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrBudgetExhausted is returned when too little of a pipeline's time budget
+// remains to start a stage.
+var ErrBudgetExhausted = errors.New("pipeline budget exhausted")
+
+// StageError identifies the pipeline stage that failed.
+type StageError struct {
+	// Stage is the name of the stage.
+	Stage string
+	// Err is the error the stage returned.
+	Err error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("stage %q: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// StageOption defines functional options for a pipeline stage.
+type StageOption func(*stage)
+
+// WithStageTimeout bounds how long the stage may run.
+func WithStageTimeout(d time.Duration) StageOption {
+	return func(s *stage) {
+		s.timeout = d
+	}
+}
+
+// Pipeline runs a sequence of stages as one future, each stage getting the
+// previous stage's output. Build it with NewPipeline and Stage, then call
+// Run for each input.
+type Pipeline struct {
+	ctx      context.Context
+	stages   []stage
+	onStage  func(name string, elapsed time.Duration, err error)
+	budget   time.Duration
+	minStage time.Duration
+}
+
+// stage is a step of a Pipeline.
+type stage struct {
+	name    string
+	fn      func(ctx context.Context, in any) (any, error)
+	timeout time.Duration
+}
+
+// NewPipeline creates an empty pipeline whose runs are derived from ctx.
+func NewPipeline(ctx context.Context) *Pipeline {
+	ctx = orBackground(ctx)
+	return &Pipeline{ctx: ctx}
+}
+
+// Stage appends a stage named name that transforms the previous stage's
+// output with fn.
+func (p *Pipeline) Stage(name string, fn func(ctx context.Context, in any) (any, error), opts ...StageOption) *Pipeline {
+	s := stage{name: name, fn: fn}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	p.stages = append(p.stages, s)
+	return p
+}
+
+// OnStage calls fn after every stage of every run, with the stage's name,
+// how long it ran, and the error it returned.
+func (p *Pipeline) OnStage(fn func(name string, elapsed time.Duration, err error)) *Pipeline {
+	p.onStage = fn
+	return p
+}
+
+// Budget gives each run a total time budget, measured from Run. Each stage
+// runs with the budget that remains when it starts as its deadline, or its
+// own WithStageTimeout if that is shorter. If less than minPerStage remains
+// when a stage is due, the run fails fast with a *StageError wrapping
+// ErrBudgetExhausted and naming the stage that would have been starved.
+func (p *Pipeline) Budget(total, minPerStage time.Duration) *Pipeline {
+	p.budget, p.minStage = total, minPerStage
+	return p
+}
+
+// Run starts the stages on input and returns a future for the last stage's
+// output. All stages share the future's context, so aborting the future
+// stops whichever stage is running and skips the rest. A failing stage
+// ends the run with a *StageError naming it. Stage timeouts, budgets, and
+// the durations passed to OnStage follow the future's clock.
+func (p *Pipeline) Run(input any, opts ...Option) *Future {
+	run := &pipelineRun{
+		// Stages added after Run do not affect this run.
+		stages:   p.stages[:len(p.stages):len(p.stages)],
+		onStage:  p.onStage,
+		budget:   p.budget,
+		minStage: p.minStage,
+		input:    input,
+	}
+	f := newFuture(p.ctx, run.run, opts...)
+	run.f = f
+	if f.rejectInvalid() {
+		return f
+	}
+	if !f.lazy {
+		f.once.Do(f.start)
+	}
+	return f
+}
+
+// pipelineRun is a run of a Pipeline.
+type pipelineRun struct {
+	f        *Future
+	stages   []stage
+	onStage  func(name string, elapsed time.Duration, err error)
+	budget   time.Duration
+	minStage time.Duration
+	input    any
+}
+
+// run is the task of the run's future.
+func (r *pipelineRun) run(ctx context.Context) (any, error) {
+	deadline := r.f.createdAt.Add(r.budget)
+	value := r.input
+	for _, s := range r.stages {
+		if err := context.Cause(ctx); err != nil {
+			return nil, &StageError{Stage: s.name, Err: err}
+		}
+		timeout := s.timeout
+		if r.budget > 0 {
+			remaining := deadline.Sub(r.f.now())
+			if remaining <= 0 || remaining < r.minStage {
+				return nil, &StageError{Stage: s.name, Err: ErrBudgetExhausted}
+			}
+			if timeout <= 0 || remaining < timeout {
+				timeout = remaining
+			}
+		}
+		start := r.f.now()
+		out, err := r.stage(ctx, s, value, timeout)
+		if r.onStage != nil {
+			r.onStage(s.name, r.f.since(start), err)
+		}
+		if err != nil {
+			return nil, &StageError{Stage: s.name, Err: err}
+		}
+		value = out
+	}
+	return value, nil
+}
+
+// stage runs s on in, within timeout if it is positive.
+func (r *pipelineRun) stage(ctx context.Context, s stage, in any, timeout time.Duration) (any, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = r.f.withTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return s.fn(ctx, in)
+}
@@ -0,0 +1,126 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+func TestPipeline_Run(t *testing.T) {
+	var stages []string
+	p := NewPipeline(context.Background()).
+		Stage("fetch", func(ctx context.Context, in any) (any, error) {
+			return "raw:" + in.(string), nil
+		}).
+		Stage("transform", func(ctx context.Context, in any) (any, error) {
+			return strings.ToUpper(in.(string)), nil
+		}).
+		OnStage(func(name string, elapsed time.Duration, err error) {
+			stages = append(stages, name)
+		})
+
+	if v, err := p.Run("id").Result(); v != "RAW:ID" || err != nil {
+		t.Fatalf("expected RAW:ID, got %v, %v", v, err)
+	}
+	if !slices.Equal(stages, []string{"fetch", "transform"}) {
+		t.Fatalf("expected both stages to be reported, got %v", stages)
+	}
+}
+
+func TestPipeline_StageError(t *testing.T) {
+	errFailed := errors.New("failed")
+	ran := false
+	p := NewPipeline(context.Background()).
+		Stage("fetch", func(ctx context.Context, in any) (any, error) {
+			return nil, errFailed
+		}).
+		Stage("persist", func(ctx context.Context, in any) (any, error) {
+			ran = true
+			return in, nil
+		})
+
+	_, err := p.Run(nil).Result()
+	var stageErr *StageError
+	if !errors.As(err, &stageErr) || stageErr.Stage != "fetch" || !errors.Is(err, errFailed) {
+		t.Fatalf("expected a StageError for fetch, got %v", err)
+	}
+	if ran {
+		t.Fatal("expected later stages to be skipped")
+	}
+}
+
+func TestPipeline_Abort(t *testing.T) {
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	p := NewPipeline(context.Background()).
+		Stage("transform", func(ctx context.Context, in any) (any, error) {
+			close(started)
+			<-ctx.Done()
+			stopped <- ctx.Err()
+			return nil, ctx.Err()
+		}).
+		Stage("persist", func(ctx context.Context, in any) (any, error) {
+			t.Error("persist should not run")
+			return nil, nil
+		})
+
+	f := p.Run(nil)
+	<-started
+	if err := f.AbortAndWait(context.Background()); err != nil {
+		t.Fatalf("expected the pipeline to stop, got %v", err)
+	}
+	if err := <-stopped; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the active stage to be cancelled, got %v", err)
+	}
+}
+
+func TestPipeline_StageTimeout(t *testing.T) {
+	p := NewPipeline(context.Background()).
+		Stage("slow", func(ctx context.Context, in any) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, WithStageTimeout(time.Millisecond))
+
+	_, err := p.Run(nil).Result()
+	var stageErr *StageError
+	if !errors.As(err, &stageErr) || stageErr.Stage != "slow" || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the slow stage to time out, got %v", err)
+	}
+}
+
+func TestPipeline_Budget(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	var remaining []time.Duration
+	slow := func(d time.Duration) func(context.Context, any) (any, error) {
+		return func(ctx context.Context, in any) (any, error) {
+			deadline, _ := ctx.Deadline()
+			remaining = append(remaining, deadline.Sub(clock.Now()))
+			clock.Advance(d)
+			return in, nil
+		}
+	}
+	p := NewPipeline(context.Background()).
+		Budget(800*time.Millisecond, 100*time.Millisecond).
+		Stage("fetch", slow(300*time.Millisecond)).
+		Stage("transform", slow(350*time.Millisecond), WithStageTimeout(time.Second)).
+		Stage("enrich", slow(100*time.Millisecond), WithStageTimeout(100*time.Millisecond)).
+		Stage("persist", slow(0))
+
+	_, err := p.Run(nil, WithClock(clock)).Result()
+	var stageErr *StageError
+	if !errors.As(err, &stageErr) || stageErr.Stage != "persist" || !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("expected persist to be starved, got %v", err)
+	}
+
+	// Each stage's deadline is the budget left when it starts, or its own
+	// shorter timeout
+	want := []time.Duration{800 * time.Millisecond, 500 * time.Millisecond, 100 * time.Millisecond}
+	if !slices.Equal(remaining, want) {
+		t.Fatalf("expected deadlines %v, got %v", want, remaining)
+	}
+}
@@ -0,0 +1,651 @@
+package A
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrQueueFull is returned by futures rejected because the pool queue is full.
+	ErrQueueFull = errors.New("pool queue is full")
+	// ErrPoolClosed is returned by futures submitted after the pool was shut down.
+	ErrPoolClosed = errors.New("pool is closed")
+	// ErrInvalidPoolSize is returned when resizing a pool to fewer than one worker.
+	ErrInvalidPoolSize = errors.New("pool size must be at least one")
+)
+
+// RejectionPolicy decides what Submit does when the pool queue is full.
+type RejectionPolicy int
+
+const (
+	// Block makes Submit wait for queue space, respecting the submission context.
+	Block RejectionPolicy = iota
+	// Reject returns a future that has already failed with ErrQueueFull.
+	Reject
+	// CallerRuns executes the task inline on the submitting goroutine.
+	// Keyed submissions fall back to Block, since running inline would
+	// break per-key ordering.
+	CallerRuns
+)
+
+// PoolOption defines functional options for Pool.
+type PoolOption func(*Pool)
+
+// WithQueueSize bounds the number of tasks waiting for a worker.
+// A size of zero or less leaves the queue unbounded.
+func WithQueueSize(n int) PoolOption {
+	return func(p *Pool) {
+		p.queueSize = n
+	}
+}
+
+// WithRejectionPolicy sets what Submit does when the queue is full.
+func WithRejectionPolicy(policy RejectionPolicy) PoolOption {
+	return func(p *Pool) {
+		p.policy = policy
+	}
+}
+
+// WithMinWorkers sets the number of workers kept alive while the pool is
+// idle. When it is below the maximum, the pool spawns workers on demand and
+// retires them after WithIdleTimeout. It defaults to the maximum, which
+// keeps the pool at a fixed size.
+func WithMinWorkers(n int) PoolOption {
+	return func(p *Pool) {
+		p.min = n
+	}
+}
+
+// WithMaxWorkers sets the maximum number of workers, overriding the size
+// passed to NewPool.
+func WithMaxWorkers(n int) PoolOption {
+	return func(p *Pool) {
+		p.size = n
+	}
+}
+
+// WithIdleTimeout sets how long a worker above the minimum may stay idle
+// before it exits. It defaults to 30 seconds.
+func WithIdleTimeout(d time.Duration) PoolOption {
+	return func(p *Pool) {
+		p.idleTimeout = d
+	}
+}
+
+// WithDefaultTaskTimeout applies WithTimeout(d) to every submitted future
+// that does not set its own timeout.
+func WithDefaultTaskTimeout(d time.Duration) PoolOption {
+	return func(p *Pool) {
+		p.taskTimeout = d
+	}
+}
+
+// WithPoolMetrics applies WithMetrics(m) to every submitted future that
+// does not set its own metrics, such as a WindowStats tracking the pool's
+// throughput.
+func WithPoolMetrics(m Metrics) PoolOption {
+	return func(p *Pool) {
+		p.metrics = m
+	}
+}
+
+// WithPoolExecutor hands the pool's work to e instead of worker goroutines,
+// e.g. a testexec.Executor to step pool tasks by hand in tests. Each run
+// given to e executes at most one queued task, highest priority first, and
+// at most n runs are outstanding at once. Queue limits, rejection policies,
+// and key ordering apply as usual; WithMinWorkers and WithIdleTimeout have
+// no effect. Shutdown waits until e has called every run it was given.
+func WithPoolExecutor(e Executor) PoolOption {
+	return func(p *Pool) {
+		p.executor = e
+	}
+}
+
+// WithPoolPanicHandler registers fn to be called whenever a task submitted
+// to the pool panics, in addition to any per-future handling. It runs on the
+// worker goroutine before the future settles; a panic inside fn is recovered
+// and discarded so the worker survives.
+func WithPoolPanicHandler(fn func(info TaskInfo, recovered any, stack []byte)) PoolOption {
+	return func(p *Pool) {
+		p.panicHandler = fn
+	}
+}
+
+// WithPriority sets the priority of a future submitted to a Pool. Queued
+// futures with a higher priority are started first; futures with equal
+// priority start in submission order.
+func WithPriority(priority int) Option {
+	return func(f *Future) {
+		f.priority = priority
+	}
+}
+
+// TaskInfo describes a task submitted to a Pool.
+type TaskInfo struct {
+	// Key is the key passed to SubmitKeyed.
+	Key string
+	// Keyed reports whether the task was submitted with SubmitKeyed.
+	Keyed bool
+	// Priority is the priority the task was submitted with.
+	Priority int
+	// QueueWait is how long the task waited before a worker started it.
+	QueueWait time.Duration
+}
+
+// PoolStats is a point-in-time view of a Pool.
+type PoolStats struct {
+	// Workers is the number of live worker goroutines.
+	Workers int
+	// Running is the number of workers currently executing a task.
+	Running int
+	// Idle is the number of workers waiting for a task.
+	Idle int
+	// Queued is the number of tasks waiting for a worker, including keyed
+	// tasks waiting behind an earlier task with the same key.
+	Queued int
+	// Completed is the number of tasks that finished executing, including
+	// failed and panicked ones.
+	Completed uint64
+	// Panicked is the number of tasks that panicked.
+	Panicked uint64
+	// TotalQueueWait is the cumulative time tasks spent queued before a
+	// worker picked them up.
+	TotalQueueWait time.Duration
+	// AvgQueueWait is TotalQueueWait divided by the number of started tasks.
+	AvgQueueWait time.Duration
+}
+
+// Pool runs futures on up to a maximum number of worker goroutines. It keeps
+// that many workers by default; with WithMinWorkers below the maximum it
+// spawns workers on demand and retires those above the minimum after
+// WithIdleTimeout.
+type Pool struct {
+	queueSize    int
+	policy       RejectionPolicy
+	taskTimeout  time.Duration
+	panicHandler func(info TaskInfo, recovered any, stack []byte)
+	idleTimeout  time.Duration
+	metrics      Metrics
+	executor     Executor
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   taskQueue
+	seq     uint64
+	keys    map[string][]*Future
+	space   chan struct{}
+	closed  bool
+	min     int
+	size    int
+	workers int
+	wg      sync.WaitGroup
+
+	live      atomic.Int64
+	running   atomic.Int64
+	idle      atomic.Int64
+	queued    atomic.Int64
+	started   atomic.Uint64
+	completed atomic.Uint64
+	panicked  atomic.Uint64
+	queueWait atomic.Int64
+}
+
+// defaultIdleTimeout is how long surplus workers of an elastic pool idle
+// before exiting when WithIdleTimeout is not set.
+const defaultIdleTimeout = 30 * time.Second
+
+// NewPool creates a Pool with at most n workers.
+func NewPool(n int, opts ...PoolOption) *Pool {
+	p := &Pool{
+		keys:        make(map[string][]*Future),
+		space:       make(chan struct{}),
+		min:         -1,
+		size:        n,
+		idleTimeout: defaultIdleTimeout,
+	}
+	p.cond = sync.NewCond(&p.mu)
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.min < 0 || p.min > p.size {
+		p.min = p.size
+	}
+	p.mu.Lock()
+	p.spawn()
+	p.mu.Unlock()
+	stats.poolsOpen.Add(1)
+	return p
+}
+
+// Submit queues the task for execution on the pool and returns its Future.
+func (p *Pool) Submit(ctx context.Context, task func(context.Context) (any, error), opts ...Option) *Future {
+	ctx = orBackground(ctx)
+	f := p.newFuture(ctx, task, opts...)
+	if f.rejectInvalid() {
+		return f
+	}
+	f.once.Do(func() {
+		p.enqueue(ctx, f)
+	})
+	return f
+}
+
+// SubmitAll queues the tasks as a unit and returns their futures in input
+// order. Either every task is queued or, when the queue cannot hold them all,
+// the rejection policy applies to the whole batch. A batch larger than the
+// queue size can never fit and fails with ErrQueueFull unless the policy is
+// CallerRuns.
+func (p *Pool) SubmitAll(ctx context.Context, tasks []func(context.Context) (any, error), opts ...Option) []*Future {
+	ctx = orBackground(ctx)
+	fs := make([]*Future, len(tasks))
+	for i, task := range tasks {
+		fs[i] = p.newFuture(ctx, task, opts...)
+		if fs[i].rejectInvalid() {
+			// The options are shared, so every future is invalid alike.
+			continue
+		}
+		fs[i].once.Do(func() {})
+	}
+	if len(fs) > 0 && !fs[0].Ready() {
+		p.enqueue(ctx, fs...)
+	}
+	return fs
+}
+
+// SubmitKeyed queues the task like Submit, but tasks sharing a key run one
+// at a time in submission order. Tasks with different keys run in parallel.
+func (p *Pool) SubmitKeyed(ctx context.Context, key string, task func(context.Context) (any, error), opts ...Option) *Future {
+	ctx = orBackground(ctx)
+	f := p.newFuture(ctx, task, opts...)
+	f.key, f.keyed = key, true
+	if f.rejectInvalid() {
+		return f
+	}
+	f.once.Do(func() {
+		p.enqueue(ctx, f)
+	})
+	return f
+}
+
+// Stats returns the current pool statistics. It does not take the pool lock,
+// so it is cheap enough to call from a metrics scraper.
+func (p *Pool) Stats() PoolStats {
+	stats := PoolStats{
+		Workers:        int(p.live.Load()),
+		Running:        int(p.running.Load()),
+		Idle:           int(p.idle.Load()),
+		Queued:         int(p.queued.Load()),
+		Completed:      p.completed.Load(),
+		Panicked:       p.panicked.Load(),
+		TotalQueueWait: time.Duration(p.queueWait.Load()),
+	}
+	if started := p.started.Load(); started > 0 {
+		stats.AvgQueueWait = stats.TotalQueueWait / time.Duration(started)
+	}
+	return stats
+}
+
+// Resize changes the number of workers. Growing spawns workers immediately;
+// shrinking lets surplus workers exit once they finish their current task,
+// so running tasks are never interrupted. For an elastic pool, n is the new
+// maximum and the minimum is lowered to n if needed.
+func (p *Pool) Resize(n int) error {
+	if n < 1 {
+		return ErrInvalidPoolSize
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	if p.min == p.size || p.min > n {
+		p.min = n
+	}
+	p.size = n
+	p.spawn()
+	// Wake idle workers so surplus ones can exit.
+	p.cond.Broadcast()
+	return nil
+}
+
+// Shutdown stops accepting new tasks and waits for queued and running tasks
+// to finish, or for ctx to be done.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		p.cond.Broadcast()
+		p.freeSpace()
+		stats.poolsOpen.Add(-1)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// newFuture creates an unstarted future with the pool defaults applied.
+func (p *Pool) newFuture(ctx context.Context, task func(context.Context) (any, error), opts ...Option) *Future {
+	if p.taskTimeout > 0 {
+		opts = append([]Option{WithTimeout(p.taskTimeout)}, opts...)
+	}
+	if p.metrics != nil {
+		opts = append([]Option{WithMetrics(p.metrics)}, opts...)
+	}
+	f := newFuture(ctx, task, opts...)
+	if f.lazy {
+		f.invalidOption("WithLazy", "pool tasks are always queued on submission")
+	}
+	if p.panicHandler != nil {
+		f.onPanic = func(recovered any, stack []byte) {
+			defer func() {
+				recover()
+			}()
+			p.panicHandler(TaskInfo{
+				Key:       f.key,
+				Keyed:     f.keyed,
+				Priority:  f.priority,
+				QueueWait: f.QueueWait(),
+			}, recovered, stack)
+		}
+	}
+	return f
+}
+
+// enqueue adds the futures to the queue as a unit, applying the rejection
+// policy when they do not all fit.
+func (p *Pool) enqueue(ctx context.Context, fs ...*Future) {
+	p.mu.Lock()
+	for {
+		if p.closed {
+			p.mu.Unlock()
+			settleAll(fs, nil, ErrPoolClosed)
+			return
+		}
+		if p.queueSize <= 0 || int(p.queued.Load())+len(fs) <= p.queueSize {
+			for _, f := range fs {
+				p.push(f)
+			}
+			p.mu.Unlock()
+			// A future aborted while queued gives its slot back at once
+			// instead of holding it until a worker discards it.
+			for _, f := range fs {
+				f.whenDone(func() {
+					p.mu.Lock()
+					p.dequeue(f)
+					p.mu.Unlock()
+				})
+			}
+			return
+		}
+		// Only SubmitKeyed enqueues keyed futures, one at a time.
+		if p.policy == CallerRuns && !fs[0].keyed {
+			p.mu.Unlock()
+			for _, f := range fs {
+				p.execute(f, 0)
+			}
+			return
+		}
+		if p.policy == Reject || len(fs) > p.queueSize {
+			p.mu.Unlock()
+			settleAll(fs, nil, ErrQueueFull)
+			return
+		}
+		space := p.space
+		p.mu.Unlock()
+		select {
+		case <-space:
+		case <-ctx.Done():
+			settleAll(fs, nil, ctx.Err())
+			return
+		}
+		p.mu.Lock()
+	}
+}
+
+// worker runs queued futures until the pool is closed and drained.
+func (p *Pool) worker() {
+	defer p.wg.Done()
+	for {
+		p.mu.Lock()
+		p.idle.Add(1)
+		expired := p.waitForTask()
+		p.idle.Add(-1)
+		if expired || len(p.queue) == 0 || p.workers > p.size {
+			p.workers--
+			p.live.Add(-1)
+			p.mu.Unlock()
+			return
+		}
+		f := heap.Pop(&p.queue).(*Future)
+		live := f.queued
+		p.dequeue(f)
+		p.mu.Unlock()
+
+		if live {
+			p.running.Add(1)
+			p.execute(f, f.since(f.enqueuedAt))
+			p.running.Add(-1)
+		}
+		if f.keyed {
+			p.mu.Lock()
+			p.advance(f.key)
+			p.mu.Unlock()
+		}
+	}
+}
+
+// waitForTask blocks until there is a task to run, the pool is closed, or the
+// worker is surplus. It reports true if the worker above the minimum idled
+// past the idle timeout. It must be called with p.mu held.
+func (p *Pool) waitForTask() bool {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	idleSince := time.Now()
+	for len(p.queue) == 0 && !p.closed && p.workers <= p.size {
+		if p.workers > p.min && p.idleTimeout > 0 {
+			if time.Since(idleSince) >= p.idleTimeout {
+				return true
+			}
+			if timer == nil {
+				timer = time.AfterFunc(p.idleTimeout, func() {
+					p.mu.Lock()
+					p.cond.Broadcast()
+					p.mu.Unlock()
+				})
+			}
+		}
+		p.cond.Wait()
+	}
+	return false
+}
+
+// execute runs f on the calling goroutine, either a worker or a submitter
+// under CallerRuns, and records its statistics.
+func (p *Pool) execute(f *Future, wait time.Duration) {
+	if f.Ready() {
+		// Aborted while queued.
+		return
+	}
+	f.setQueueWait(wait)
+	p.started.Add(1)
+	p.queueWait.Add(int64(wait))
+
+	f.run()
+
+	p.completed.Add(1)
+	if f.Panicked() {
+		p.panicked.Add(1)
+	}
+}
+
+// push adds an admitted future to the queue. Keyed futures wait in their
+// key's backlog while an earlier future with the same key is outstanding.
+// It must be called with p.mu held.
+func (p *Pool) push(f *Future) {
+	p.seq++
+	f.seq = p.seq
+	f.enqueuedAt = f.now()
+	f.queued = true
+	p.queued.Add(1)
+	if f.keyed {
+		if backlog, ok := p.keys[f.key]; ok {
+			p.keys[f.key] = append(backlog, f)
+			return
+		}
+		p.keys[f.key] = nil
+	}
+	p.schedule(f)
+}
+
+// dequeue releases the queue slot held by f, if it still holds one. It must
+// be called with p.mu held.
+func (p *Pool) dequeue(f *Future) {
+	if !f.queued {
+		return
+	}
+	f.queued = false
+	p.queued.Add(-1)
+	p.freeSpace()
+}
+
+// advance moves the next live future in the key's backlog to the queue,
+// dropping futures that were aborted while they waited. It must be called
+// with p.mu held.
+func (p *Pool) advance(key string) {
+	backlog := p.keys[key]
+	for len(backlog) > 0 {
+		next := backlog[0]
+		backlog[0] = nil
+		backlog = backlog[1:]
+		if !next.queued {
+			continue
+		}
+		p.keys[key] = backlog
+		p.schedule(next)
+		return
+	}
+	delete(p.keys, key)
+}
+
+// schedule makes f available to workers, spawning a worker when queued
+// tasks outnumber idle workers and the pool is below its maximum size. It
+// must be called with p.mu held.
+func (p *Pool) schedule(f *Future) {
+	heap.Push(&p.queue, f)
+	if len(p.queue) > int(p.idle.Load()) && p.workers < p.size {
+		p.startWorker()
+	}
+	p.cond.Signal()
+}
+
+// spawn starts workers until the pool reaches its minimum size. With an
+// executor, whose workers run a single task each, it starts one per queued
+// task instead. It must be called with p.mu held.
+func (p *Pool) spawn() {
+	if p.executor != nil {
+		for p.workers < len(p.queue) && p.workers < p.size {
+			p.startWorker()
+		}
+		return
+	}
+	for p.workers < p.min {
+		p.startWorker()
+	}
+}
+
+// startWorker starts one worker. It must be called with p.mu held.
+func (p *Pool) startWorker() {
+	p.workers++
+	p.live.Add(1)
+	p.wg.Add(1)
+	if p.executor != nil {
+		p.executor.Execute(p.step)
+		return
+	}
+	go p.worker()
+}
+
+// step is a worker run by the pool's executor. It runs the next queued
+// future, if any, and exits rather than wait for more, since an executor may
+// run it on the test's own goroutine.
+func (p *Pool) step() {
+	defer p.wg.Done()
+	p.mu.Lock()
+	if len(p.queue) > 0 {
+		f := heap.Pop(&p.queue).(*Future)
+		live := f.queued
+		p.dequeue(f)
+		p.mu.Unlock()
+		if live {
+			p.running.Add(1)
+			p.execute(f, f.since(f.enqueuedAt))
+			p.running.Add(-1)
+		}
+		p.mu.Lock()
+		if f.keyed {
+			p.advance(f.key)
+		}
+	}
+	p.workers--
+	p.live.Add(-1)
+	p.spawn()
+	p.mu.Unlock()
+}
+
+// freeSpace wakes submitters blocked on a full queue. It must be called with
+// p.mu held.
+func (p *Pool) freeSpace() {
+	close(p.space)
+	p.space = make(chan struct{})
+}
+
+// taskQueue is a heap of queued futures ordered by priority, then by
+// submission order.
+type taskQueue []*Future
+
+func (q taskQueue) Len() int { return len(q) }
+
+func (q taskQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q taskQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *taskQueue) Push(x any) { *q = append(*q, x.(*Future)) }
+
+func (q *taskQueue) Pop() any {
+	old := *q
+	n := len(old)
+	f := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return f
+}
+
+// settleAll settles every future with the same result.
+func settleAll(fs []*Future, item any, err error) {
+	for _, f := range fs {
+		f.settle(item, err)
+	}
+}
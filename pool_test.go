@@ -0,0 +1,785 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// saturate fills a single-worker pool with a running task and a full queue.
+// Closing the returned channel lets the running and queued tasks finish.
+func saturate(t *testing.T, p *Pool) chan struct{} {
+	t.Helper()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-release
+		return "running", nil
+	})
+	<-started
+	p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		<-release
+		return "queued", nil
+	})
+	if got := p.Stats().Queued; got != 1 {
+		t.Fatalf("expected 1 queued task, got %d", got)
+	}
+	return release
+}
+
+func TestPool_Submit(t *testing.T) {
+	p := NewPool(2)
+	defer p.Shutdown(context.Background())
+
+	future := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		return "success", nil
+	})
+	result, err := future.Result()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != "success" {
+		t.Fatalf("expected result 'success', got %v", result)
+	}
+}
+
+func TestPool_Reject(t *testing.T) {
+	p := NewPool(1, WithQueueSize(1), WithRejectionPolicy(Reject))
+	defer p.Shutdown(context.Background())
+	release := saturate(t, p)
+	defer close(release)
+
+	future := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		t.Error("rejected task should not run")
+		return nil, nil
+	})
+	if !future.Ready() {
+		t.Fatalf("expected rejected future to be settled immediately")
+	}
+	if _, err := future.Result(); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+}
+
+func TestPool_AbortQueuedFreesSpace(t *testing.T) {
+	p := NewPool(1, WithQueueSize(1), WithRejectionPolicy(Reject))
+	defer p.Shutdown(context.Background())
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	<-started
+	queued := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		t.Error("aborted task should not run")
+		return nil, nil
+	})
+
+	// Aborting a queued future gives its slot back before a worker reaches it
+	queued.Abort()
+	if got := p.Stats().Queued; got != 0 {
+		t.Fatalf("expected empty queue after abort, got %d", got)
+	}
+	next := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		return "next", nil
+	})
+	if next.Ready() {
+		_, err := next.Result()
+		t.Fatalf("expected the freed slot to admit the next task, got %v", err)
+	}
+	if got := p.Stats().Queued; got != 1 {
+		t.Fatalf("expected 1 queued task, got %d", got)
+	}
+}
+
+func TestPool_Block(t *testing.T) {
+	p := NewPool(1, WithQueueSize(1), WithRejectionPolicy(Block))
+	defer p.Shutdown(context.Background())
+	release := saturate(t, p)
+
+	submitted := make(chan *Future)
+	go func() {
+		submitted <- p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+			return "unblocked", nil
+		})
+	}()
+
+	// Submit should block while the queue is full
+	select {
+	case <-submitted:
+		t.Fatal("expected Submit to block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	future := <-submitted
+	result, err := future.Result()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != "unblocked" {
+		t.Fatalf("expected result 'unblocked', got %v", result)
+	}
+}
+
+func TestPool_BlockContext(t *testing.T) {
+	p := NewPool(1, WithQueueSize(1), WithRejectionPolicy(Block))
+	defer p.Shutdown(context.Background())
+	release := saturate(t, p)
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	future := p.Submit(ctx, func(ctx context.Context) (any, error) {
+		t.Error("task should not run after its submission context expired")
+		return nil, nil
+	})
+	if _, err := future.Result(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestPool_CallerRuns(t *testing.T) {
+	p := NewPool(1, WithQueueSize(1), WithRejectionPolicy(CallerRuns))
+	defer p.Shutdown(context.Background())
+	release := saturate(t, p)
+	defer close(release)
+
+	before := p.Stats()
+	caller := make(chan struct{})
+	future := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		close(caller)
+		return "inline", nil
+	})
+
+	// The task must have run before Submit returned
+	select {
+	case <-caller:
+	default:
+		t.Fatal("expected task to run on the submitting goroutine")
+	}
+	result, err := future.Result()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != "inline" {
+		t.Fatalf("expected result 'inline', got %v", result)
+	}
+
+	// Inline tasks are accounted like queued ones, with no queue wait
+	p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		panic("boom")
+	})
+	stats := p.Stats()
+	if stats.Completed != 2 || stats.Panicked != 1 {
+		t.Fatalf("expected 2 completed and 1 panicked, got %+v", stats)
+	}
+	if stats.TotalQueueWait != before.TotalQueueWait || future.QueueWait() != 0 {
+		t.Fatalf("expected no queue wait for inline tasks, got %+v", stats)
+	}
+}
+
+func TestPool_Shutdown(t *testing.T) {
+	p := NewPool(1)
+	queued := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		time.Sleep(50 * time.Millisecond)
+		return "drained", nil
+	})
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Tasks queued before Shutdown still run
+	if result, _ := queued.Result(); result != "drained" {
+		t.Fatalf("expected result 'drained', got %v", result)
+	}
+
+	future := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	})
+	if _, err := future.Result(); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got %v", err)
+	}
+}
+
+func TestPool_Stats(t *testing.T) {
+	p := NewPool(2)
+	defer p.Shutdown(context.Background())
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	for i := 0; i < 3; i++ {
+		p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+			started <- struct{}{}
+			<-release
+			return nil, nil
+		})
+	}
+	<-started
+	<-started
+
+	stats := p.Stats()
+	if stats.Running != 2 || stats.Idle != 0 || stats.Queued != 1 {
+		t.Fatalf("expected 2 running, 0 idle, 1 queued, got %+v", stats)
+	}
+
+	close(release)
+	panicky := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		panic("boom")
+	})
+	panicky.Result()
+
+	// Completed is recorded after the future settles, so poll briefly
+	deadline := time.Now().Add(time.Second)
+	for p.Stats().Completed < 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stats = p.Stats()
+	if stats.Completed != 4 || stats.Panicked != 1 {
+		t.Fatalf("expected 4 completed and 1 panicked, got %+v", stats)
+	}
+	if stats.TotalQueueWait <= 0 || stats.AvgQueueWait <= 0 {
+		t.Fatalf("expected queue wait to be recorded, got %+v", stats)
+	}
+}
+
+func TestPool_QueueWait(t *testing.T) {
+	p := NewPool(1)
+	defer p.Shutdown(context.Background())
+
+	blocker := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		time.Sleep(50 * time.Millisecond)
+		return nil, nil
+	})
+	queued := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	})
+	queued.Result()
+
+	if wait := queued.QueueWait(); wait < 40*time.Millisecond {
+		t.Fatalf("expected queued future to wait behind the blocker, waited %v", wait)
+	}
+	if wait := blocker.QueueWait(); wait >= 40*time.Millisecond {
+		t.Fatalf("expected blocker to start immediately, waited %v", wait)
+	}
+	if wait := NewFuture(context.Background(), func(ctx context.Context) (any, error) { return nil, nil }).QueueWait(); wait != 0 {
+		t.Fatalf("expected no queue wait outside a pool, got %v", wait)
+	}
+}
+
+func TestPool_Resize(t *testing.T) {
+	p := NewPool(4)
+	defer p.Shutdown(context.Background())
+
+	// Keep the pool under continuous load while resizing
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+				time.Sleep(time.Millisecond)
+				return nil, nil
+			}).Result()
+		}
+	}()
+
+	converge := func(target int) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for p.Stats().Workers != target && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if got := p.Stats().Workers; got != target {
+			t.Fatalf("expected %d workers, got %d", target, got)
+		}
+	}
+
+	if err := p.Resize(1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	converge(1)
+	if err := p.Resize(6); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	converge(6)
+}
+
+func TestPool_ResizeRunningTask(t *testing.T) {
+	p := NewPool(2)
+	defer p.Shutdown(context.Background())
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	future := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-release
+		return "finished", ctx.Err()
+	})
+	<-started
+
+	// Shrinking must not interrupt the running task
+	if err := p.Resize(1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	close(release)
+	result, err := future.Result()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != "finished" {
+		t.Fatalf("expected result 'finished', got %v", result)
+	}
+}
+
+func TestPool_ResizeInvalid(t *testing.T) {
+	p := NewPool(1)
+	if err := p.Resize(0); !errors.Is(err, ErrInvalidPoolSize) {
+		t.Fatalf("expected ErrInvalidPoolSize, got %v", err)
+	}
+	p.Shutdown(context.Background())
+	if err := p.Resize(2); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got %v", err)
+	}
+}
+
+func TestPool_SubmitKeyedOrder(t *testing.T) {
+	p := NewPool(4)
+	defer p.Shutdown(context.Background())
+
+	keys := []string{"a", "b", "c"}
+	var mu sync.Mutex
+	order := make(map[string][]int)
+	var futures []*Future
+	for i := 0; i < 90; i++ {
+		key, seq := keys[i%len(keys)], i
+		futures = append(futures, p.SubmitKeyed(context.Background(), key, func(ctx context.Context) (any, error) {
+			mu.Lock()
+			order[key] = append(order[key], seq)
+			mu.Unlock()
+			return nil, nil
+		}))
+	}
+	for _, f := range futures {
+		f.Result()
+	}
+
+	for _, key := range keys {
+		seqs := order[key]
+		if len(seqs) != 30 {
+			t.Fatalf("expected 30 tasks for key %q, got %d", key, len(seqs))
+		}
+		for i := 1; i < len(seqs); i++ {
+			if seqs[i] < seqs[i-1] {
+				t.Fatalf("key %q ran out of order: %v", key, seqs)
+			}
+		}
+	}
+}
+
+func TestPool_SubmitKeyedParallel(t *testing.T) {
+	p := NewPool(2)
+	defer p.Shutdown(context.Background())
+
+	// Tasks with different keys must run at the same time
+	var wg sync.WaitGroup
+	wg.Add(2)
+	both := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(both)
+	}()
+	task := func(ctx context.Context) (any, error) {
+		wg.Done()
+		<-both
+		return nil, nil
+	}
+	a := p.SubmitKeyed(context.Background(), "a", task)
+	b := p.SubmitKeyed(context.Background(), "b", task)
+
+	select {
+	case <-b.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected different keys to run in parallel")
+	}
+	a.Result()
+}
+
+func TestPool_SubmitKeyedAbort(t *testing.T) {
+	p := NewPool(2)
+	defer p.Shutdown(context.Background())
+
+	release := make(chan struct{})
+	first := p.SubmitKeyed(context.Background(), "a", func(ctx context.Context) (any, error) {
+		<-release
+		return "first", nil
+	})
+	second := p.SubmitKeyed(context.Background(), "a", func(ctx context.Context) (any, error) {
+		t.Error("aborted task should not run")
+		return nil, nil
+	})
+	third := p.SubmitKeyed(context.Background(), "a", func(ctx context.Context) (any, error) {
+		return "third", nil
+	})
+
+	// Aborting a queued keyed future must not stall the rest of its key
+	second.Abort()
+	close(release)
+	first.Result()
+	result, err := third.Result()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != "third" {
+		t.Fatalf("expected result 'third', got %v", result)
+	}
+	if got := p.Stats().Queued; got != 0 {
+		t.Fatalf("expected empty queue, got %d", got)
+	}
+}
+
+func TestPool_Priority(t *testing.T) {
+	p := NewPool(1)
+	defer p.Shutdown(context.Background())
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	<-started
+
+	// Queue tasks while the only worker is busy
+	var mu sync.Mutex
+	var order []int
+	var futures []*Future
+	for _, priority := range []int{1, 5, 1, 10, 5} {
+		priority := priority
+		futures = append(futures, p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+			mu.Lock()
+			order = append(order, priority)
+			mu.Unlock()
+			return nil, nil
+		}, WithPriority(priority)))
+	}
+	close(release)
+	for _, f := range futures {
+		f.Result()
+	}
+
+	expected := []int{10, 5, 5, 1, 1}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected order %v, got %v", expected, order)
+		}
+	}
+	if got := futures[3].Priority(); got != 10 {
+		t.Fatalf("expected priority 10, got %d", got)
+	}
+}
+
+func TestPool_DefaultTaskTimeout(t *testing.T) {
+	p := NewPool(1, WithDefaultTaskTimeout(20*time.Millisecond))
+	defer p.Shutdown(context.Background())
+
+	release := make(chan struct{})
+	future := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		// Ignore cancellation entirely
+		<-release
+		return "too late", nil
+	})
+
+	// The future settles when the timeout fires, not when the task returns
+	select {
+	case <-future.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected future to settle when the timeout fired")
+	}
+	if _, err := future.Result(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// The worker stays occupied until the task actually returns
+	if got := p.Stats().Running; got != 1 {
+		t.Fatalf("expected the worker to still be running, got %d", got)
+	}
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for p.Stats().Running != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stats := p.Stats()
+	if stats.Running != 0 || stats.Workers != 1 {
+		t.Fatalf("expected the worker to be released, got %+v", stats)
+	}
+	if result, _ := future.Result(); result != nil {
+		t.Fatalf("expected the late result to be dropped, got %v", result)
+	}
+}
+
+func TestPool_DefaultTaskTimeoutFromStart(t *testing.T) {
+	p := NewPool(1, WithDefaultTaskTimeout(30*time.Millisecond))
+	defer p.Shutdown(context.Background())
+
+	p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		time.Sleep(20 * time.Millisecond)
+		return nil, nil
+	})
+	queued := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		time.Sleep(20 * time.Millisecond)
+		return "started late", nil
+	})
+
+	// Queue wait does not count towards the timeout
+	result, err := queued.Result()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != "started late" {
+		t.Fatalf("expected result 'started late', got %v", result)
+	}
+}
+
+func TestPool_DefaultTaskTimeoutOverride(t *testing.T) {
+	p := NewPool(1, WithDefaultTaskTimeout(10*time.Millisecond))
+	defer p.Shutdown(context.Background())
+
+	future := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+			return "overridden", nil
+		}
+	}, WithTimeout(time.Second))
+	result, err := future.Result()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != "overridden" {
+		t.Fatalf("expected result 'overridden', got %v", result)
+	}
+}
+
+func TestPool_SubmitAll(t *testing.T) {
+	p := NewPool(4)
+	defer p.Shutdown(context.Background())
+
+	var tasks []func(context.Context) (any, error)
+	for i := 0; i < 10; i++ {
+		i := i
+		tasks = append(tasks, func(ctx context.Context) (any, error) {
+			return i, nil
+		})
+	}
+	futures := p.SubmitAll(context.Background(), tasks)
+
+	// Futures are returned in input order
+	for i, f := range futures {
+		result, err := f.Result()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result != i {
+			t.Fatalf("expected result %d, got %v", i, result)
+		}
+	}
+}
+
+func TestPool_SubmitAllReject(t *testing.T) {
+	p := NewPool(1, WithQueueSize(3), WithRejectionPolicy(Reject))
+	defer p.Shutdown(context.Background())
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	<-started
+	p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	})
+
+	task := func(ctx context.Context) (any, error) {
+		return nil, nil
+	}
+
+	// Three more tasks do not fit, so none of them are queued
+	rejected := p.SubmitAll(context.Background(), []func(context.Context) (any, error){task, task, task})
+	for _, f := range rejected {
+		if _, err := f.Result(); !errors.Is(err, ErrQueueFull) {
+			t.Fatalf("expected ErrQueueFull, got %v", err)
+		}
+	}
+	if got := p.Stats().Queued; got != 1 {
+		t.Fatalf("expected the rejected batch to leave the queue untouched, got %d queued", got)
+	}
+
+	// Two more tasks fit, so both are queued
+	accepted := p.SubmitAll(context.Background(), []func(context.Context) (any, error){task, task})
+	for _, f := range accepted {
+		if f.Ready() {
+			t.Fatal("expected the batch to be queued")
+		}
+	}
+	if got := p.Stats().Queued; got != 3 {
+		t.Fatalf("expected 3 queued tasks, got %d", got)
+	}
+}
+
+func TestPool_SubmitAllTooLarge(t *testing.T) {
+	p := NewPool(1, WithQueueSize(2), WithRejectionPolicy(Block))
+	defer p.Shutdown(context.Background())
+
+	task := func(ctx context.Context) (any, error) {
+		return nil, nil
+	}
+
+	// A batch larger than the queue can never fit, even when blocking
+	futures := p.SubmitAll(context.Background(), []func(context.Context) (any, error){task, task, task})
+	for _, f := range futures {
+		if _, err := f.Result(); !errors.Is(err, ErrQueueFull) {
+			t.Fatalf("expected ErrQueueFull, got %v", err)
+		}
+	}
+}
+
+func TestPool_PanicIsolation(t *testing.T) {
+	var mu sync.Mutex
+	var handled int
+	p := NewPool(4, WithPoolPanicHandler(func(info TaskInfo, recovered any, stack []byte) {
+		if recovered != "boom" {
+			t.Errorf("expected recovered value 'boom', got %v", recovered)
+		}
+		if len(stack) == 0 {
+			t.Error("expected a stack trace")
+		}
+		mu.Lock()
+		handled++
+		mu.Unlock()
+		// A panicking handler must not take the worker down either
+		panic("handler failed")
+	}))
+	defer p.Shutdown(context.Background())
+
+	var futures []*Future
+	for i := 0; i < 200; i++ {
+		futures = append(futures, p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+			panic("boom")
+		}))
+		if got := p.Stats().Workers; got != 4 {
+			t.Fatalf("expected 4 workers, got %d", got)
+		}
+	}
+	for _, f := range futures {
+		if _, err := f.Result(); err == nil {
+			t.Fatal("expected panic error, got nil")
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for p.Stats().Panicked < 200 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stats := p.Stats()
+	if stats.Workers != 4 || stats.Panicked != 200 {
+		t.Fatalf("expected 4 workers and 200 panics, got %+v", stats)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if handled != 200 {
+		t.Fatalf("expected the handler to run 200 times, got %d", handled)
+	}
+
+	// The pool keeps serving tasks after the panics
+	result, err := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		return "alive", nil
+	}).Result()
+	if err != nil || result != "alive" {
+		t.Fatalf("expected result 'alive', got %v, %v", result, err)
+	}
+}
+
+func TestPool_Elastic(t *testing.T) {
+	p := NewPool(4, WithMinWorkers(1), WithIdleTimeout(20*time.Millisecond))
+	defer p.Shutdown(context.Background())
+
+	if got := p.Stats().Workers; got != 1 {
+		t.Fatalf("expected the pool to start with 1 worker, got %d", got)
+	}
+
+	// A burst spawns workers on demand, up to the maximum
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(4)
+	var futures []*Future
+	for i := 0; i < 6; i++ {
+		futures = append(futures, p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+			started.Done()
+			<-release
+			return nil, nil
+		}))
+	}
+	started.Wait()
+	if stats := p.Stats(); stats.Workers != 4 || stats.Running != 4 || stats.Queued != 2 {
+		t.Fatalf("expected 4 running workers and 2 queued tasks, got %+v", stats)
+	}
+	started.Add(2)
+	close(release)
+	for _, f := range futures {
+		f.Result()
+	}
+
+	// Idle workers above the minimum retire after the idle timeout
+	deadline := time.Now().Add(time.Second)
+	for p.Stats().Workers != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := p.Stats().Workers; got != 1 {
+		t.Fatalf("expected the pool to shrink to 1 worker, got %d", got)
+	}
+}
+
+func TestPool_ElasticResize(t *testing.T) {
+	p := NewPool(4, WithMinWorkers(2), WithMaxWorkers(8), WithIdleTimeout(time.Hour))
+	defer p.Shutdown(context.Background())
+
+	if got := p.Stats().Workers; got != 2 {
+		t.Fatalf("expected 2 workers, got %d", got)
+	}
+
+	// Resizing below the minimum lowers the minimum too
+	if err := p.Resize(1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for p.Stats().Workers != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := p.Stats().Workers; got != 1 {
+		t.Fatalf("expected 1 worker, got %d", got)
+	}
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := p.Stats().Workers; got != 0 {
+		t.Fatalf("expected no workers after shutdown, got %d", got)
+	}
+}
@@ -0,0 +1,132 @@
+package A
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Process bridges a channel of inputs to a channel of futures. It runs fn
+// on each item read from in, with at most limit tasks running at once, and
+// emits each item's future in input order; the futures settle in whatever
+// order their tasks finish. Zero or a negative limit means no limit.
+//
+// The output channel is closed once in is closed or ctx is done. The tasks
+// run under ctx, so when ctx is done the futures still pending fail with
+// its cause, and an item read while waiting for a free slot is emitted as
+// a future already failed with it. The consumer must drain the output
+// channel or cancel ctx, or Process blocks forever. Each future's value is
+// the R that fn returned.
+func Process[T, R any](ctx context.Context, in <-chan T, limit int, fn func(ctx context.Context, item T) (R, error), opts ...Option) <-chan *Future {
+	ctx = orBackground(ctx)
+	out := make(chan *Future)
+	var sem chan struct{}
+	if limit > 0 {
+		sem = make(chan struct{}, limit)
+	}
+	go func() {
+		defer close(out)
+		for {
+			var item T
+			select {
+			case next, ok := <-in:
+				if !ok {
+					return
+				}
+				item = next
+			case <-ctx.Done():
+				return
+			}
+
+			var f *Future
+			if sem == nil {
+				f = processItem(ctx, nil, item, fn, opts...)
+			} else {
+				select {
+				case sem <- struct{}{}:
+					f = processItem(ctx, sem, item, fn, opts...)
+				case <-ctx.Done():
+					f = newSettled(ctx, nil, context.Cause(ctx))
+				}
+			}
+
+			select {
+			case out <- f:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// processItem starts fn on item in a future holding a slot of sem, if any.
+func processItem[T, R any](ctx context.Context, sem chan struct{}, item T, fn func(ctx context.Context, item T) (R, error), opts ...Option) *Future {
+	f := newFuture(ctx, func(ctx context.Context) (any, error) {
+		return fn(ctx, item)
+	}, opts...)
+	if sem != nil {
+		f.whenReleased(func() {
+			<-sem
+		})
+	}
+	if !f.rejectInvalid() && !f.lazy {
+		f.once.Do(f.start)
+	}
+	return f
+}
+
+// ChunkedMap splits items into chunks of chunkSize, the last one possibly
+// shorter, and runs fn on each chunk in a future of its own, so a failing
+// chunk does not affect the others. At most limit chunks run at once; the
+// rest wait their turn without blocking the caller. It returns the futures
+// in chunk order, and none for empty input. Zero or a negative chunkSize
+// puts every item in one chunk, and zero or a negative limit means no limit.
+func ChunkedMap[T any](ctx context.Context, items []T, chunkSize, limit int, fn func(ctx context.Context, chunk []T) (any, error), opts ...Option) []*Future {
+	ctx = orBackground(ctx)
+	if len(items) == 0 {
+		return nil
+	}
+	if chunkSize <= 0 {
+		chunkSize = len(items)
+	}
+	opts = append(slices.Clip(opts), WithGovernor(NewGovernor(limit)))
+	futures := make([]*Future, 0, (len(items)+chunkSize-1)/chunkSize)
+	for chunk := range slices.Chunk(items, chunkSize) {
+		futures = append(futures, NewFuture(ctx, func(ctx context.Context) (any, error) {
+			return fn(ctx, chunk)
+		}, opts...))
+	}
+	return futures
+}
+
+// StartThrottled starts the lazy futures fs in order at a controlled rate:
+// the first burst at once, then one every interval. It returns a future
+// that settles with the number of futures started once every start has
+// been issued, without waiting for the futures themselves. If ctx is done
+// or the returned future is aborted first, the remaining futures are left
+// unstarted and it fails with the cause. A burst below one means one.
+// Pacing follows the returned future's clock.
+func StartThrottled(ctx context.Context, fs []*Future, interval time.Duration, burst int, opts ...Option) *Future {
+	ctx = orBackground(ctx)
+	burst = max(burst, 1)
+	var pacer *Future
+	pacer = newFuture(ctx, func(ctx context.Context) (any, error) {
+		for i, f := range fs {
+			if i >= burst {
+				if err := pacer.sleep(ctx, interval); err != nil {
+					return nil, err
+				}
+			}
+			if err := context.Cause(ctx); err != nil {
+				return nil, err
+			}
+			f.once.Do(f.start)
+		}
+		return len(fs), nil
+	}, opts...)
+	if !pacer.rejectInvalid() && !pacer.lazy {
+		pacer.once.Do(pacer.start)
+	}
+	return pacer
+}
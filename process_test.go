@@ -0,0 +1,207 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+func TestProcess_EmitsInInputOrder(t *testing.T) {
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := range 5 {
+			in <- i
+		}
+	}()
+
+	// Later items finish first
+	out := Process(context.Background(), in, 5, func(ctx context.Context, item int) (int, error) {
+		time.Sleep(time.Duration(5-item) * time.Millisecond)
+		return item * 10, nil
+	})
+	var i int
+	for f := range out {
+		if v, err := f.Result(); v != i*10 || err != nil {
+			t.Fatalf("item %d: expected %d, got %v, %v", i, i*10, v, err)
+		}
+		i++
+	}
+	if i != 5 {
+		t.Fatalf("expected 5 futures, got %d", i)
+	}
+}
+
+func TestProcess_Limit(t *testing.T) {
+	in := make(chan int, 20)
+	for i := range 20 {
+		in <- i
+	}
+	close(in)
+
+	var running, peak atomic.Int32
+	out := Process(context.Background(), in, 3, func(ctx context.Context, item int) (int, error) {
+		defer enter(&running, &peak)()
+		time.Sleep(time.Millisecond)
+		return item, nil
+	})
+	var futures []*Future
+	for f := range out {
+		futures = append(futures, f)
+	}
+	for _, f := range futures {
+		f.Result()
+	}
+	if p := peak.Load(); p > 3 {
+		t.Fatalf("expected at most 3 concurrent tasks, got %d", p)
+	}
+}
+
+func TestProcess_ContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan string)
+	started := make(chan struct{})
+	out := Process(ctx, in, 1, func(ctx context.Context, item string) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	in <- "item"
+	f := <-out
+	<-started
+	cancel()
+
+	// The pending future fails with the context error and the output closes
+	if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	for range out {
+	}
+}
+
+func TestChunkedMap(t *testing.T) {
+	errFailed := errors.New("failed")
+	items := []int{1, 2, 3, 4, 5, 6, 7}
+	var running, peak atomic.Int32
+	futures := ChunkedMap(context.Background(), items, 3, 2, func(ctx context.Context, chunk []int) (any, error) {
+		defer enter(&running, &peak)()
+		time.Sleep(time.Millisecond)
+		if chunk[0] == 4 {
+			return nil, errFailed
+		}
+		return len(chunk), nil
+	})
+	if len(futures) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(futures))
+	}
+
+	// The failing chunk is isolated, and the last chunk is partial
+	if v, err := futures[0].Result(); v != 3 || err != nil {
+		t.Fatalf("chunk 0: expected 3, got %v, %v", v, err)
+	}
+	if _, err := futures[1].Result(); !errors.Is(err, errFailed) {
+		t.Fatalf("chunk 1: expected errFailed, got %v", err)
+	}
+	if v, err := futures[2].Result(); v != 1 || err != nil {
+		t.Fatalf("chunk 2: expected 1, got %v, %v", v, err)
+	}
+	if p := peak.Load(); p > 2 {
+		t.Fatalf("expected at most 2 concurrent chunks, got %d", p)
+	}
+}
+
+func TestChunkedMap_EdgeCases(t *testing.T) {
+	count := func(ctx context.Context, chunk []int) (any, error) {
+		return len(chunk), nil
+	}
+	if futures := ChunkedMap(context.Background(), nil, 3, 1, count); len(futures) != 0 {
+		t.Fatalf("expected no futures for empty input, got %d", len(futures))
+	}
+
+	// A non-positive chunk size makes one chunk
+	futures := ChunkedMap(context.Background(), []int{1, 2, 3}, 0, 0, count)
+	if len(futures) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(futures))
+	}
+	if v, _ := futures[0].Result(); v != 3 {
+		t.Fatalf("expected 3 items, got %v", v)
+	}
+}
+
+// enter counts a running task in running, recording the highest count in
+// peak, and returns a function that counts it out again.
+func enter(running, peak *atomic.Int32) func() {
+	n := running.Add(1)
+	for {
+		p := peak.Load()
+		if n <= p || peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	return func() {
+		running.Add(-1)
+	}
+}
+
+func TestStartThrottled(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	fs := make([]*Future, 5)
+	for i := range fs {
+		fs[i] = NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			return i, nil
+		}, WithLazy(), WithSync())
+	}
+
+	// The futures run inline, so each one has settled once it is started
+	started := func() int {
+		n := 0
+		for _, f := range fs {
+			if f.Ready() {
+				n++
+			}
+		}
+		return n
+	}
+
+	pacer := StartThrottled(context.Background(), fs, time.Second, 2, WithClock(clock))
+	clock.BlockUntil(1)
+	if n := started(); n != 2 {
+		t.Fatalf("expected the burst of 2 to start at once, got %d", n)
+	}
+	clock.Advance(time.Second)
+	clock.BlockUntil(1)
+	if n := started(); n != 3 {
+		t.Fatalf("expected one more start after the interval, got %d", n)
+	}
+	clock.Advance(time.Second)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	if v, err := pacer.Result(); v != 5 || err != nil {
+		t.Fatalf("expected 5 starts, got %v, %v", v, err)
+	}
+}
+
+func TestStartThrottled_Cancel(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	fs := make([]*Future, 3)
+	for i := range fs {
+		fs[i] = NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			return i, nil
+		}, WithLazy(), WithSync())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	pacer := StartThrottled(ctx, fs, time.Second, 1, WithClock(clock))
+	clock.BlockUntil(1)
+	cancel()
+	if _, err := pacer.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if !fs[0].Ready() || fs[1].Ready() || fs[2].Ready() {
+		t.Fatal("expected only the first future to be started")
+	}
+}
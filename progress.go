@@ -0,0 +1,151 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrStalled is the cause futures created with WithStallTimeout are aborted
+// with when their task stops reporting progress.
+var ErrStalled = errors.New("task stalled")
+
+// ProgressUpdate is a progress report of a task.
+type ProgressUpdate struct {
+	Done  int64
+	Total int64
+}
+
+// progressKey carries the future of a task created WithProgress in the
+// task's context.
+type progressKey struct{}
+
+// progress is the progress state of a future created WithProgress. It is
+// guarded by the future's lock.
+type progress struct {
+	last    ProgressUpdate
+	updates chan ProgressUpdate
+
+	// stallAfter is the WithStallTimeout duration; reportedAt is when the
+	// task last reported, and stall fires once it has been quiet too long.
+	stallAfter time.Duration
+	reportedAt time.Time
+	stall      Timer
+}
+
+// WithProgress lets the task report its progress with ReportProgress, for
+// consumers to read with Progress or ProgressUpdates. Futures without it pay
+// nothing for progress reporting.
+func WithProgress() Option {
+	return func(f *Future) {
+		if f.progress == nil {
+			f.progress = &progress{updates: make(chan ProgressUpdate, 1)}
+		}
+	}
+}
+
+// WithStallTimeout aborts the future with ErrStalled if its task goes d
+// without reporting progress after its first report. Tasks that never
+// report are exempt. It implies WithProgress.
+func WithStallTimeout(d time.Duration) Option {
+	return func(f *Future) {
+		if d <= 0 {
+			f.invalidOption("WithStallTimeout", "non-positive duration %v", d)
+			return
+		}
+		WithProgress()(f)
+		f.progress.stallAfter = d
+	}
+}
+
+// ReportProgress reports that the task running under ctx has completed done
+// units of work out of total. It never blocks, and it does nothing if the
+// task's future was not created WithProgress or has settled.
+func ReportProgress(ctx context.Context, done, total int64) {
+	f, ok := ctx.Value(progressKey{}).(*Future)
+	if !ok {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.settled {
+		return
+	}
+	f.publishProgress(ProgressUpdate{Done: done, Total: total})
+	if p := f.progress; p.stallAfter > 0 {
+		p.reportedAt = f.now()
+		if p.stall == nil {
+			p.stall = f.afterFunc(p.stallAfter, f.checkStall)
+		} else {
+			p.stall.Reset(p.stallAfter)
+		}
+	}
+}
+
+// checkStall aborts the future if its task has not reported progress within
+// the stall timeout.
+func (f *Future) checkStall() {
+	f.mu.Lock()
+	p := f.progress
+	if f.settled || f.since(p.reportedAt) < p.stallAfter {
+		// Settled, or a report raced with the timer.
+		f.mu.Unlock()
+		return
+	}
+	f.mu.Unlock()
+	f.AbortWithError(f.attribute(ErrStalled))
+}
+
+// Progress returns the latest progress reported by the task. Both values
+// are zero until the task reports, and for futures created without
+// WithProgress.
+func (f *Future) Progress() (done, total int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.progress == nil {
+		return 0, 0
+	}
+	return f.progress.last.Done, f.progress.last.Total
+}
+
+// ProgressUpdates returns a channel of progress reports. Updates are
+// coalesced: a consumer that falls behind receives only the latest one.
+// When the future settles, the channel is closed, after a final complete
+// update if it succeeded. It returns nil for futures created without
+// WithProgress.
+func (f *Future) ProgressUpdates() <-chan ProgressUpdate {
+	if f.progress == nil {
+		return nil
+	}
+	return f.progress.updates
+}
+
+// publishProgress records update and offers it to the updates channel,
+// replacing an update the consumer has not received yet. It must be called
+// with f.mu held.
+func (f *Future) publishProgress(update ProgressUpdate) {
+	f.progress.last = update
+	select {
+	case <-f.progress.updates:
+	default:
+	}
+	f.progress.updates <- update
+}
+
+// finishProgress delivers the final complete update if the future
+// succeeded, and closes the updates channel. It must be called with f.mu
+// held, once the future has settled.
+func (f *Future) finishProgress() {
+	if f.progress.stall != nil {
+		f.progress.stall.Stop()
+	}
+	if f.outcome == Succeeded {
+		final := f.progress.last
+		if final.Total <= 0 {
+			final.Total = 1
+		}
+		final.Done = final.Total
+		f.publishProgress(final)
+	}
+	close(f.progress.updates)
+}
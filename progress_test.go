@@ -0,0 +1,129 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+func TestFuture_Progress(t *testing.T) {
+	report := make(chan struct{})
+	reported := make(chan struct{})
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		for i := int64(1); i <= 3; i++ {
+			<-report
+			ReportProgress(ctx, i, 10)
+			reported <- struct{}{}
+		}
+		return nil, nil
+	}, WithProgress())
+
+	report <- struct{}{}
+	<-reported
+	if done, total := future.Progress(); done != 1 || total != 10 {
+		t.Fatalf("expected progress 1/10, got %d/%d", done, total)
+	}
+	if update := <-future.ProgressUpdates(); update != (ProgressUpdate{Done: 1, Total: 10}) {
+		t.Fatalf("expected update 1/10, got %+v", update)
+	}
+
+	// Updates the consumer missed are coalesced into the latest one
+	for i := 0; i < 2; i++ {
+		report <- struct{}{}
+		<-reported
+	}
+	if update := <-future.ProgressUpdates(); update != (ProgressUpdate{Done: 3, Total: 10}) {
+		t.Fatalf("expected update 3/10, got %+v", update)
+	}
+
+	// Settlement delivers a final complete update and closes the channel
+	future.Result()
+	var updates []ProgressUpdate
+	for update := range future.ProgressUpdates() {
+		updates = append(updates, update)
+	}
+	if len(updates) != 1 || updates[0] != (ProgressUpdate{Done: 10, Total: 10}) {
+		t.Fatalf("expected a final update of 10/10, got %+v", updates)
+	}
+}
+
+func TestFuture_ProgressFailed(t *testing.T) {
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		ReportProgress(ctx, 4, 10)
+		return nil, errors.New("disk full")
+	}, WithProgress())
+	future.Result()
+
+	// A failure is not reported as complete
+	var updates []ProgressUpdate
+	for update := range future.ProgressUpdates() {
+		updates = append(updates, update)
+	}
+	if len(updates) != 1 || updates[0] != (ProgressUpdate{Done: 4, Total: 10}) {
+		t.Fatalf("expected only the update 4/10, got %+v", updates)
+	}
+	if done, total := future.Progress(); done != 4 || total != 10 {
+		t.Fatalf("expected progress 4/10, got %d/%d", done, total)
+	}
+}
+
+func TestFuture_ProgressDisabled(t *testing.T) {
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		// Reporting without WithProgress is a no-op
+		ReportProgress(ctx, 1, 2)
+		return nil, nil
+	})
+	future.Result()
+	if done, total := future.Progress(); done != 0 || total != 0 {
+		t.Fatalf("expected no progress, got %d/%d", done, total)
+	}
+	if future.ProgressUpdates() != nil {
+		t.Fatal("expected no updates channel")
+	}
+}
+
+func TestFuture_StallTimeout(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	reported, proceed := make(chan struct{}), make(chan struct{})
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		// Report steadily for a while, then stop making progress
+		for i := int64(1); i <= 5; i++ {
+			ReportProgress(ctx, i, 10)
+			reported <- struct{}{}
+			<-proceed
+		}
+		<-ctx.Done()
+		return nil, context.Cause(ctx)
+	}, WithStallTimeout(30*time.Millisecond), WithClock(clock))
+
+	for range 5 {
+		<-reported
+		clock.Advance(20 * time.Millisecond)
+		if future.Ready() {
+			t.Fatal("expected the task not to be reaped while it reported progress")
+		}
+		proceed <- struct{}{}
+	}
+	clock.Advance(10 * time.Millisecond)
+	if _, err := future.Result(); !errors.Is(err, ErrStalled) {
+		t.Fatalf("expected ErrStalled, got %v", err)
+	}
+}
+
+func TestFuture_StallTimeoutWithoutReports(t *testing.T) {
+	// A task that never reports progress is exempt
+	clock := fakeclock.New(time.Now())
+	release := make(chan struct{})
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-release
+		return "done", nil
+	}, WithStallTimeout(10*time.Millisecond), WithClock(clock))
+	clock.Advance(time.Hour)
+	close(release)
+	if result, err := future.Result(); err != nil || result != "done" {
+		t.Fatalf("expected result 'done', got %v, %v", result, err)
+	}
+}
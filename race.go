@@ -0,0 +1,53 @@
+package A
+
+import (
+	"context"
+	"fmt"
+)
+
+// SupersededError is the abort cause of a future that lost a Race, so
+// metrics and logs can tell it apart from a real failure. It wraps
+// context.Canceled.
+type SupersededError struct {
+	// WinnerIndex is the position of the winning future among those passed
+	// to Race.
+	WinnerIndex int
+}
+
+func (e *SupersededError) Error() string {
+	return fmt.Sprintf("superseded by future %d", e.WinnerIndex)
+}
+
+func (e *SupersededError) Unwrap() error {
+	return context.Canceled
+}
+
+// Race starts fs, waits for the first of them to settle, and returns its
+// index; the winner's result is then available from its Result. Every other
+// future is aborted with a *SupersededError naming the winner. If ctx is
+// done first, Race returns -1 and ctx's error and leaves the futures
+// running.
+func Race(ctx context.Context, fs ...*Future) (int, error) {
+	ctx = orBackground(ctx)
+	won := make(chan int, 1)
+	for i, f := range fs {
+		f.once.Do(f.start)
+		f.whenDone(func() {
+			select {
+			case won <- i:
+			default:
+			}
+		})
+	}
+	select {
+	case winner := <-won:
+		for i, f := range fs {
+			if i != winner {
+				f.AbortWithError(&SupersededError{WinnerIndex: winner})
+			}
+		}
+		return winner, nil
+	case <-ctx.Done():
+		return -1, ctx.Err()
+	}
+}
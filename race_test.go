@@ -0,0 +1,61 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRace(t *testing.T) {
+	blocked := func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	release := make(chan struct{})
+	fs := []*Future{
+		NewFuture(context.Background(), blocked),
+		NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			<-release
+			return "fast", nil
+		}),
+		NewFuture(context.Background(), blocked, WithLazy()),
+	}
+	close(release)
+
+	winner, err := Race(context.Background(), fs...)
+	if winner != 1 || err != nil {
+		t.Fatalf("expected future 1 to win, got %d, %v", winner, err)
+	}
+	if v, err := fs[1].Result(); v != "fast" || err != nil {
+		t.Fatalf("expected the winner's result, got %v, %v", v, err)
+	}
+
+	// Losers are aborted with a cause naming the winner, not a bare cancellation
+	for _, i := range []int{0, 2} {
+		c := fs[i].Completion()
+		var superseded *SupersededError
+		if !errors.As(c.Err, &superseded) || superseded.WinnerIndex != 1 {
+			t.Fatalf("expected loser %d to be superseded by 1, got %v", i, c.Err)
+		}
+		if !c.Aborted() || !IsAborted(c.Err) {
+			t.Fatalf("expected loser %d to count as aborted, got %+v", i, c)
+		}
+	}
+}
+
+func TestRace_ContextDone(t *testing.T) {
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if winner, err := Race(ctx, f); winner != -1 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the context to end the race, got %d, %v", winner, err)
+	}
+	if f.Ready() {
+		t.Fatalf("expected the future to keep running")
+	}
+	f.Abort()
+}
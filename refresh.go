@@ -0,0 +1,85 @@
+package A
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Refreshable is a lazily computed value that can be invalidated: each
+// execution of its task runs on first demand, and Reset makes the next
+// demand run the task again. Futures are single-use, so every execution
+// gets a fresh one; a future obtained before a Reset keeps its result.
+type Refreshable struct {
+	ctx  context.Context
+	task func(context.Context) (any, error)
+	opts []Option
+
+	mu      sync.Mutex
+	current *Future
+	waiters int
+}
+
+// NewRefreshable creates a Refreshable whose executions run task with opts
+// under ctx. Nothing runs until the first Result.
+func NewRefreshable(ctx context.Context, task func(context.Context) (any, error), opts ...Option) *Refreshable {
+	ctx = orBackground(ctx)
+	return &Refreshable{ctx: ctx, task: task, opts: append(slices.Clone(opts), WithLazy())}
+}
+
+// Future returns the future of the current execution, creating a lazy one
+// if the value has been reset. Waiting on it starts the execution, but does
+// not keep Reset from replacing it.
+func (r *Refreshable) Future() *Future {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.future()
+}
+
+// Result starts the current execution if needed, waits for it, and returns
+// its result. Reset fails while Result is waiting.
+func (r *Refreshable) Result() (any, error) {
+	r.mu.Lock()
+	f := r.future()
+	r.waiters++
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.waiters--
+		r.mu.Unlock()
+	}()
+	return f.Result()
+}
+
+// Reset discards the settled result, so the next Result runs the task
+// again. It returns false, and changes nothing, if the current execution
+// has not settled or a Result call is waiting on it.
+func (r *Refreshable) Reset() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.waiters > 0 || r.current != nil && !r.current.Ready() {
+		return false
+	}
+	r.current = nil
+	return true
+}
+
+// Rerun resets the value like Reset and starts the new execution at once,
+// returning its future. It returns false if Reset would.
+func (r *Refreshable) Rerun() (*Future, bool) {
+	if !r.Reset() {
+		return nil, false
+	}
+	f := r.Future()
+	f.once.Do(f.start)
+	return f, true
+}
+
+// future returns the current execution, creating it if needed. The caller
+// holds r.mu.
+func (r *Refreshable) future() *Future {
+	if r.current == nil {
+		r.current = NewFuture(r.ctx, r.task, r.opts...)
+	}
+	return r.current
+}
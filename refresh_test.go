@@ -0,0 +1,114 @@
+package A
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRefreshable_Reset(t *testing.T) {
+	var runs atomic.Int32
+	r := NewRefreshable(context.Background(), func(ctx context.Context) (any, error) {
+		return runs.Add(1), nil
+	})
+	if runs.Load() != 0 {
+		t.Fatal("expected nothing to run before the first Result")
+	}
+
+	old := r.Future()
+	if v, _ := r.Result(); v != int32(1) {
+		t.Fatalf("expected 1, got %v", v)
+	}
+	if v, _ := r.Result(); v != int32(1) {
+		t.Fatalf("expected the result to be kept, got %v", v)
+	}
+
+	if !r.Reset() {
+		t.Fatal("expected Reset to succeed on a settled value")
+	}
+	if v, _ := r.Result(); v != int32(2) {
+		t.Fatalf("expected the task to run again, got %v", v)
+	}
+
+	// A future obtained before the Reset keeps its result
+	if v, _ := old.Result(); v != int32(1) {
+		t.Fatalf("expected the old future to keep 1, got %v", v)
+	}
+}
+
+func TestRefreshable_ResetWhileWaiting(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r := NewRefreshable(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-release
+		return "value", nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Result()
+	}()
+	<-started
+	if r.Reset() {
+		t.Fatal("expected Reset to fail while Result is waiting")
+	}
+	close(release)
+	<-done
+	if !r.Reset() {
+		t.Fatal("expected Reset to succeed once Result has returned")
+	}
+}
+
+func TestRefreshable_Rerun(t *testing.T) {
+	var runs atomic.Int32
+	r := NewRefreshable(context.Background(), func(ctx context.Context) (any, error) {
+		return runs.Add(1), nil
+	})
+	r.Result()
+	f, ok := r.Rerun()
+	if !ok {
+		t.Fatal("expected Rerun to succeed")
+	}
+	if v, _ := f.Result(); v != int32(2) {
+		t.Fatalf("expected 2, got %v", v)
+	}
+	if v, _ := r.Result(); v != int32(2) {
+		t.Fatalf("expected Result to see the rerun, got %v", v)
+	}
+}
+
+func TestRefreshable_ConcurrentReset(t *testing.T) {
+	var runs atomic.Int32
+	r := NewRefreshable(context.Background(), func(ctx context.Context) (any, error) {
+		return runs.Add(1), nil
+	})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				if v, err := r.Result(); v == nil || err != nil {
+					t.Errorf("unexpected result %v, %v", v, err)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				r.Reset()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every execution was started by a Result
+	if n := runs.Load(); n < 1 || n > 8*100 {
+		t.Fatalf("unexpected number of executions %d", n)
+	}
+}
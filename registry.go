@@ -0,0 +1,160 @@
+package A
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultRegistry is a process-wide Registry for futures created with
+// WithRegistry(DefaultRegistry).
+var DefaultRegistry = NewRegistry()
+
+// WithRegistry tracks the future in r until it settles.
+func WithRegistry(r *Registry) Option {
+	return func(f *Future) {
+		f.registry = r
+	}
+}
+
+// FutureInfo describes a pending future.
+type FutureInfo struct {
+	// Future is the pending future.
+	Future *Future
+	// Name is the name given with WithName.
+	Name string
+	// State is Pending or Running, or Settled in leak reports.
+	State State
+	// CreatedAt is when the future was created.
+	CreatedAt time.Time
+	// StartedAt is when the task started executing, or the zero time if it
+	// has not started.
+	StartedAt time.Time
+	// Age is how long the future has existed.
+	Age time.Duration
+	// Origin is the file:line that created the future, if it was created
+	// with WithCallerInfo or leak detection is enabled.
+	Origin string
+}
+
+// Registry tracks outstanding futures so a process can wait for, or abort,
+// all of them on shutdown. Futures deregister themselves when they settle.
+type Registry struct {
+	mu      sync.Mutex
+	futures map[*Future]struct{}
+	empty   chan struct{}
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	empty := make(chan struct{})
+	close(empty)
+	return &Registry{
+		futures: make(map[*Future]struct{}),
+		empty:   empty,
+	}
+}
+
+// Len returns the number of registered futures that have not settled.
+func (r *Registry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.futures)
+}
+
+// WaitForAll blocks until every registered future has settled or ctx is
+// done. Lazy futures that are never started keep it waiting; use AbortAll
+// to settle them.
+func (r *Registry) WaitForAll(ctx context.Context) error {
+	r.mu.Lock()
+	empty := r.empty
+	r.mu.Unlock()
+	select {
+	case <-empty:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// AbortAll aborts every registered future with cause.
+func (r *Registry) AbortAll(cause error) {
+	r.mu.Lock()
+	futures := make([]*Future, 0, len(r.futures))
+	for f := range r.futures {
+		futures = append(futures, f)
+	}
+	r.mu.Unlock()
+	for _, f := range futures {
+		f.AbortWithError(cause)
+	}
+}
+
+// Pending returns a snapshot of the registered futures that have not
+// settled, oldest first, for shutdown logs and debug handlers.
+func (r *Registry) Pending() []FutureInfo {
+	r.mu.Lock()
+	futures := make([]*Future, 0, len(r.futures))
+	for f := range r.futures {
+		futures = append(futures, f)
+	}
+	r.mu.Unlock()
+	return pendingInfo(futures)
+}
+
+// pendingInfo describes the futures that have not settled, oldest first.
+func pendingInfo(futures []*Future) []FutureInfo {
+	infos := make([]FutureInfo, 0, len(futures))
+	for _, f := range futures {
+		if info, ok := f.info(); ok {
+			infos = append(infos, info)
+		}
+	}
+	slices.SortFunc(infos, func(a, b FutureInfo) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return infos
+}
+
+// info describes f as of now on its clock. It reports false if f has
+// settled.
+func (f *Future) info() (FutureInfo, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.settled {
+		return FutureInfo{}, false
+	}
+	return FutureInfo{
+		Future:    f,
+		Name:      f.name,
+		State:     f.state(),
+		CreatedAt: f.createdAt,
+		StartedAt: f.startedAt,
+		Age:       f.since(f.createdAt),
+		Origin:    f.origin,
+	}, true
+}
+
+// add registers f until it settles.
+func (r *Registry) add(f *Future) {
+	r.mu.Lock()
+	if len(r.futures) == 0 {
+		r.empty = make(chan struct{})
+	}
+	r.futures[f] = struct{}{}
+	r.mu.Unlock()
+	f.whenDone(func() {
+		r.remove(f)
+	})
+}
+
+// remove deregisters f.
+func (r *Registry) remove(f *Future) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.futures, f)
+	if len(r.futures) == 0 {
+		close(r.empty)
+	}
+}
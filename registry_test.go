@@ -0,0 +1,107 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistry_WaitForAll(t *testing.T) {
+	r := NewRegistry()
+
+	release := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			<-release
+			return nil, nil
+		}, WithRegistry(r))
+	}
+	if got := r.Len(); got != 3 {
+		t.Fatalf("expected 3 registered futures, got %d", got)
+	}
+
+	// WaitForAll gives up when its context expires
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := r.WaitForAll(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// Settled futures deregister themselves
+	close(release)
+	if err := r.WaitForAll(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := r.Len(); got != 0 {
+		t.Fatalf("expected no registered futures, got %d", got)
+	}
+}
+
+func TestRegistry_AbortAll(t *testing.T) {
+	r := NewRegistry()
+	errShutdown := errors.New("shutting down")
+
+	started := make(chan struct{})
+	causes := make(chan error, 1)
+	running := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return nil, ctx.Err()
+	}, WithRegistry(r))
+	lazy := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithRegistry(r), WithLazy())
+
+	// Abort only once the task is running, so it observes its context
+	<-started
+	r.AbortAll(errShutdown)
+	for _, f := range []*Future{running, lazy} {
+		if _, err := f.Result(); !errors.Is(err, errShutdown) {
+			t.Fatalf("expected the abort cause, got %v", err)
+		}
+	}
+	if cause := <-causes; !errors.Is(cause, errShutdown) {
+		t.Fatalf("expected the task context cause to be the abort cause, got %v", cause)
+	}
+	if err := r.WaitForAll(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestRegistry_Pending(t *testing.T) {
+	r := NewRegistry()
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	running := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}, WithRegistry(r), WithName("running"))
+	lazy := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithRegistry(r), WithName("lazy"), WithLazy())
+	NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithRegistry(r)).Result()
+	<-started
+	time.Sleep(10 * time.Millisecond)
+
+	// Settled futures are left out, and the rest are listed oldest first
+	infos := r.Pending()
+	if len(infos) != 2 || infos[0].Future != running || infos[1].Future != lazy {
+		t.Fatalf("expected the running and lazy futures, got %+v", infos)
+	}
+	if infos[0].Name != "running" || infos[0].State != Running || infos[0].StartedAt.IsZero() {
+		t.Fatalf("expected a named running future with a start time, got %+v", infos[0])
+	}
+	if infos[1].Name != "lazy" || infos[1].State != Pending || !infos[1].StartedAt.IsZero() {
+		t.Fatalf("expected a named pending future without a start time, got %+v", infos[1])
+	}
+	if infos[0].Age < 10*time.Millisecond {
+		t.Fatalf("expected age of at least 10ms, got %v", infos[0].Age)
+	}
+}
@@ -0,0 +1,75 @@
+package A
+
+import (
+	"context"
+	"time"
+)
+
+// WithRetry runs the task up to attempts times, until an attempt succeeds
+// or the future's context is done. WithTimeout bounds all attempts
+// together. A panic settles the future at once, since re-running a task
+// that panicked usually multiplies the damage; WithRetryPanics opts in to
+// retrying panics too. Each attempt counts towards Attempts in Snapshot
+// and ResultMeta.
+func WithRetry(attempts int) Option {
+	return func(f *Future) {
+		if attempts < 1 {
+			f.invalidOption("WithRetry", "%d attempts", attempts)
+			return
+		}
+		f.retries = attempts - 1
+	}
+}
+
+// WithRetryPanics makes WithRetry retry attempts that panicked. If the last
+// attempt panics, the future still fails with a *PanicError.
+func WithRetryPanics() Option {
+	return func(f *Future) {
+		f.retryPanics = true
+	}
+}
+
+// WithRetryBackoff waits d between the attempts of WithRetry, on the
+// future's clock. A timeout or abort during the wait ends the future
+// without another attempt.
+func WithRetryBackoff(d time.Duration) Option {
+	return func(f *Future) {
+		if d < 0 {
+			f.invalidOption("WithRetryBackoff", "negative duration %v", d)
+			return
+		}
+		f.retryBackoff = d
+	}
+}
+
+// attempt calls the task, retrying failed attempts as WithRetry allows and
+// hedging each as WithHedge asks. The last attempt is called without
+// recovering, so its panic reaches run.
+func (f *Future) attempt(ctx context.Context) (any, error) {
+	for retries := f.retries; retries > 0; retries-- {
+		res, err := f.hedged(f.traced(ctx), f.callRetryable)
+		if err == nil || ctx.Err() != nil || f.State() == Settled {
+			// A hedge settles the future itself when it wins.
+			return res, err
+		}
+		f.endSpan(err)
+		if f.retryBackoff > 0 {
+			if err := f.sleep(ctx, f.retryBackoff); err != nil {
+				return nil, err
+			}
+		}
+		f.mu.Lock()
+		f.attempts++
+		f.mu.Unlock()
+	}
+	return f.hedged(f.traced(ctx), f.call)
+}
+
+// callRetryable calls the task, turning a panic into a *PanicError if
+// panics are retried.
+func (f *Future) callRetryable(ctx context.Context) (any, error) {
+	if f.retryPanics {
+		return f.callRecovered(ctx, f.task)
+	}
+	return f.call(ctx)
+}
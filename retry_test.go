@@ -0,0 +1,128 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+func TestWithRetry(t *testing.T) {
+	errFlaky := errors.New("flaky")
+	var calls atomic.Int32
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		if calls.Add(1) < 3 {
+			return nil, errFlaky
+		}
+		return "ok", nil
+	}, WithRetry(5))
+	v, err, meta := f.ResultMeta()
+	if v != "ok" || err != nil {
+		t.Fatalf("expected ok, got %v, %v", v, err)
+	}
+	if calls.Load() != 3 || meta.Attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d calls and %+v", calls.Load(), meta)
+	}
+
+	// Exhausted retries report the last failure
+	calls.Store(-10)
+	f = NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		calls.Add(1)
+		return nil, errFlaky
+	}, WithRetry(2))
+	if _, err, meta := f.ResultMeta(); !errors.Is(err, errFlaky) || meta.Attempts != 2 {
+		t.Fatalf("expected 2 failed attempts, got %v, %+v", err, meta)
+	}
+}
+
+func TestWithRetry_Panics(t *testing.T) {
+	var calls atomic.Int32
+	panicky := func(ctx context.Context) (any, error) {
+		calls.Add(1)
+		panic("boom")
+	}
+
+	// Panics are not retried by default
+	f := NewFuture(context.Background(), panicky, WithRetry(3))
+	if _, err, meta := f.ResultMeta(); !IsPanic(err) || meta.Attempts != 1 || calls.Load() != 1 {
+		t.Fatalf("expected a single panicked attempt, got %v, %+v", err, meta)
+	}
+
+	// WithRetryPanics retries them, and the last one is kept intact
+	calls.Store(0)
+	f = NewFuture(context.Background(), panicky, WithRetry(3), WithRetryPanics())
+	_, err, meta := f.ResultMeta()
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Fatalf("expected a PanicError, got %v", err)
+	}
+	if meta.Attempts != 3 || !meta.Panicked || calls.Load() != 3 {
+		t.Fatalf("expected 3 panicked attempts, got %+v after %d calls", meta, calls.Load())
+	}
+
+	calls.Store(0)
+	f = NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		if calls.Add(1) == 1 {
+			panic("boom")
+		}
+		return "ok", nil
+	}, WithRetry(3), WithRetryPanics())
+	if v, err := f.Result(); v != "ok" || err != nil {
+		t.Fatalf("expected a retried panic to recover, got %v, %v", v, err)
+	}
+}
+
+func TestWithRetryBackoff(t *testing.T) {
+	errFailed := errors.New("failed")
+	clock := fakeclock.New(time.Now())
+	var calls atomic.Int32
+	failing := func(ctx context.Context) (any, error) {
+		calls.Add(1)
+		return nil, errFailed
+	}
+	f := NewFuture(context.Background(), failing, WithClock(clock), WithRetry(3), WithRetryBackoff(time.Second))
+	for i := 0; i < 2; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+	}
+	if _, err, meta := f.ResultMeta(); !errors.Is(err, errFailed) || meta.Attempts != 3 || calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %v, %+v", err, meta)
+	}
+
+	// A timeout during the backoff ends the future without another attempt
+	clock = fakeclock.New(time.Now())
+	calls.Store(0)
+	f = NewFuture(context.Background(), failing, WithClock(clock), WithRetry(3), WithRetryBackoff(10*time.Second), WithTimeout(5*time.Second))
+	clock.BlockUntil(2)
+	clock.Advance(5 * time.Second)
+	if _, err, meta := f.ResultMeta(); !IsTimeout(err) || meta.Attempts != 1 {
+		t.Fatalf("expected a timeout during the backoff, got %v, %+v", err, meta)
+	}
+	f.AbortAndWait(context.Background())
+	if calls.Load() != 1 {
+		t.Fatalf("expected no attempt after the timeout, got %d calls", calls.Load())
+	}
+}
+
+func TestWithRetry_Abort(t *testing.T) {
+	var calls atomic.Int32
+	ready := make(chan struct{})
+	var f *Future
+	f = NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-ready
+		calls.Add(1)
+		f.Abort()
+		return nil, errors.New("failed")
+	}, WithRetry(5))
+	close(ready)
+	if _, err := f.Result(); !IsAborted(err) {
+		t.Fatalf("expected an abort, got %v", err)
+	}
+	f.AbortAndWait(context.Background())
+	if calls.Load() != 1 {
+		t.Fatalf("expected no retries after an abort, got %d calls", calls.Load())
+	}
+}
@@ -0,0 +1,141 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrScopeClosed is returned by futures created through a ScopeHandle after
+// its Scope has returned.
+var ErrScopeClosed = errors.New("scope is closed")
+
+// ScopeOption defines functional options for Scope.
+type ScopeOption func(*scopeConfig)
+
+type scopeConfig struct {
+	grace time.Duration
+}
+
+// WithGracePeriod bounds how long Scope waits for its futures to settle and
+// their tasks to return. Futures still unsettled after d are aborted, and
+// tasks still running are reported in a *StragglerError instead of blocking
+// Scope forever. By default Scope waits as long as it takes.
+func WithGracePeriod(d time.Duration) ScopeOption {
+	return func(c *scopeConfig) {
+		c.grace = d
+	}
+}
+
+// StragglerError reports futures whose tasks were still running when the
+// scope's grace period expired.
+type StragglerError struct {
+	Stragglers []*Future
+}
+
+func (e *StragglerError) Error() string {
+	return fmt.Sprintf("%d tasks still running after grace period", len(e.Stragglers))
+}
+
+// ScopeHandle creates futures owned by a Scope.
+type ScopeHandle struct {
+	ctx context.Context
+
+	mu      sync.Mutex
+	futures []*Future
+	closed  bool
+}
+
+// Scope runs fn and guarantees that every future created through the handle
+// has settled, and that its task has returned, before Scope returns.
+//
+// If fn returns nil, Scope waits for started futures to complete and aborts
+// lazy futures that were never started. If fn returns an error or panics,
+// every unsettled future is aborted. Scope then waits for the tasks to
+// acknowledge, re-panics if fn panicked, and returns fn's error joined with
+// a *StragglerError if the grace period expired first.
+func Scope(ctx context.Context, fn func(s *ScopeHandle) error, opts ...ScopeOption) (err error) {
+	ctx = orBackground(ctx)
+	var cfg scopeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	scopeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s := &ScopeHandle{ctx: scopeCtx}
+
+	defer func() {
+		r := recover()
+		stragglers := s.close(r != nil || err != nil, cfg.grace)
+		if r != nil {
+			panic(r)
+		}
+		if len(stragglers) > 0 {
+			err = errors.Join(err, &StragglerError{Stragglers: stragglers})
+		}
+	}()
+	return fn(s)
+}
+
+// Context returns the context shared by the scope's futures. It is
+// cancelled when Scope returns.
+func (s *ScopeHandle) Context() context.Context {
+	return s.ctx
+}
+
+// Future creates a future owned by the scope. After Scope has returned, it
+// returns a future that has already failed with ErrScopeClosed.
+func (s *ScopeHandle) Future(task func(context.Context) (any, error), opts ...Option) *Future {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return newSettled(s.ctx, nil, ErrScopeClosed)
+	}
+	f := newFuture(s.ctx, task, opts...)
+	s.futures = append(s.futures, f)
+	s.mu.Unlock()
+	if !f.rejectInvalid() && !f.lazy {
+		f.once.Do(f.start)
+	}
+	return f
+}
+
+// close settles every future, aborting them if abort is true, and waits up
+// to grace for them to settle and for their tasks to return. Futures still
+// unsettled when grace expires are aborted. It returns the stragglers.
+func (s *ScopeHandle) close(abort bool, grace time.Duration) []*Future {
+	s.mu.Lock()
+	s.closed = true
+	futures := s.futures
+	s.mu.Unlock()
+
+	ctx := context.Background()
+	if grace > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, grace)
+		defer cancel()
+	}
+	for _, f := range futures {
+		// Lazy futures that were never started are aborted in any case.
+		f.once.Do(f.Abort)
+		if abort {
+			f.Abort()
+			continue
+		}
+		select {
+		case <-f.Done():
+		case <-ctx.Done():
+			f.Abort()
+		}
+	}
+
+	var stragglers []*Future
+	for _, f := range futures {
+		if err := f.waitExit(ctx); err != nil {
+			stragglers = append(stragglers, f)
+		}
+	}
+	return stragglers
+}
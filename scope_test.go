@@ -0,0 +1,166 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScope_WaitsForFutures(t *testing.T) {
+	var future *Future
+	err := Scope(context.Background(), func(s *ScopeHandle) error {
+		future = s.Future(func(ctx context.Context) (any, error) {
+			time.Sleep(20 * time.Millisecond)
+			return "completed", nil
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// A clean return waits for started futures to complete
+	if !future.Ready() {
+		t.Fatal("expected future to be settled when Scope returns")
+	}
+	if result, _ := future.Result(); result != "completed" {
+		t.Fatalf("expected result 'completed', got %v", result)
+	}
+}
+
+func TestScope_AbortsOnError(t *testing.T) {
+	errFailed := errors.New("failed")
+	started := make(chan struct{})
+	exited := make(chan struct{})
+	var running, lazy *Future
+	err := Scope(context.Background(), func(s *ScopeHandle) error {
+		running = s.Future(func(ctx context.Context) (any, error) {
+			close(started)
+			defer close(exited)
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond) // Simulate cleanup
+			return nil, ctx.Err()
+		})
+		<-started
+		lazy = s.Future(func(ctx context.Context) (any, error) {
+			t.Error("lazy future should never start")
+			return nil, nil
+		}, WithLazy())
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("expected fn's error, got %v", err)
+	}
+
+	// Every future is aborted and its task has returned
+	select {
+	case <-exited:
+	default:
+		t.Fatal("expected the task to have returned before Scope returned")
+	}
+	for _, f := range []*Future{running, lazy} {
+		if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	}
+}
+
+func TestScope_Panic(t *testing.T) {
+	var future *Future
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Fatalf("expected panic 'boom' to propagate, got %v", r)
+			}
+		}()
+		Scope(context.Background(), func(s *ScopeHandle) error {
+			future = s.Future(func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			})
+			panic("boom")
+		})
+	}()
+
+	// Futures are settled even when fn panics
+	if !future.Ready() {
+		t.Fatal("expected future to be settled after the panic")
+	}
+}
+
+func TestScope_Stragglers(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	var stuck *Future
+	started := make(chan struct{})
+	start := time.Now()
+	err := Scope(context.Background(), func(s *ScopeHandle) error {
+		stuck = s.Future(func(ctx context.Context) (any, error) {
+			close(started)
+			// Ignore cancellation
+			<-release
+			return nil, nil
+		})
+		<-started
+		return errors.New("failed")
+	}, WithGracePeriod(30*time.Millisecond))
+
+	// Scope gives up after the grace period and reports the straggler
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected Scope to return after the grace period, took %v", elapsed)
+	}
+	var straggler *StragglerError
+	if !errors.As(err, &straggler) || len(straggler.Stragglers) != 1 || straggler.Stragglers[0] != stuck {
+		t.Fatalf("expected the stuck future to be reported, got %v", err)
+	}
+}
+
+func TestScope_StragglersOnCleanReturn(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	var stuck *Future
+	started := make(chan struct{})
+	start := time.Now()
+	err := Scope(context.Background(), func(s *ScopeHandle) error {
+		stuck = s.Future(func(ctx context.Context) (any, error) {
+			close(started)
+			// Ignore cancellation
+			<-release
+			return nil, nil
+		})
+		<-started
+		return nil
+	}, WithGracePeriod(30*time.Millisecond))
+
+	// The grace period also bounds the wait for futures to settle
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected Scope to return after the grace period, took %v", elapsed)
+	}
+	if _, err := stuck.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the unsettled future to be aborted, got %v", err)
+	}
+	var straggler *StragglerError
+	if !errors.As(err, &straggler) || len(straggler.Stragglers) != 1 || straggler.Stragglers[0] != stuck {
+		t.Fatalf("expected the stuck future to be reported, got %v", err)
+	}
+}
+
+func TestScope_Closed(t *testing.T) {
+	var handle *ScopeHandle
+	Scope(context.Background(), func(s *ScopeHandle) error {
+		handle = s
+		return nil
+	})
+
+	// The handle cannot create futures once the scope has returned
+	future := handle.Future(func(ctx context.Context) (any, error) {
+		t.Error("task should not run after the scope closed")
+		return nil, nil
+	})
+	if _, err := future.Result(); !errors.Is(err, ErrScopeClosed) {
+		t.Fatalf("expected ErrScopeClosed, got %v", err)
+	}
+}
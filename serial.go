@@ -0,0 +1,113 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrChainBroken is returned by futures of a Serial created with
+// WithBreakOnFailure once an earlier future in the chain has failed.
+var ErrChainBroken = errors.New("serial chain broken")
+
+// SerialOption defines functional options for Serial.
+type SerialOption func(*Serial)
+
+// WithBreakOnFailure makes a failed future, including an aborted one, break
+// the chain: every later future settles with an error wrapping
+// ErrChainBroken and the failure, without running its task.
+func WithBreakOnFailure() SerialOption {
+	return func(s *Serial) {
+		s.breakOnFailure = true
+	}
+}
+
+// Serial runs tasks one at a time in the order they are added, like a pool's
+// SubmitKeyed for a single key: each task starts only after the previous
+// one has settled and its task function has returned. It suits per-user
+// write ordering. It waits on settlement hooks rather than a goroutine.
+type Serial struct {
+	breakOnFailure bool
+
+	mu   sync.Mutex
+	tail *serialLink
+}
+
+// serialLink is a future's place in a Serial chain.
+type serialLink struct {
+	f *Future
+	// done is set, and next called, once f's turn is over; both are
+	// guarded by the Serial lock.
+	done bool
+	next func()
+}
+
+// NewSerial creates an empty Serial.
+func NewSerial(opts ...SerialOption) *Serial {
+	s := &Serial{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Next adds task to the chain and returns its future, which starts once
+// every future added before it has finished. Result does not start it
+// early, and aborting it while it waits lets the chain move on only once
+// the future before it has finished.
+func (s *Serial) Next(ctx context.Context, task func(context.Context) (any, error), opts ...Option) *Future {
+	ctx = orBackground(ctx)
+	f := newFuture(ctx, task, opts...)
+	if f.lazy {
+		f.invalidOption("WithLazy", "serial tasks start when their turn comes")
+	}
+	if f.rejectInvalid() {
+		return f
+	}
+	f.once.Do(func() {})
+
+	l := &serialLink{f: f}
+	s.mu.Lock()
+	prev := s.tail
+	s.tail = l
+	if prev != nil && !prev.done {
+		prev.next = func() {
+			s.turn(prev, l)
+		}
+		s.mu.Unlock()
+		return f
+	}
+	s.mu.Unlock()
+	s.turn(prev, l)
+	return f
+}
+
+// turn starts l's future once prev, if any, has finished.
+func (s *Serial) turn(prev, l *serialLink) {
+	l.f.whenReleased(func() {
+		s.finish(l)
+	})
+	if prev != nil && s.breakOnFailure {
+		if _, err := prev.f.peek(); err != nil {
+			if !errors.Is(err, ErrChainBroken) {
+				err = fmt.Errorf("%w: %w", ErrChainBroken, err)
+			}
+			l.f.settle(nil, err)
+			return
+		}
+	}
+	l.f.launch()
+}
+
+// finish ends l's turn and hands over to the next future in the chain.
+func (s *Serial) finish(l *serialLink) {
+	s.mu.Lock()
+	l.done = true
+	next := l.next
+	l.next = nil
+	s.mu.Unlock()
+	if next != nil {
+		next()
+	}
+}
@@ -0,0 +1,112 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSerial(t *testing.T) {
+	s := NewSerial()
+	var mu sync.Mutex
+	var order []int
+	running := 0
+	futures := make([]*Future, 20)
+	for i := range futures {
+		futures[i] = s.Next(context.Background(), func(ctx context.Context) (any, error) {
+			mu.Lock()
+			running++
+			if running > 1 {
+				t.Errorf("task %d overlapped another task", i)
+			}
+			order = append(order, i)
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			if i%3 == 0 {
+				return nil, errors.New("failed")
+			}
+			return i, nil
+		})
+	}
+
+	// Waiting on a later future does not let it jump the queue
+	if v, err := futures[19].Result(); v != 19 || err != nil {
+		t.Fatalf("expected 19, got %v, %v", v, err)
+	}
+	for i, v := range order {
+		if v != i {
+			t.Fatalf("expected tasks in order, got %v", order)
+		}
+	}
+	if len(order) != 20 {
+		t.Fatalf("expected failures not to break the chain, got %v", order)
+	}
+
+	if _, err := s.Next(context.Background(), nil, WithLazy()).Result(); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected lazy serial tasks to be rejected, got %v", err)
+	}
+}
+
+func TestSerial_AbortWhileWaiting(t *testing.T) {
+	s := NewSerial()
+	release := make(chan struct{})
+	first := s.Next(context.Background(), func(ctx context.Context) (any, error) {
+		<-release
+		return "first", nil
+	})
+	second := s.Next(context.Background(), func(ctx context.Context) (any, error) {
+		t.Error("aborted task ran")
+		return nil, nil
+	})
+	third := s.Next(context.Background(), func(ctx context.Context) (any, error) {
+		return "third", nil
+	})
+	second.Abort()
+
+	// The third task still waits for the first one
+	select {
+	case <-third.Done():
+		t.Fatalf("expected the third task to wait for the first")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	if v, err := third.Result(); v != "third" || err != nil {
+		t.Fatalf("expected third, got %v, %v", v, err)
+	}
+	if v, _ := first.Result(); v != "first" {
+		t.Fatalf("expected first, got %v", v)
+	}
+}
+
+func TestSerial_BreakOnFailure(t *testing.T) {
+	s := NewSerial(WithBreakOnFailure())
+	errFailed := errors.New("failed")
+	s.Next(context.Background(), func(ctx context.Context) (any, error) {
+		return "ok", nil
+	})
+	s.Next(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, errFailed
+	})
+	skipped := func(ctx context.Context) (any, error) {
+		t.Error("task ran after the chain broke")
+		return nil, nil
+	}
+	next := s.Next(context.Background(), skipped)
+	next.Result()
+
+	// Futures added once everything has settled stay broken too
+	for _, f := range []*Future{next, s.Next(context.Background(), skipped)} {
+		_, err := f.Result()
+		if !errors.Is(err, ErrChainBroken) || !errors.Is(err, errFailed) {
+			t.Fatalf("expected a broken chain caused by the failure, got %v", err)
+		}
+		if err.Error() != "serial chain broken: failed" {
+			t.Fatalf("expected the cause to be wrapped once, got %q", err.Error())
+		}
+	}
+}
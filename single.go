@@ -0,0 +1,64 @@
+package A
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+)
+
+// ErrAlreadyConsumed is returned by every read of a WithSingleConsumer
+// future's result after the first.
+var ErrAlreadyConsumed = errors.New("future result already consumed")
+
+// WithSingleConsumer hands the result to exactly one reader, for values such
+// as connections or files that must have a single owner. The first of
+// Result, ResultTimeout, ResultMeta, Completion, OnComplete, or
+// CompletionQueue.Next to read a successful result receives it; every
+// later read gets ErrAlreadyConsumed. A failed result is returned to every
+// reader as usual. If the value is never read before Release, or before
+// the future is garbage collected, the WithResultCleanup function receives
+// it instead. Combinators that read the parent's result, such as Child and
+// DelayResult, do not take ownership and should not be used with it.
+func WithSingleConsumer() Option {
+	return func(f *Future) {
+		f.singleConsumer = true
+	}
+}
+
+// unclaimed is the value of a single-consumer future that nobody has taken
+// yet. It must not reference the future, or the future could never be
+// collected.
+type unclaimed struct {
+	value   any
+	claimed atomic.Bool
+}
+
+// holdUnclaimed arranges for the cleanup to receive item if the future is
+// collected before anyone takes it. It must be called with f.mu held.
+func (f *Future) holdUnclaimed(item any) {
+	if f.cleanup == nil || item == nil {
+		return
+	}
+	f.unclaimed = &unclaimed{value: item}
+	cleanup := f.cleanup
+	runtime.AddCleanup(f, func(u *unclaimed) {
+		if u.claimed.CompareAndSwap(false, true) {
+			cleanup(u.value)
+		}
+	}, f.unclaimed)
+}
+
+// take returns the stored result, handing a single-consumer future's value
+// over to the caller. It must be called with f.mu held.
+func (f *Future) take() (any, error) {
+	item, err := f.item, f.err
+	if !f.singleConsumer || !f.settled || err != nil {
+		return item, err
+	}
+	f.item, f.err = nil, ErrAlreadyConsumed
+	if f.unclaimed != nil {
+		f.unclaimed.claimed.Store(true)
+		f.unclaimed = nil
+	}
+	return item, nil
+}
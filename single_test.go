@@ -0,0 +1,197 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// resource stands in for a value that must have a single owner.
+type resource struct {
+	closed atomic.Int32
+}
+
+func closeResource(value any) {
+	value.(*resource).closed.Add(1)
+}
+
+func TestWithSingleConsumer(t *testing.T) {
+	res := &resource{}
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return res, nil
+	}, WithSingleConsumer(), WithResultCleanup(closeResource))
+
+	if v, err := f.Result(); v != res || err != nil {
+		t.Fatalf("expected the first read to get the value, got %v, %v", v, err)
+	}
+	if v, err := f.Result(); v != nil || err != ErrAlreadyConsumed {
+		t.Fatalf("expected ErrAlreadyConsumed, got %v, %v", v, err)
+	}
+	if _, err := f.ResultTimeout(time.Second); err != ErrAlreadyConsumed {
+		t.Fatalf("expected ErrAlreadyConsumed from ResultTimeout, got %v", err)
+	}
+	if c := f.Completion(); c.Value != nil || c.Err != ErrAlreadyConsumed || c.Outcome != Succeeded {
+		t.Fatalf("expected ErrAlreadyConsumed from Completion, got %+v", c)
+	}
+	f.Release()
+	if n := res.closed.Load(); n != 0 {
+		t.Fatalf("expected a consumed value not to be cleaned up, got %d cleanups", n)
+	}
+}
+
+func TestWithSingleConsumer_Errors(t *testing.T) {
+	errDown := errors.New("down")
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, errDown
+	}, WithSingleConsumer(), WithoutErrorWrapping())
+
+	// Failures are not consumed
+	for range 2 {
+		if _, err := f.Result(); err != errDown {
+			t.Fatalf("expected every read to get the error, got %v", err)
+		}
+	}
+}
+
+func TestWithSingleConsumer_Race(t *testing.T) {
+	res := &resource{}
+	start := make(chan struct{})
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-start
+		return res, nil
+	}, WithSingleConsumer(), WithResultCleanup(closeResource))
+
+	const consumers = 16
+	var wg sync.WaitGroup
+	var won, lost atomic.Int32
+	for i := range consumers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var v any
+			var err error
+			switch i % 3 {
+			case 0:
+				v, err = f.Result()
+			case 1:
+				v, err, _ = f.ResultMeta()
+			case 2:
+				c := f.Completion()
+				v, err = c.Value, c.Err
+			}
+			switch {
+			case v == res && err == nil:
+				won.Add(1)
+			case v == nil && err == ErrAlreadyConsumed:
+				lost.Add(1)
+			default:
+				t.Errorf("unexpected result %v, %v", v, err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	if won.Load() != 1 || lost.Load() != consumers-1 {
+		t.Fatalf("expected exactly one winner, got %d winners and %d losers", won.Load(), lost.Load())
+	}
+}
+
+func TestWithSingleConsumer_AbortAfterConsumption(t *testing.T) {
+	res := &resource{}
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return res, nil
+	}, WithSingleConsumer(), WithResultCleanup(closeResource))
+	if v, _ := f.Result(); v != res {
+		t.Fatalf("expected the value, got %v", v)
+	}
+
+	// The owner keeps the value, and the future stays consumed
+	f.Abort()
+	if _, err := f.Result(); err != ErrAlreadyConsumed {
+		t.Fatalf("expected ErrAlreadyConsumed after Abort, got %v", err)
+	}
+	if n := res.closed.Load(); n != 0 {
+		t.Fatalf("expected the owned value not to be cleaned up, got %d cleanups", n)
+	}
+
+	// Aborting before the task returns hands its value to the cleanup
+	res = &resource{}
+	started, release := make(chan struct{}), make(chan struct{})
+	f = NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-release
+		return res, nil
+	}, WithSingleConsumer(), WithResultCleanup(closeResource))
+	<-started
+	f.Abort()
+	close(release)
+	if err := f.AbortAndWait(context.Background()); err != nil {
+		t.Fatalf("unexpected error waiting for the task: %v", err)
+	}
+	if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the abort error, got %v", err)
+	}
+	if n := res.closed.Load(); n != 1 {
+		t.Fatalf("expected the discarded value to be cleaned up once, got %d", n)
+	}
+}
+
+func TestWithSingleConsumer_Release(t *testing.T) {
+	res := &resource{}
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return res, nil
+	}, WithSingleConsumer(), WithResultCleanup(closeResource))
+	<-f.Done()
+
+	f.Release()
+	f.Release()
+	if n := res.closed.Load(); n != 1 {
+		t.Fatalf("expected the unconsumed value to be cleaned up once, got %d", n)
+	}
+	if _, err := f.Result(); err != ErrReleased {
+		t.Fatalf("expected ErrReleased, got %v", err)
+	}
+}
+
+func TestWithSingleConsumer_Collected(t *testing.T) {
+	cleaned := make(chan any, 2)
+	consumed, unconsumed := &resource{}, &resource{}
+	func() {
+		cleanup := WithResultCleanup(func(value any) {
+			cleaned <- value
+		})
+		f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			return unconsumed, nil
+		}, WithSingleConsumer(), cleanup)
+		<-f.Done()
+		g := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			return consumed, nil
+		}, WithSingleConsumer(), cleanup)
+		g.Result()
+	}()
+
+	deadline := time.After(time.Second)
+	for {
+		runtime.GC()
+		select {
+		case value := <-cleaned:
+			if value != unconsumed {
+				t.Fatalf("expected only the unconsumed value to be cleaned up, got %v", value)
+			}
+			// Give a wrong cleanup of the consumed value a chance to show up.
+			runtime.GC()
+			time.Sleep(10 * time.Millisecond)
+			if len(cleaned) > 0 {
+				t.Fatalf("expected the consumed value not to be cleaned up")
+			}
+			return
+		case <-deadline:
+			t.Fatalf("unconsumed value was not cleaned up")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
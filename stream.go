@@ -0,0 +1,94 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// ErrEndOfStream is returned by Next once a stream's task has returned
+// without error and every value has been read.
+var ErrEndOfStream = errors.New("end of stream")
+
+// Stream is a future whose task emits a sequence of values before it
+// completes, such as pages of an API or lines of a file. The embedded
+// Future settles with the task's final error once it returns, and Abort,
+// panic recovery, and Done behave as for any future.
+type Stream struct {
+	*Future
+
+	values chan any
+}
+
+// NewStream runs task, which passes each value to emit, and returns a
+// Stream to read them from. Up to buffer values are held before emit blocks
+// until the consumer catches up. emit returns the cause once the stream is
+// aborted or its context is done, and the task should then stop.
+func NewStream(ctx context.Context, buffer int, task func(ctx context.Context, emit func(v any) error) error, opts ...Option) *Stream {
+	s := &Stream{values: make(chan any, max(buffer, 0))}
+	s.Future = NewFuture(ctx, func(ctx context.Context) (any, error) {
+		defer close(s.values)
+		return nil, task(ctx, func(v any) error {
+			select {
+			case s.values <- v:
+				return nil
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			}
+		})
+	}, opts...)
+	return s
+}
+
+// Next returns the next value, starting a lazy stream. Once every value
+// has been read, it returns ErrEndOfStream if the task succeeded and its
+// error otherwise. If ctx is done first, it returns ctx's cause and leaves
+// the stream running.
+func (s *Stream) Next(ctx context.Context) (any, error) {
+	s.once.Do(s.start)
+	select {
+	case v, ok := <-s.values:
+		if ok {
+			return v, nil
+		}
+		return nil, s.end()
+	case <-s.Future.Done():
+		// Values emitted before the stream settled are still delivered.
+		select {
+		case v, ok := <-s.values:
+			if ok {
+				return v, nil
+			}
+		default:
+		}
+		return nil, s.end()
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
+
+// All returns an iterator over the values read with Next under ctx. It
+// stops at the end of the stream or at the first error, which it yields
+// unless it is ErrEndOfStream.
+func (s *Stream) All(ctx context.Context) iter.Seq2[any, error] {
+	return func(yield func(any, error) bool) {
+		for {
+			v, err := s.Next(ctx)
+			if errors.Is(err, ErrEndOfStream) {
+				return
+			}
+			if !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// end returns the error Next reports once the values are exhausted.
+func (s *Stream) end() error {
+	_, err := s.Result()
+	if err == nil {
+		return ErrEndOfStream
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestStream_Next(t *testing.T) {
+	s := NewStream(context.Background(), 0, func(ctx context.Context, emit func(any) error) error {
+		for page := range 3 {
+			if err := emit(page); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	var pages []any
+	for {
+		v, err := s.Next(context.Background())
+		if errors.Is(err, ErrEndOfStream) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		pages = append(pages, v)
+	}
+	if !slices.Equal(pages, []any{0, 1, 2}) {
+		t.Fatalf("expected 3 pages, got %v", pages)
+	}
+	if _, err := s.Result(); err != nil {
+		t.Fatalf("expected the stream to succeed, got %v", err)
+	}
+}
+
+func TestStream_FinalError(t *testing.T) {
+	errFailed := errors.New("failed")
+	s := NewStream(context.Background(), 2, func(ctx context.Context, emit func(any) error) error {
+		emit("a")
+		emit("b")
+		return errFailed
+	})
+
+	// Buffered values are delivered before the error
+	var values []any
+	var err error
+	for v, e := range s.All(context.Background()) {
+		if e != nil {
+			err = e
+			break
+		}
+		values = append(values, v)
+	}
+	if !slices.Equal(values, []any{"a", "b"}) || !errors.Is(err, errFailed) {
+		t.Fatalf("expected a, b then errFailed, got %v, %v", values, err)
+	}
+}
+
+func TestStream_Abort(t *testing.T) {
+	emitErr := make(chan error, 1)
+	s := NewStream(context.Background(), 0, func(ctx context.Context, emit func(any) error) error {
+		for i := 0; ; i++ {
+			if err := emit(i); err != nil {
+				emitErr <- err
+				return err
+			}
+		}
+	})
+
+	if v, _ := s.Next(context.Background()); v != 0 {
+		t.Fatalf("expected 0, got %v", v)
+	}
+	s.Abort()
+
+	// The producer learns of the abort and the consumer sees it
+	if err := <-emitErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected emit to fail with context.Canceled, got %v", err)
+	}
+	for {
+		if _, err := s.Next(context.Background()); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled, got %v", err)
+			}
+			break
+		}
+	}
+}
+
+func TestStream_Panic(t *testing.T) {
+	s := NewStream(context.Background(), 0, func(ctx context.Context, emit func(any) error) error {
+		panic("boom")
+	})
+	if _, err := s.Next(context.Background()); err == nil {
+		t.Fatal("expected the panic to fail the stream")
+	}
+	if !s.Panicked() {
+		t.Fatal("expected the stream to report the panic")
+	}
+}
+
+func TestStream_NextContext(t *testing.T) {
+	s := NewStream(context.Background(), 0, func(ctx context.Context, emit func(any) error) error {
+		<-ctx.Done()
+		return nil
+	})
+	defer s.Abort()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.Next(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if s.Ready() {
+		t.Fatal("expected the stream to keep running")
+	}
+}
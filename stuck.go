@@ -0,0 +1,77 @@
+package A
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// WithAbortGrace reports tasks that ignore cancellation. If the future is
+// aborted or times out while its task is running, and the task function
+// has not returned d later, onStuck is called on its own goroutine with the
+// future and the stack of the goroutine running the task. A goroutine
+// cannot be killed, but this turns a silent leak into a report.
+func WithAbortGrace(d time.Duration, onStuck func(f *Future, stack []byte)) Option {
+	return func(f *Future) {
+		if d <= 0 || onStuck == nil {
+			f.invalidOption("WithAbortGrace", "non-positive duration %v or nil callback", d)
+			return
+		}
+		f.abortGrace = d
+		f.onStuck = onStuck
+	}
+}
+
+// watchStuck arms the stuck-task report for a future settled while its
+// task is running. It must be called with f.mu held.
+func (f *Future) watchStuck() {
+	f.stuckTimer = f.afterFunc(f.abortGrace, f.reportStuck)
+}
+
+// reportStuck reports the task if its function still has not returned.
+func (f *Future) reportStuck() {
+	f.mu.Lock()
+	running, goid := f.running, f.goid
+	f.mu.Unlock()
+	if !running {
+		return
+	}
+	f.onStuck(f, goroutineStack(goid))
+}
+
+// goroutineID returns the id of the calling goroutine, as printed in stack
+// traces.
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	// The trace starts with "goroutine 123 [running]:".
+	fields := bytes.Fields(buf[:n])
+	if len(fields) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(string(fields[1]), 10, 64)
+	return id
+}
+
+// goroutineStack returns the stack trace of the goroutine with the given
+// id, or nil if it has exited.
+func goroutineStack(id uint64) []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	header := []byte(fmt.Sprintf("goroutine %d [", id))
+	for trace := range bytes.SplitSeq(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(trace, header) {
+			return trace
+		}
+	}
+	return nil
+}
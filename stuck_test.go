@@ -0,0 +1,67 @@
+package A
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+func TestWithAbortGrace(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	started, release := make(chan struct{}), make(chan struct{})
+	type report struct {
+		f     *Future
+		stack []byte
+	}
+	reports := make(chan report, 1)
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-release // ignores ctx
+		return nil, nil
+	}, WithClock(clock), WithName("stubborn"), WithAbortGrace(time.Second, func(f *Future, stack []byte) {
+		reports <- report{f, stack}
+	}))
+	<-started
+	f.Abort()
+
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case r := <-reports:
+		t.Fatalf("expected no report within the grace period, got %s", r.stack)
+	default:
+	}
+	clock.Advance(time.Millisecond)
+	r := <-reports
+	if r.f != f || !bytes.Contains(r.stack, []byte("TestWithAbortGrace")) {
+		t.Fatalf("expected the stuck task's stack, got %s", r.stack)
+	}
+	close(release)
+}
+
+func TestWithAbortGrace_Cooperative(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	started := make(chan struct{})
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, WithClock(clock), WithAbortGrace(time.Second, func(f *Future, stack []byte) {
+		t.Errorf("unexpected report for a task that returned")
+	}))
+	<-started
+	f.Abort()
+	f.AbortAndWait(context.Background())
+	clock.Advance(time.Second)
+
+	// Futures that settle normally never arm the report
+	done := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithClock(clock), WithAbortGrace(time.Second, func(f *Future, stack []byte) {
+		t.Errorf("unexpected report for a settled future")
+	}))
+	done.Result()
+	clock.Advance(time.Second)
+}
@@ -0,0 +1,138 @@
+//go:build go1.25
+
+package A
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+// These tests run the time-based features inside a synctest bubble, where
+// time only advances once every goroutine is durably blocked, so hour-long
+// timeouts pass instantly. They fail with a deadlock or a panic if the
+// package blocks in ways synctest cannot see or leaks goroutines.
+
+func TestSynctest_Timeout(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		start := time.Now()
+		f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, WithTimeout(time.Hour))
+		if _, err := f.Result(); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed != time.Hour {
+			t.Fatalf("expected the timeout after exactly an hour, got %v", elapsed)
+		}
+		synctest.Wait()
+	})
+}
+
+func TestSynctest_Lazy(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			time.Sleep(time.Minute)
+			return "done", nil
+		}, WithLazy())
+		time.Sleep(time.Hour)
+		if _, ok := f.StartedAt(); ok {
+			t.Fatal("expected a lazy future not to start on its own")
+		}
+		if v, err := f.Result(); v != "done" || err != nil {
+			t.Fatalf("unexpected result %v, %v", v, err)
+		}
+		if exec, _ := f.ExecDuration(); exec != time.Minute {
+			t.Fatalf("expected a minute of execution, got %v", exec)
+		}
+	})
+}
+
+func TestSynctest_AbortWhileWaiting(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			select {
+			case <-time.After(time.Hour):
+				return "slept", nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		})
+		synctest.Wait()
+		f.Abort()
+		if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+		if err := f.waitExit(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestSynctest_SlowWarningAndStall(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var warnings atomic.Int32
+		f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+			ReportProgress(ctx, 1, 2)
+			<-ctx.Done()
+			return nil, context.Cause(ctx)
+		}, WithStallTimeout(time.Hour+time.Second), WithSlowWarning(time.Minute, func(*Future, time.Duration) {
+			warnings.Add(1)
+		}), WithSlowWarningEvery(time.Minute))
+		if _, err := f.Result(); !errors.Is(err, ErrStalled) {
+			t.Fatalf("expected ErrStalled, got %v", err)
+		}
+		synctest.Wait()
+		if n := warnings.Load(); n != 60 {
+			t.Fatalf("expected a warning a minute until the stall, got %d", n)
+		}
+	})
+}
+
+func TestSynctest_PoolAndDispatcher(t *testing.T) {
+	// Each bubble must leave nothing behind that the next one touches.
+	for range 2 {
+		synctest.Test(t, func(t *testing.T) {
+			p := NewPool(2, WithDefaultTaskTimeout(time.Hour))
+			f := p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			})
+			if _, err := f.Result(); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+			}
+			p.Shutdown(context.Background())
+
+			d := NewDispatcher(2)
+			defer d.Close()
+			v, err := d.Do(context.Background(), func(context.Context) (any, error) {
+				time.Sleep(time.Minute)
+				return "done", nil
+			})
+			if v != "done" || err != nil {
+				t.Fatalf("unexpected result %v, %v", v, err)
+			}
+		})
+	}
+}
+
+func TestSynctest_SleepThenOutside(t *testing.T) {
+	// Many sleeps inside the bubble, then some outside it: a timer made in
+	// the bubble must never be reused out of it.
+	synctest.Test(t, func(t *testing.T) {
+		for range 10 {
+			if err := SleepCtx(context.Background(), time.Hour); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	})
+	for range 10 {
+		if err := SleepCtx(context.Background(), time.Microsecond); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
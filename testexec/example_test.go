@@ -0,0 +1,47 @@
+package testexec_test
+
+import (
+	"context"
+	"fmt"
+
+	A "github.com/ongniud/future"
+	"github.com/ongniud/future/testexec"
+)
+
+// This is TestFuture_Ready, which sleeps 100ms so the future is still
+// pending when it checks, converted to step the task by hand instead.
+func Example() {
+	exec := testexec.New()
+	f := A.NewFuture(context.Background(), func(context.Context) (any, error) {
+		return "ready success", nil
+	}, A.WithExecutor(exec))
+
+	fmt.Println("ready before step:", f.Ready())
+	exec.Step()
+	fmt.Println("ready after step:", f.Ready())
+	fmt.Println(f.Result())
+	// Output:
+	// ready before step: false
+	// ready after step: true
+	// ready success <nil>
+}
+
+// This is TestPool_Priority, which blocks the pool's only worker so tasks
+// queue behind it, converted to leave them queued until the test runs them.
+func Example_pool() {
+	exec := testexec.New()
+	p := A.NewPool(1, A.WithPoolExecutor(exec))
+	for _, priority := range []int{1, 5, 10} {
+		p.Submit(context.Background(), func(context.Context) (any, error) {
+			fmt.Println("running priority", priority)
+			return nil, nil
+		}, A.WithPriority(priority))
+	}
+
+	exec.RunAll()
+	p.Shutdown(context.Background())
+	// Output:
+	// running priority 10
+	// running priority 5
+	// running priority 1
+}
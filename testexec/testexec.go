@@ -0,0 +1,78 @@
+// Package testexec provides a deterministic executor for testing code that
+// uses futures without sleeps or real concurrency.
+package testexec
+
+import (
+	"sync"
+)
+
+// Executor queues the tasks of futures created with A.WithExecutor until
+// the test runs them, in order, on its own goroutine with Step or RunAll.
+// It is safe for concurrent use.
+type Executor struct {
+	mu    sync.Mutex
+	queue []func()
+}
+
+// New creates an empty Executor.
+func New() *Executor {
+	return &Executor{}
+}
+
+// Execute queues run. It implements A.Executor.
+func (e *Executor) Execute(run func()) {
+	e.mu.Lock()
+	e.queue = append(e.queue, run)
+	e.mu.Unlock()
+}
+
+// Len returns the number of queued tasks.
+func (e *Executor) Len() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.queue)
+}
+
+// Step runs the oldest queued task on the calling goroutine. It reports
+// false if no task was queued.
+func (e *Executor) Step() bool {
+	e.mu.Lock()
+	if len(e.queue) == 0 {
+		e.mu.Unlock()
+		return false
+	}
+	run := e.queue[0]
+	e.queue[0] = nil
+	e.queue = e.queue[1:]
+	e.mu.Unlock()
+	run()
+	return true
+}
+
+// StepLast runs the newest queued task on the calling goroutine, for tests
+// that need tasks to finish out of order. It reports false if no task was
+// queued.
+func (e *Executor) StepLast() bool {
+	e.mu.Lock()
+	n := len(e.queue)
+	if n == 0 {
+		e.mu.Unlock()
+		return false
+	}
+	run := e.queue[n-1]
+	e.queue[n-1] = nil
+	e.queue = e.queue[:n-1]
+	e.mu.Unlock()
+	run()
+	return true
+}
+
+// RunAll steps until no task is queued, including tasks queued by the ones
+// it runs, and returns how many it ran.
+func (e *Executor) RunAll() int {
+	n := 0
+	for e.Step() {
+		n++
+	}
+	return n
+}
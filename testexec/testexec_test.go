@@ -0,0 +1,136 @@
+package testexec_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	A "github.com/ongniud/future"
+	"github.com/ongniud/future/testexec"
+)
+
+// TestReady is TestFuture_Ready without the sleep: the future stays pending
+// until the test steps the executor.
+func TestReady(t *testing.T) {
+	exec := testexec.New()
+	f := A.NewFuture(context.Background(), func(context.Context) (any, error) {
+		return "ready success", nil
+	}, A.WithExecutor(exec))
+
+	if f.Ready() || f.State() != A.Pending {
+		t.Fatalf("expected future to be pending")
+	}
+	if !exec.Step() {
+		t.Fatalf("expected a queued task")
+	}
+	if v, err := f.Result(); v != "ready success" || err != nil {
+		t.Fatalf("unexpected result: %v, %v", v, err)
+	}
+	if exec.Step() {
+		t.Fatalf("expected no queued task")
+	}
+}
+
+// TestGroupFirstError is TestGroup_FirstError without the sleep: the test
+// decides the order in which members settle.
+func TestGroupFirstError(t *testing.T) {
+	exec := testexec.New()
+	g := A.NewGroup(context.Background())
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+	g.Go(func(context.Context) (any, error) {
+		return nil, errSecond
+	}, A.WithExecutor(exec))
+	g.Go(func(context.Context) (any, error) {
+		return nil, errFirst
+	}, A.WithExecutor(exec))
+
+	exec.StepLast()
+	if exec.Len() != 1 {
+		t.Fatalf("expected one queued task, got %d", exec.Len())
+	}
+	exec.RunAll()
+	if err := g.Wait(); !errors.Is(err, errFirst) {
+		t.Fatalf("expected first error, got %v", err)
+	}
+}
+
+func TestChild(t *testing.T) {
+	exec := testexec.New()
+	parent := A.NewFuture(context.Background(), func(context.Context) (any, error) {
+		return 1, nil
+	}, A.WithExecutor(exec))
+	child := parent.Child(func(_ context.Context, v any) (any, error) {
+		return v.(int) + 1, nil
+	}, A.WithExecutor(exec))
+
+	exec.Step()
+	if !parent.Ready() || child.Ready() {
+		t.Fatalf("expected only the parent to have run")
+	}
+	// The child was queued when the parent settled.
+	if n := exec.RunAll(); n != 1 {
+		t.Fatalf("expected one more task, ran %d", n)
+	}
+	if v, _ := child.Result(); v != 2 {
+		t.Fatalf("unexpected child result %v", v)
+	}
+}
+
+// TestPoolPriority is TestPool_Priority without the blocked worker: the
+// tasks stay queued until the test steps the executor.
+func TestPoolPriority(t *testing.T) {
+	exec := testexec.New()
+	p := A.NewPool(1, A.WithPoolExecutor(exec))
+
+	var order []int
+	for _, priority := range []int{1, 5, 1, 10, 5} {
+		p.Submit(context.Background(), func(context.Context) (any, error) {
+			order = append(order, priority)
+			return nil, nil
+		}, A.WithPriority(priority))
+	}
+	if n := exec.Len(); n != 1 {
+		t.Fatalf("expected one outstanding run for a pool of one, got %d", n)
+	}
+	if n := exec.RunAll(); n != 5 {
+		t.Fatalf("expected 5 runs, got %d", n)
+	}
+	if want := []int{10, 5, 5, 1, 1}; !slices.Equal(order, want) {
+		t.Fatalf("expected order %v, got %v", want, order)
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPoolKeyed(t *testing.T) {
+	exec := testexec.New()
+	p := A.NewPool(4, A.WithPoolExecutor(exec))
+	defer p.Shutdown(context.Background())
+
+	var order []string
+	task := func(name string) func(context.Context) (any, error) {
+		return func(context.Context) (any, error) {
+			order = append(order, name)
+			return name, nil
+		}
+	}
+	first := p.SubmitKeyed(context.Background(), "user", task("first"))
+	second := p.SubmitKeyed(context.Background(), "user", task("second"))
+	other := p.SubmitKeyed(context.Background(), "other", task("other"))
+
+	// Only the head of each key is runnable
+	if n := exec.Len(); n != 2 {
+		t.Fatalf("expected 2 runs, got %d", n)
+	}
+	exec.RunAll()
+	if want := []string{"first", "second", "other"}; !slices.Equal(order, want) {
+		t.Fatalf("expected order %v, got %v", want, order)
+	}
+	for _, f := range []*A.Future{first, second, other} {
+		if !f.Ready() {
+			t.Fatalf("expected every task to have run")
+		}
+	}
+}
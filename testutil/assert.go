@@ -0,0 +1,78 @@
+package testutil
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	A "github.com/ongniud/future"
+)
+
+// WaitTimeout bounds how long the assertions without an explicit duration
+// wait for a future to settle.
+var WaitTimeout = 10 * time.Second
+
+// AssertCompletesWithin fails the test unless f settles within d, and
+// returns its result. It does not start lazy futures.
+func AssertCompletesWithin(t testing.TB, f *A.Future, d time.Duration) (any, error) {
+	t.Helper()
+	if !settles(f, d) {
+		t.Fatalf("%v did not settle within %v: %s", f, d, describe(f))
+	}
+	return f.Result()
+}
+
+// AssertFailsWith fails the test unless f settles within WaitTimeout with
+// an error matching target according to errors.Is.
+func AssertFailsWith(t testing.TB, f *A.Future, target error) {
+	t.Helper()
+	if !settles(f, WaitTimeout) {
+		t.Fatalf("%v did not settle within %v: %s", f, WaitTimeout, describe(f))
+	}
+	if _, err := f.Result(); !errors.Is(err, target) {
+		t.Fatalf("%v failed with %v, want %v: %s", f, err, target, describe(f))
+	}
+}
+
+// AssertAborted fails the test unless f settles within WaitTimeout through
+// Abort or AbortWithError.
+func AssertAborted(t testing.TB, f *A.Future) {
+	t.Helper()
+	if !settles(f, WaitTimeout) {
+		t.Fatalf("%v was not aborted within %v: %s", f, WaitTimeout, describe(f))
+	}
+	if !f.Snapshot().Aborted {
+		t.Fatalf("%v settled without being aborted: %s", f, describe(f))
+	}
+}
+
+// AssertNeverStarts fails the test if f's task starts within d, for lazy
+// futures nobody should run and futures aborted before they start. It
+// always waits for d, or until f settles without having started.
+func AssertNeverStarts(t testing.TB, f *A.Future, d time.Duration) {
+	t.Helper()
+	settles(f, d)
+	if _, started := f.StartedAt(); started {
+		t.Fatalf("%v started: %s", f, describe(f))
+	}
+}
+
+// settles waits up to d for f to settle. It reports whether it did. The
+// timer is stopped on return, so a timed-out assertion leaves nothing
+// running.
+func settles(f *A.Future, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-f.Done():
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// describe formats f's snapshot for failure messages.
+func describe(f *A.Future) string {
+	return fmt.Sprintf("%+v", f.Snapshot())
+}
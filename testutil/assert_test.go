@@ -0,0 +1,93 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	A "github.com/ongniud/future"
+	"github.com/ongniud/future/testutil"
+)
+
+// recorder captures a failure instead of failing the test.
+type recorder struct {
+	testing.TB
+	failure string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failure = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// failure runs assert and returns the failure it reports, if any.
+func failure(t *testing.T, assert func(tb testing.TB)) string {
+	r := &recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert(r)
+	}()
+	<-done
+	return r.failure
+}
+
+func TestAssertions(t *testing.T) {
+	errBoom := errors.New("boom")
+	failed := A.NewFuture(context.Background(), func(context.Context) (any, error) {
+		return nil, errBoom
+	}, A.WithName("fetch"))
+	if _, err := testutil.AssertCompletesWithin(t, failed, time.Second); !errors.Is(err, errBoom) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	testutil.AssertFailsWith(t, failed, errBoom)
+
+	lazy := A.NewFuture(context.Background(), func(context.Context) (any, error) {
+		return nil, nil
+	}, A.WithLazy(), A.WithName("lazy"))
+	testutil.AssertNeverStarts(t, lazy, 10*time.Millisecond)
+	lazy.Abort()
+	testutil.AssertAborted(t, lazy)
+}
+
+func TestAssertionFailures(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	stuck := A.NewFuture(context.Background(), func(context.Context) (any, error) {
+		<-release
+		return nil, nil
+	}, A.WithName("stuck"))
+
+	msg := failure(t, func(tb testing.TB) {
+		testutil.AssertCompletesWithin(tb, stuck, time.Millisecond)
+	})
+	if !strings.Contains(msg, "name=stuck") || !strings.Contains(msg, "State:Running") {
+		t.Fatalf("expected the failure to name the future and its state, got %q", msg)
+	}
+
+	done := A.NewFuture(context.Background(), func(context.Context) (any, error) {
+		return "ok", nil
+	}, A.WithName("done"))
+	done.Result()
+	for want, assert := range map[string]func(testing.TB){
+		"failed with <nil>": func(tb testing.TB) {
+			testutil.AssertFailsWith(tb, done, context.Canceled)
+		},
+		"without being aborted": func(tb testing.TB) {
+			testutil.AssertAborted(tb, done)
+		},
+		"started": func(tb testing.TB) {
+			testutil.AssertNeverStarts(tb, done, time.Millisecond)
+		},
+	} {
+		if msg := failure(t, assert); !strings.Contains(msg, want) {
+			t.Fatalf("expected a failure containing %q, got %q", want, msg)
+		}
+	}
+}
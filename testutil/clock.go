@@ -0,0 +1,17 @@
+// Package testutil provides helpers for testing code that uses futures.
+package testutil
+
+import (
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+// FakeClock is an A.Clock whose time only moves when the test advances it.
+// Timers fire on the goroutine that calls Advance, in deadline order.
+type FakeClock = fakeclock.Clock
+
+// NewFakeClock creates a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return fakeclock.New(now)
+}
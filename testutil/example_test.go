@@ -0,0 +1,41 @@
+package testutil_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ongniud/future/testutil"
+)
+
+// This drives the all aggregator from TestMockFuture_All through a table of
+// scripts, each scripting the same three mocks differently.
+func Example() {
+	scripts := []struct {
+		name   string
+		script func(a, b, c *testutil.MockFuture)
+	}{{
+		name: "all succeed",
+		script: func(a, b, c *testutil.MockFuture) {
+			a.CompleteNow(1, nil)
+			b.CompleteAfter(time.Millisecond, 2, nil)
+			c.CompleteNow(3, nil)
+		},
+	}, {
+		name: "failure aborts the slow branch",
+		script: func(a, b, c *testutil.MockFuture) {
+			a.CompleteNow(1, nil)
+			b.CompleteNow(nil, errors.New("boom"))
+			// c never completes.
+		},
+	}}
+	for _, s := range scripts {
+		a, b, c := testutil.NewMockFuture(), testutil.NewMockFuture(), testutil.NewMockFuture()
+		s.script(a, b, c)
+		values, err := all(a.Future, b.Future, c.Future)
+		fmt.Printf("%s: %v, %v; aborted %v %v %v\n", s.name, values, err, a.Aborted(), b.Aborted(), c.Aborted())
+	}
+	// Output:
+	// all succeed: [1 2 3], <nil>; aborted false false false
+	// failure aborts the slow branch: [], boom; aborted false false true
+}
@@ -0,0 +1,84 @@
+package testutil
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	A "github.com/ongniud/future"
+)
+
+// MockFuture is a real *A.Future whose result the test scripts, for
+// testing code that consumes futures. Pass m.Future to the code under test.
+type MockFuture struct {
+	*A.Future
+
+	results chan mockResult
+
+	mu   sync.Mutex
+	gate <-chan struct{}
+}
+
+// mockResult is a scripted result.
+type mockResult struct {
+	value any
+	err   error
+}
+
+// NewMockFuture creates a future that stays running until the test
+// completes it or the future is aborted.
+func NewMockFuture(opts ...A.Option) *MockFuture {
+	m := &MockFuture{results: make(chan mockResult, 1)}
+	m.Future = A.NewFuture(context.Background(), m.run, opts...)
+	return m
+}
+
+// run is the task of the mock future.
+func (m *MockFuture) run(ctx context.Context) (any, error) {
+	var r mockResult
+	select {
+	case r = <-m.results:
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+	m.mu.Lock()
+	gate := m.gate
+	m.mu.Unlock()
+	if gate != nil {
+		select {
+		case <-gate:
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		}
+	}
+	return r.value, r.err
+}
+
+// CompleteNow settles the future with value and err. Only the first
+// completion counts.
+func (m *MockFuture) CompleteNow(value any, err error) {
+	select {
+	case m.results <- mockResult{value: value, err: err}:
+	default:
+	}
+}
+
+// CompleteAfter completes the future with value and err once d has elapsed.
+func (m *MockFuture) CompleteAfter(d time.Duration, value any, err error) {
+	time.AfterFunc(d, func() {
+		m.CompleteNow(value, err)
+	})
+}
+
+// BlockUntil holds the completion back until ch is closed. Call it before
+// completing the future.
+func (m *MockFuture) BlockUntil(ch <-chan struct{}) {
+	m.mu.Lock()
+	m.gate = ch
+	m.mu.Unlock()
+}
+
+// Aborted reports whether the code under test aborted the future.
+func (m *MockFuture) Aborted() bool {
+	return m.Snapshot().Aborted
+}
@@ -0,0 +1,97 @@
+package testutil_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	A "github.com/ongniud/future"
+	"github.com/ongniud/future/testutil"
+)
+
+// all is an All-style aggregator of the kind consumer code writes: it
+// waits for every future and, on the first failure, aborts the rest.
+func all(fs ...*A.Future) ([]any, error) {
+	values := make([]any, len(fs))
+	for i, f := range fs {
+		v, err := f.Result()
+		if err != nil {
+			for _, other := range fs {
+				other.Abort()
+			}
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func TestMockFuture_All(t *testing.T) {
+	errBoom := errors.New("boom")
+	tests := []struct {
+		name        string
+		script      func(a, b, c *testutil.MockFuture)
+		wantValues  []any
+		wantErr     error
+		wantAborted []bool
+	}{{
+		name: "all succeed",
+		script: func(a, b, c *testutil.MockFuture) {
+			a.CompleteNow(1, nil)
+			b.CompleteAfter(time.Millisecond, 2, nil)
+			c.CompleteNow(3, nil)
+		},
+		wantValues:  []any{1, 2, 3},
+		wantAborted: []bool{false, false, false},
+	}, {
+		name: "failure aborts the slow branch",
+		script: func(a, b, c *testutil.MockFuture) {
+			a.CompleteNow(1, nil)
+			b.CompleteNow(nil, errBoom)
+			// c never completes.
+		},
+		wantErr:     errBoom,
+		wantAborted: []bool{false, false, true},
+	}, {
+		name: "blocked branch is waited for",
+		script: func(a, b, c *testutil.MockFuture) {
+			release := make(chan struct{})
+			a.BlockUntil(release)
+			a.CompleteNow(1, nil)
+			b.CompleteNow(2, nil)
+			c.CompleteNow(3, nil)
+			time.AfterFunc(time.Millisecond, func() {
+				close(release)
+			})
+		},
+		wantValues:  []any{1, 2, 3},
+		wantAborted: []bool{false, false, false},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b, c := testutil.NewMockFuture(), testutil.NewMockFuture(), testutil.NewMockFuture()
+			tt.script(a, b, c)
+			values, err := all(a.Future, b.Future, c.Future)
+			if !errors.Is(err, tt.wantErr) || !slices.Equal(values, tt.wantValues) {
+				t.Fatalf("all() = %v, %v; want %v, %v", values, err, tt.wantValues, tt.wantErr)
+			}
+			for i, m := range []*testutil.MockFuture{a, b, c} {
+				if m.Aborted() != tt.wantAborted[i] {
+					t.Fatalf("mock %d: aborted = %v, want %v", i, m.Aborted(), tt.wantAborted[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMockFuture_AbortedWhileBlocked(t *testing.T) {
+	m := testutil.NewMockFuture(A.WithName("blocked"))
+	m.BlockUntil(make(chan struct{}))
+	m.CompleteNow("late", nil)
+	m.Abort()
+	testutil.AssertAborted(t, m.Future)
+	if !m.Aborted() {
+		t.Fatal("expected the abort to be recorded")
+	}
+}
@@ -0,0 +1,119 @@
+package A
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TaskTimeoutError reports that a task ran into its own timeout, set with
+// WithTimeout, WithTimeoutCause, WithDeadlineCause, or a pool's
+// WithDefaultTaskTimeout. It wraps context.DeadlineExceeded and the cause,
+// if one was given.
+type TaskTimeoutError struct {
+	// Limit is the configured timeout, or the time left until the deadline
+	// when the task started.
+	Limit time.Duration
+	// Elapsed is how long the task had been running.
+	Elapsed time.Duration
+	// Cause is the cause given with WithTimeoutCause or WithDeadlineCause.
+	Cause error
+}
+
+func (e *TaskTimeoutError) Error() string {
+	msg := fmt.Sprintf("task timed out after %v (limit %v)", roundElapsed(e.Elapsed), e.Limit)
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+func (e *TaskTimeoutError) Unwrap() []error {
+	if e.Cause != nil {
+		return []error{context.DeadlineExceeded, e.Cause}
+	}
+	return []error{context.DeadlineExceeded}
+}
+
+// deadlineCause is what a task's own timeout cancels its context with when
+// no cause was given. Each run gets its own, so a deadline inherited from
+// the parent context, whose cause is plain context.DeadlineExceeded, is
+// never mistaken for the task's timeout.
+type deadlineCause struct {
+	_ byte // distinct pointers
+}
+
+func (*deadlineCause) Error() string {
+	return context.DeadlineExceeded.Error()
+}
+
+func (*deadlineCause) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// WaitTimeoutError reports that a caller gave up waiting for a future, which
+// may still settle later. It wraps context.DeadlineExceeded.
+type WaitTimeoutError struct {
+	// Limit is how long the caller was willing to wait.
+	Limit time.Duration
+	// Elapsed is how long the caller actually waited.
+	Elapsed time.Duration
+}
+
+func (e *WaitTimeoutError) Error() string {
+	return fmt.Sprintf("wait timed out after %v (limit %v)", roundElapsed(e.Elapsed), e.Limit)
+}
+
+func (e *WaitTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// roundElapsed rounds d for display, to milliseconds from a second up.
+func roundElapsed(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(time.Microsecond)
+}
+
+// ResultTimeout waits up to d for the result, like Result, measured on the
+// future's clock. If d passes first, it returns a *WaitTimeoutError and
+// leaves the future running.
+func (f *Future) ResultTimeout(d time.Duration) (any, error) {
+	f.consume()
+	f.once.Do(f.start)
+	if !f.ready.Load() {
+		start := f.now()
+		expired := make(chan struct{})
+		timer := f.afterFunc(d, func() {
+			close(expired)
+		})
+		defer timer.Stop()
+		select {
+		case <-f.doneChan():
+		case <-expired:
+			return nil, f.attribute(&WaitTimeoutError{Limit: d, Elapsed: f.since(start)})
+		}
+	}
+	f.mu.Lock()
+	item, err := f.take()
+	f.mu.Unlock()
+	return f.cloned(item), err
+}
+
+// limit returns how long the task may run once it starts, and whether it
+// is bounded at all.
+func (f *Future) limit() (time.Duration, bool) {
+	if !f.deadline.IsZero() {
+		return max(f.deadline.Sub(f.now()), 0), true
+	}
+	return f.timeout, f.timeout > 0
+}
+
+// timedOut returns the error of a task that ran into its timeout.
+func (f *Future) timedOut(limit time.Duration) error {
+	f.mu.Lock()
+	startedAt := f.startedAt
+	f.mu.Unlock()
+	return f.attribute(&TaskTimeoutError{Limit: limit, Elapsed: f.since(startedAt), Cause: f.timeoutCause})
+}
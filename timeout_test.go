@@ -0,0 +1,174 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+func TestTaskTimeoutError(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	started := make(chan struct{})
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, WithTimeout(2*time.Second), WithClock(clock))
+	<-started
+	clock.BlockUntil(1)
+	clock.Advance(2003 * time.Millisecond)
+
+	_, err := f.Result()
+	var timeoutErr *TaskTimeoutError
+	if !errors.As(err, &timeoutErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a TaskTimeoutError wrapping context.DeadlineExceeded, got %v", err)
+	}
+	if timeoutErr.Limit != 2*time.Second || timeoutErr.Elapsed < 2*time.Second {
+		t.Fatalf("expected a 2s limit and at least 2s elapsed, got %+v", timeoutErr)
+	}
+	if got, want := (&TaskTimeoutError{Limit: 2 * time.Second, Elapsed: 2003417 * time.Microsecond}).Error(), "task timed out after 2.003s (limit 2s)"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestWithTimeoutCause(t *testing.T) {
+	errSlowDB := errors.New("database query budget exceeded")
+	for name, clock := range map[string]*fakeclock.Clock{"real clock": nil, "fake clock": fakeclock.New(time.Now())} {
+		t.Run(name, func(t *testing.T) {
+			opts := []Option{WithTimeoutCause(time.Millisecond, errSlowDB)}
+			if clock != nil {
+				opts = append(opts, WithClock(clock))
+			}
+			causes := make(chan error, 1)
+			f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+				causes <- context.Cause(ctx)
+				return nil, ctx.Err()
+			}, opts...)
+			if clock != nil {
+				clock.BlockUntil(1)
+				clock.Advance(time.Millisecond)
+			}
+
+			if cause := <-causes; cause != errSlowDB {
+				t.Fatalf("expected the task to see the cause, got %v", cause)
+			}
+			_, err := f.Result()
+			var timeoutErr *TaskTimeoutError
+			if !errors.As(err, &timeoutErr) || !errors.Is(err, errSlowDB) || !errors.Is(err, context.DeadlineExceeded) || !IsTimeout(err) {
+				t.Fatalf("expected a TaskTimeoutError wrapping the cause, got %v", err)
+			}
+			if timeoutErr.Cause != errSlowDB {
+				t.Fatalf("expected the cause on the error, got %+v", timeoutErr)
+			}
+		})
+	}
+
+	if got, want := (&TaskTimeoutError{Limit: time.Second, Elapsed: time.Second, Cause: errSlowDB}).Error(), "task timed out after 1s (limit 1s): database query budget exceeded"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestWithDeadlineCause(t *testing.T) {
+	errShutdown := errors.New("shutdown deadline")
+	clock := fakeclock.New(time.Now())
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, context.Cause(ctx)
+	}, WithDeadlineCause(clock.Now().Add(3*time.Second), errShutdown), WithClock(clock), WithLazy())
+	clock.Advance(time.Second)
+	go f.Result()
+	clock.BlockUntil(1)
+	clock.Advance(2 * time.Second)
+
+	_, err := f.Result()
+	var timeoutErr *TaskTimeoutError
+	if !errors.As(err, &timeoutErr) || !errors.Is(err, errShutdown) {
+		t.Fatalf("expected a TaskTimeoutError wrapping the cause, got %v", err)
+	}
+	if timeoutErr.Limit != 2*time.Second {
+		t.Fatalf("expected the time left at start as the limit, got %v", timeoutErr.Limit)
+	}
+
+	// A task starting past its deadline times out at once
+	past := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, WithDeadlineCause(time.Now().Add(-time.Second), errShutdown))
+	if _, err := past.Result(); !errors.Is(err, errShutdown) || !IsTimeout(err) {
+		t.Fatalf("expected an immediate timeout, got %v", err)
+	}
+}
+
+func TestFuture_ResultTimeout(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	release := make(chan struct{})
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-release
+		return "late", nil
+	}, WithClock(clock), WithName("fetch"))
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := f.ResultTimeout(time.Second)
+		errs <- err
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+
+	err := <-errs
+	var waitErr *WaitTimeoutError
+	if !errors.As(err, &waitErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a WaitTimeoutError wrapping context.DeadlineExceeded, got %v", err)
+	}
+	if waitErr.Limit != time.Second || waitErr.Elapsed != time.Second {
+		t.Fatalf("expected a 1s wait, got %+v", waitErr)
+	}
+	if want := `future "fetch": wait timed out after 1s (limit 1s)`; err.Error() != want {
+		t.Fatalf("expected %q, got %q", want, err.Error())
+	}
+
+	// The future keeps running after the wait gives up
+	close(release)
+	if v, err := f.ResultTimeout(time.Hour); v != "late" || err != nil {
+		t.Fatalf("expected late, got %v, %v", v, err)
+	}
+	if n := clock.Timers(); n != 0 {
+		t.Fatalf("expected the wait timers to be released, got %d", n)
+	}
+}
+
+func TestWithTimeout_ParentDeadline(t *testing.T) {
+	// A parent deadline that passes first is the parent's error, not the
+	// task's timeout, with or without a timeout cause.
+	errParent := errors.New("request budget exceeded")
+	errOwn := errors.New("task budget exceeded")
+	tests := map[string]Option{
+		"WithTimeout":      WithTimeout(time.Hour),
+		"WithTimeoutCause": WithTimeoutCause(time.Hour, errOwn),
+	}
+	for name, opt := range tests {
+		t.Run(name, func(t *testing.T) {
+			parent, cancel := context.WithTimeoutCause(context.Background(), 20*time.Millisecond, errParent)
+			defer cancel()
+			f := NewFuture(parent, func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+				return nil, context.Cause(ctx)
+			}, opt)
+			_, err := f.Result()
+			var timeoutErr *TaskTimeoutError
+			if errors.As(err, &timeoutErr) || errors.Is(err, errOwn) {
+				t.Fatalf("expected the parent's error, got the task timeout %v", err)
+			}
+			if !errors.Is(err, errParent) {
+				t.Fatalf("expected the parent's cause, got %v", err)
+			}
+			if _, _, meta := f.ResultMeta(); meta.Outcome == TimedOut {
+				t.Fatalf("expected the future not to count as timed out")
+			}
+		})
+	}
+}
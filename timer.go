@@ -0,0 +1,123 @@
+package A
+
+import (
+	"context"
+	"time"
+)
+
+// SleepCtx pauses for d, or until ctx is done, in which case it returns
+// ctx's cause. Unlike a select on time.After, it never leaves a timer
+// running when ctx wins. Timers are not pooled: one created inside a
+// testing/synctest bubble cannot be reset outside it.
+func SleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return context.Cause(ctx)
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// After returns a future that succeeds with the elapsed time once d has
+// passed, measured on its clock. It runs no goroutine and no task: the
+// timer settles it. If ctx is done first, it fails with ctx's cause, and
+// Abort settles it at once; either way the timer is released immediately.
+// WithLazy has no effect: the timer starts when After is called.
+func After(ctx context.Context, d time.Duration, opts ...Option) *Future {
+	ctx = orBackground(ctx)
+	f := newFuture(ctx, nil, opts...)
+	if f.rejectInvalid() {
+		return f
+	}
+	f.once.Do(func() {})
+	start := f.now()
+	timer := f.afterFunc(d, func() {
+		f.settle(f.since(start), nil)
+	})
+	stop := context.AfterFunc(ctx, func() {
+		f.settle(nil, context.Cause(ctx))
+	})
+	f.whenDone(func() {
+		timer.Stop()
+		stop()
+	})
+	return f
+}
+
+// At returns a future that runs task once the clock reaches t, or at once
+// if t has already passed. The wait is a timer rather than a goroutine, so
+// distant times cost nothing while waiting. Aborting the future, or ctx
+// being done, during the wait releases the timer and settles the future
+// without running the task; afterwards it cancels the task as usual.
+// Result does not start the task early, and WithLazy has no effect.
+func At(ctx context.Context, t time.Time, task func(context.Context) (any, error), opts ...Option) *Future {
+	ctx = orBackground(ctx)
+	f := newFuture(ctx, task, opts...)
+	if f.rejectInvalid() {
+		return f
+	}
+	f.once.Do(func() {})
+	d := t.Sub(f.now())
+	if d <= 0 {
+		f.launch()
+		return f
+	}
+	timer := f.afterFunc(d, f.launch)
+	f.whenDone(func() {
+		timer.Stop()
+	})
+	context.AfterFunc(f.ctx, func() {
+		// Abort settles the future itself; a done ctx while still waiting
+		// is left to run, which settles the future with the cause.
+		if ctx.Err() != nil && timer.Stop() {
+			f.launch()
+		}
+	})
+	return f
+}
+
+// DelayResult returns a future that settles with f's result, but no sooner
+// than d after f was created, on f's clock, to enforce a minimum response
+// time. The task itself is not delayed, and a task that takes longer than d
+// adds no wait. Aborting either future settles the returned one at once
+// with the abort error, and aborting the returned future also aborts f.
+// Waiting on the returned future starts f if it is lazy.
+func (f *Future) DelayResult(d time.Duration) *Future {
+	delayed := newFuture(f.base, nil, WithClock(f.clock))
+	delayed.parent = f
+	delayed.clone = f.clone
+	deadline := f.createdAt.Add(d)
+	f.whenDone(func() {
+		value, err := f.peek()
+		if f.aborted() {
+			delayed.AbortWithError(err)
+			return
+		}
+		remaining := deadline.Sub(f.now())
+		if remaining <= 0 {
+			delayed.settle(value, err)
+			return
+		}
+		timer := f.afterFunc(remaining, func() {
+			delayed.settle(value, err)
+		})
+		delayed.whenDone(func() {
+			timer.Stop()
+		})
+	})
+	delayed.whenDone(func() {
+		if delayed.aborted() {
+			_, err := delayed.peek()
+			f.AbortWithError(err)
+		}
+	})
+	if !f.lazy {
+		delayed.once.Do(delayed.start)
+	}
+	return delayed
+}
@@ -0,0 +1,257 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+func TestAfter(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	f := After(context.Background(), time.Minute, WithClock(clock))
+	clock.Advance(59 * time.Second)
+	if f.Ready() {
+		t.Fatal("expected the timer to be pending")
+	}
+	clock.Advance(time.Second)
+	if v, err := f.Result(); v != time.Minute || err != nil {
+		t.Fatalf("expected the elapsed time, got %v, %v", v, err)
+	}
+}
+
+func TestAfter_AbortReleasesTimer(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	f := After(context.Background(), time.Minute, WithClock(clock))
+	f.Abort()
+	if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if n := clock.Timers(); n != 0 {
+		t.Fatalf("expected the timer to be released, got %d", n)
+	}
+}
+
+func TestAfter_ContextDone(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	f := After(ctx, time.Minute, WithClock(clock))
+	cancel()
+	if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if n := clock.Timers(); n != 0 {
+		t.Fatalf("expected the timer to be released, got %d", n)
+	}
+}
+
+func TestAfter_RealClock(t *testing.T) {
+	f := After(context.Background(), time.Millisecond)
+	if v, err := f.Result(); v.(time.Duration) < time.Millisecond || err != nil {
+		t.Fatalf("expected at least 1ms to elapse, got %v, %v", v, err)
+	}
+}
+
+func TestAt(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	ran := make(chan struct{})
+	f := At(context.Background(), clock.Now().Add(time.Hour), func(ctx context.Context) (any, error) {
+		close(ran)
+		return "ran", nil
+	}, WithClock(clock), WithSync())
+
+	clock.Advance(59 * time.Minute)
+	select {
+	case <-ran:
+		t.Fatal("expected the task to wait for the time")
+	default:
+	}
+	clock.Advance(time.Minute)
+	if v, err := f.Result(); v != "ran" || err != nil {
+		t.Fatalf("expected the task to run, got %v, %v", v, err)
+	}
+}
+
+func TestAt_Past(t *testing.T) {
+	f := At(context.Background(), time.Now().Add(-time.Hour), func(ctx context.Context) (any, error) {
+		return "ran", nil
+	})
+	if v, _ := f.Result(); v != "ran" {
+		t.Fatalf("expected the task to run at once, got %v", v)
+	}
+}
+
+func TestAt_AbortWhileWaiting(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	for name, abort := range map[string]func(*Future){
+		"abort":   (*Future).Abort,
+		"context": func(*Future) { cancel() },
+	} {
+		f := At(ctx, clock.Now().Add(time.Hour), func(ctx context.Context) (any, error) {
+			t.Errorf("%s: the task should not run", name)
+			return nil, nil
+		}, WithClock(clock))
+		abort(f)
+		if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+			t.Fatalf("%s: expected context.Canceled, got %v", name, err)
+		}
+		if n := clock.Timers(); n != 0 {
+			t.Fatalf("%s: expected the timer to be released, got %d", name, n)
+		}
+	}
+}
+
+func TestAt_AbortWhileRunning(t *testing.T) {
+	started := make(chan struct{})
+	f := At(context.Background(), time.Now(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	<-started
+	if err := f.AbortAndWait(context.Background()); err != nil {
+		t.Fatalf("expected the task to stop, got %v", err)
+	}
+}
+
+func TestDelayResult(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "fast", nil
+	}, WithClock(clock), WithSync())
+	delayed := f.DelayResult(time.Second)
+
+	// The task has finished, but the result is withheld
+	if !f.Ready() || delayed.Ready() {
+		t.Fatal("expected only the source to be settled")
+	}
+	clock.Advance(999 * time.Millisecond)
+	if delayed.Ready() {
+		t.Fatal("expected the result to be withheld until d has passed")
+	}
+	clock.Advance(time.Millisecond)
+	if v, err := delayed.Result(); v != "fast" || err != nil {
+		t.Fatalf("expected fast, got %v, %v", v, err)
+	}
+}
+
+func TestDelayResult_SlowTask(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	release := make(chan struct{})
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-release
+		return "slow", nil
+	}, WithClock(clock))
+	delayed := f.DelayResult(time.Second)
+	clock.Advance(2 * time.Second)
+	close(release)
+
+	// No wait is added after a task slower than d
+	if v, _ := delayed.Result(); v != "slow" {
+		t.Fatalf("expected slow, got %v", v)
+	}
+}
+
+func TestDelayResult_Abort(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "fast", nil
+	}, WithClock(clock), WithSync())
+	delayed := f.DelayResult(time.Second)
+
+	// Aborting cuts the delay short
+	delayed.Abort()
+	if _, err := delayed.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if n := clock.Timers(); n != 0 {
+		t.Fatalf("expected the timer to be released, got %d", n)
+	}
+
+	// Aborting the source settles the delayed future at once
+	started := make(chan struct{})
+	f = NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, WithClock(clock))
+	delayed = f.DelayResult(time.Second)
+	<-started
+	f.Abort()
+	if _, err := delayed.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestDelayResult_AbortsSource(t *testing.T) {
+	started := make(chan struct{})
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	delayed := f.DelayResult(time.Hour)
+	<-started
+	delayed.Abort()
+	if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the source to be aborted, got %v", err)
+	}
+}
+
+func TestDelayResult_StartsLazy(t *testing.T) {
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return "lazy", nil
+	}, WithLazy())
+	if v, _ := f.DelayResult(0).Result(); v != "lazy" {
+		t.Fatalf("expected lazy, got %v", v)
+	}
+}
+
+func TestSleepCtx(t *testing.T) {
+	start := time.Now()
+	if err := SleepCtx(context.Background(), 5*time.Millisecond); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 5*time.Millisecond {
+		t.Fatalf("expected to sleep at least 5ms, slept %v", elapsed)
+	}
+}
+
+func TestSleepCtx_ContextDone(t *testing.T) {
+	errCause := errors.New("cause")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(time.Millisecond, func() {
+		cancel(errCause)
+	})
+	if err := SleepCtx(ctx, time.Hour); !errors.Is(err, errCause) {
+		t.Fatalf("expected the cause, got %v", err)
+	}
+	if err := SleepCtx(ctx, 0); !errors.Is(err, errCause) {
+		t.Fatalf("expected the cause for a zero sleep, got %v", err)
+	}
+	if err := SleepCtx(context.Background(), -time.Second); err != nil {
+		t.Fatalf("expected a negative sleep to return at once, got %v", err)
+	}
+}
+
+func BenchmarkSleepCtx(b *testing.B) {
+	b.ReportAllocs()
+	ctx := context.Background()
+	for range b.N {
+		SleepCtx(ctx, time.Microsecond)
+	}
+}
+
+func BenchmarkTimeAfter(b *testing.B) {
+	b.ReportAllocs()
+	ctx := context.Background()
+	for range b.N {
+		select {
+		case <-time.After(time.Microsecond):
+		case <-ctx.Done():
+		}
+	}
+}
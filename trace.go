@@ -0,0 +1,71 @@
+package A
+
+import "context"
+
+// Tracer starts spans for futures. It lets a future appear in a distributed
+// trace without this package depending on a tracing library.
+type Tracer interface {
+	// StartSpan starts a span named name as a child of the span in ctx, and
+	// returns a context carrying the new span.
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// End ends the span, recording err if it is not nil.
+	End(err error)
+}
+
+// WithTracer traces the future's task with t. A span starts when the task
+// begins executing, so the wait of a lazy, queued, or rate-limited future is
+// not part of it, and the task receives the span's context. The span ends
+// when the future settles, recording its error, including panics, timeouts,
+// and aborts. Each execution attempt gets its own span: with WithRetry, the
+// span of a failed attempt ends as soon as it fails, with its error.
+func WithTracer(t Tracer) Option {
+	return func(f *Future) {
+		f.tracer = t
+	}
+}
+
+// traced starts the span of an execution attempt, if the future is traced,
+// and returns the context to run the attempt with.
+func (f *Future) traced(ctx context.Context) context.Context {
+	if f.tracer == nil {
+		return ctx
+	}
+	return f.startSpan(ctx)
+}
+
+// endSpan ends the span of an attempt that failed and will be retried. The
+// span of the last attempt ends when the future settles.
+func (f *Future) endSpan(err error) {
+	f.mu.Lock()
+	span := f.span
+	f.span = nil
+	f.mu.Unlock()
+	if span != nil {
+		span.End(err)
+	}
+}
+
+// startSpan starts the span of an execution attempt and returns the context
+// to run the task with.
+func (f *Future) startSpan(ctx context.Context) context.Context {
+	name := f.name
+	if name == "" {
+		name = "future"
+	}
+	ctx, span := f.tracer.StartSpan(ctx, name)
+	f.mu.Lock()
+	if f.settled {
+		// Aborted or timed out while the span was starting.
+		err := f.err
+		f.mu.Unlock()
+		span.End(err)
+		return ctx
+	}
+	f.span = span
+	f.mu.Unlock()
+	return ctx
+}
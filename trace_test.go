@@ -0,0 +1,120 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testSpanKey struct{}
+
+// testTracer records the spans it starts.
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+type testSpan struct {
+	name  string
+	ended chan error
+}
+
+func (t *testTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	span := &testSpan{name: name, ended: make(chan error, 1)}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return context.WithValue(ctx, testSpanKey{}, span), span
+}
+
+func (s *testSpan) End(err error) {
+	s.ended <- err
+}
+
+func (t *testTracer) started() []*testSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*testSpan(nil), t.spans...)
+}
+
+func TestFuture_Tracer(t *testing.T) {
+	tracer := &testTracer{}
+	errFailed := errors.New("failed")
+
+	var taskSpan any
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		taskSpan = ctx.Value(testSpanKey{})
+		return nil, errFailed
+	}, WithTracer(tracer), WithName("load"), WithLazy())
+
+	// A lazy future has no span until its task begins
+	time.Sleep(10 * time.Millisecond)
+	if spans := tracer.started(); len(spans) != 0 {
+		t.Fatalf("expected no span before the task starts, got %d", len(spans))
+	}
+	future.Result()
+	spans := tracer.started()
+	if len(spans) != 1 || spans[0].name != "load" {
+		t.Fatalf("expected one span named 'load', got %v", spans)
+	}
+	if taskSpan != spans[0] {
+		t.Fatal("expected the task to run under the span's context")
+	}
+	if err := <-spans[0].ended; !errors.Is(err, errFailed) {
+		t.Fatalf("expected the span to record the task error, got %v", err)
+	}
+}
+
+func TestFuture_TracerTimeout(t *testing.T) {
+	tracer := &testTracer{}
+	release := make(chan struct{})
+	defer close(release)
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-release // ignores its context
+		return nil, nil
+	}, WithTracer(tracer), WithTimeout(10*time.Millisecond))
+
+	// The span ends at settlement, not when the task finally returns
+	future.Result()
+	select {
+	case err := <-tracer.started()[0].ended:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected the span to record the timeout, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the span to end when the future timed out")
+	}
+}
+
+func TestFuture_TracerRetry(t *testing.T) {
+	tracer := &testTracer{}
+	errFailed := errors.New("failed")
+	var calls int
+	f := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		calls++
+		if calls < 3 {
+			return nil, errFailed
+		}
+		return "ok", nil
+	}, WithTracer(tracer), WithRetry(3), WithName("load"))
+	if v, err := f.Result(); v != "ok" || err != nil {
+		t.Fatalf("expected ok, got %v, %v", v, err)
+	}
+
+	// One span per attempt, each recording that attempt's error
+	spans := tracer.started()
+	if len(spans) != 3 {
+		t.Fatalf("expected a span per attempt, got %d", len(spans))
+	}
+	for i, span := range spans {
+		var want error
+		if i < 2 {
+			want = errFailed
+		}
+		if err := <-span.ended; err != want {
+			t.Fatalf("expected span %d to end with %v, got %v", i, want, err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidOption is wrapped by the errors of futures created with
+// misused options.
+var ErrInvalidOption = errors.New("invalid option")
+
+// NewFutureE creates a Future like NewFuture, but reports misused options
+// as an error wrapping ErrInvalidOption instead of creating the future.
+func NewFutureE(ctx context.Context, task func(context.Context) (any, error), opts ...Option) (*Future, error) {
+	f := newFuture(ctx, task, opts...)
+	if task == nil {
+		f.awaitTask()
+	}
+	if f.rejectInvalid() {
+		return nil, f.err
+	}
+	if !f.lazy {
+		f.once.Do(f.start)
+	}
+	return f, nil
+}
+
+// invalidOption records that an option was misused.
+func (f *Future) invalidOption(option, format string, args ...any) {
+	err := fmt.Errorf("%w: %s: %s", ErrInvalidOption, option, fmt.Sprintf(format, args...))
+	f.invalid = append(f.invalid, err)
+}
+
+// optionsErr returns the misused options joined into one error, or nil.
+func (f *Future) optionsErr() error {
+	return errors.Join(f.invalid...)
+}
+
+// rejectInvalid settles a future created with misused options with their
+// error, instead of ever running its task. It reports whether it did.
+func (f *Future) rejectInvalid() bool {
+	err := f.optionsErr()
+	if err == nil {
+		return false
+	}
+	f.once.Do(func() {})
+	f.settle(nil, err)
+	return true
+}
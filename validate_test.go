@@ -0,0 +1,100 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInvalidOptions(t *testing.T) {
+	tests := map[string][]Option{
+		"negative timeout":    {WithTimeout(-time.Second)},
+		"nil semaphore":       {WithSemaphore(nil, 1)},
+		"zero weight":         {WithSemaphore(newTestSemaphore(1), 0)},
+		"zero slow warning":   {WithSlowWarning(0, func(*Future, time.Duration) {})},
+		"nil slow callback":   {WithSlowWarning(time.Second, nil)},
+		"negative slow every": {WithSlowWarningEvery(-time.Second)},
+		"zero stall timeout":  {WithStallTimeout(0)},
+		"nil middleware":      {WithMiddleware(nil)},
+		"nil error decorator": {WithErrorDecorator(nil)},
+		"zero retry attempts": {WithRetry(0)},
+		"nil result clone":    {WithResultClone(nil)},
+		"zero abort grace":    {WithAbortGrace(0, func(*Future, []byte) {})},
+		"zero hedge delay":    {WithHedge(0)},
+		"negative backoff":    {WithRetryBackoff(-time.Second)},
+		"nil stuck callback":  {WithAbortGrace(time.Second, nil)},
+		"nil dependency":      {WithDependencies(nil)},
+	}
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			ran := false
+			task := func(context.Context) (any, error) {
+				ran = true
+				return nil, nil
+			}
+			if f, err := NewFutureE(context.Background(), task, opts...); f != nil || !errors.Is(err, ErrInvalidOption) {
+				t.Fatalf("NewFutureE: expected ErrInvalidOption, got %v, %v", f, err)
+			}
+			f := NewFuture(context.Background(), task, opts...)
+			if _, err := f.Result(); !errors.Is(err, ErrInvalidOption) {
+				t.Fatalf("NewFuture: expected ErrInvalidOption, got %v", err)
+			}
+			if ran {
+				t.Fatalf("task ran despite invalid options")
+			}
+		})
+	}
+}
+
+func TestInvalidOptionsJoined(t *testing.T) {
+	_, err := NewFutureE(context.Background(), func(context.Context) (any, error) {
+		return nil, nil
+	}, WithTimeout(-1), WithStallTimeout(-1))
+	if err == nil || !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption, got %v", err)
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 2 {
+		t.Fatalf("expected 2 joined errors, got %d: %v", n, err)
+	}
+}
+
+func TestNewFutureEValid(t *testing.T) {
+	f, err := NewFutureE(context.Background(), func(context.Context) (any, error) {
+		return 1, nil
+	}, WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, err := f.Result(); v != 1 || err != nil {
+		t.Fatalf("unexpected result: %v, %v", v, err)
+	}
+}
+
+func TestInvalidOptionsPoolAndGroup(t *testing.T) {
+	p := NewPool(1)
+	defer p.Shutdown(context.Background())
+	task := func(context.Context) (any, error) {
+		return nil, nil
+	}
+	if _, err := p.Submit(context.Background(), task, WithLazy()).Result(); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected lazy pool submission to be invalid, got %v", err)
+	}
+	for _, f := range p.SubmitAll(context.Background(), []func(context.Context) (any, error){task, task}, WithTimeout(-1)) {
+		if _, err := f.Result(); !errors.Is(err, ErrInvalidOption) {
+			t.Fatalf("expected ErrInvalidOption from SubmitAll, got %v", err)
+		}
+	}
+	if s := p.Stats(); s.Queued != 0 || s.Completed != 0 {
+		t.Fatalf("invalid submissions reached the queue: %+v", s)
+	}
+
+	g := NewGroup(context.Background())
+	g.SetLimit(1)
+	g.Go(task, WithTimeout(-1))
+	// The invalid member must not hold on to the only slot.
+	g.Go(task)
+	if err := g.Wait(); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected group to fail with ErrInvalidOption, got %v", err)
+	}
+}
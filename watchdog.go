@@ -0,0 +1,56 @@
+package A
+
+import "time"
+
+// WithSlowWarning calls fn if the task has been executing for longer than d
+// without the future settling, typically to log it. It catches tasks that
+// are stuck without ever tripping a timeout. fn runs once unless
+// WithSlowWarningEvery is also given, on its own goroutine.
+func WithSlowWarning(d time.Duration, fn func(f *Future, running time.Duration)) Option {
+	return func(f *Future) {
+		if d <= 0 || fn == nil {
+			f.invalidOption("WithSlowWarning", "non-positive duration %v or nil callback", d)
+			return
+		}
+		f.slowAfter = d
+		f.onSlow = fn
+	}
+}
+
+// WithSlowWarningEvery repeats the WithSlowWarning callback every interval
+// for as long as the future stays unsettled.
+func WithSlowWarningEvery(interval time.Duration) Option {
+	return func(f *Future) {
+		if interval < 0 {
+			f.invalidOption("WithSlowWarningEvery", "negative interval %v", interval)
+			return
+		}
+		f.slowEvery = interval
+	}
+}
+
+// watch arms the slow-task warning once the task starts. The timer is
+// stopped when the future settles, so settled futures hold no timers.
+func (f *Future) watch() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.settled {
+		return
+	}
+	f.slowTimer = f.afterFunc(f.slowAfter, f.warnSlow)
+}
+
+// warnSlow reports the running task and re-arms the warning if it repeats.
+func (f *Future) warnSlow() {
+	f.mu.Lock()
+	if f.settled {
+		f.mu.Unlock()
+		return
+	}
+	running := f.since(f.startedAt)
+	if f.slowEvery > 0 {
+		f.slowTimer.Reset(f.slowEvery)
+	}
+	f.mu.Unlock()
+	f.onSlow(f, running)
+}
@@ -0,0 +1,63 @@
+package A
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+func TestFuture_SlowWarning(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	warnings := make(chan time.Duration, 10)
+	release := make(chan struct{})
+	future := NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	}, WithSlowWarning(20*time.Millisecond, func(f *Future, running time.Duration) {
+		warnings <- running
+	}), WithSlowWarningEvery(10*time.Millisecond), WithClock(clock))
+
+	// The warning fires after the threshold and repeats while the task runs
+	clock.BlockUntil(1)
+	clock.Advance(19 * time.Millisecond)
+	if len(warnings) != 0 {
+		t.Fatal("expected no warning before the threshold")
+	}
+	clock.Advance(time.Millisecond)
+	clock.Advance(10 * time.Millisecond)
+	for _, want := range []time.Duration{20 * time.Millisecond, 30 * time.Millisecond} {
+		if running := <-warnings; running != want {
+			t.Fatalf("expected the task to have run for %v, got %v", want, running)
+		}
+	}
+	close(release)
+	future.Result()
+
+	// No warnings after settlement
+	if n := clock.Timers(); n != 0 {
+		t.Fatalf("expected the warning timer to be stopped, got %d timers", n)
+	}
+	clock.Advance(time.Hour)
+	if len(warnings) != 0 {
+		t.Fatal("expected no warnings after the future settled")
+	}
+}
+
+func TestFuture_SlowWarningFast(t *testing.T) {
+	clock := fakeclock.New(time.Now())
+	warned := make(chan struct{}, 1)
+	NewFuture(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, WithSlowWarning(10*time.Millisecond, func(f *Future, running time.Duration) {
+		warned <- struct{}{}
+	}), WithClock(clock)).Result()
+
+	clock.Advance(time.Hour)
+	select {
+	case <-warned:
+		t.Fatal("expected no warning for a task that settled in time")
+	default:
+	}
+}
@@ -0,0 +1,180 @@
+package A
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBuckets is the number of exec-time histogram buckets: four per
+// power of two, enough for durations up to about 2^50ns, or 13 days.
+const latencyBuckets = 4 * 50
+
+// WindowStats is a Metrics that keeps a rolling view of the last few
+// seconds of settled futures: throughput, error rate, and exec-time
+// percentiles, for feeding adaptive concurrency control without exporting
+// and scraping. Attach it with WithMetrics, WithPoolMetrics, or
+// SetDefaultMetrics. Updates are atomic and lock-free; counts recorded
+// just as a second's bucket is recycled may be lost, so treat the figures
+// as approximate.
+type WindowStats struct {
+	clock   Clock
+	buckets []windowBucket
+}
+
+// windowBucket holds one second of settlements.
+type windowBucket struct {
+	second  atomic.Int64 // Unix second the counts belong to
+	settled atomic.Uint64
+	failed  atomic.Uint64
+	latency [latencyBuckets]atomic.Uint32
+}
+
+// WindowOption configures a WindowStats.
+type WindowOption func(*WindowStats)
+
+// WithWindowClock makes the stats read time from c instead of the real
+// clock.
+func WithWindowClock(c Clock) WindowOption {
+	return func(w *WindowStats) {
+		w.clock = c
+	}
+}
+
+// NewWindowStats returns a WindowStats covering the last window, rounded
+// up to whole seconds, with one second at least.
+func NewWindowStats(window time.Duration, opts ...WindowOption) *WindowStats {
+	seconds := max(int((window+time.Second-1)/time.Second), 1)
+	w := &WindowStats{buckets: make([]windowBucket, seconds)}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// FutureStarted implements Metrics.
+func (w *WindowStats) FutureStarted(name string) {}
+
+// FutureSettled implements Metrics. Failed, Panicked, and TimedOut
+// futures count as errors; aborted ones only count as settled.
+func (w *WindowStats) FutureSettled(name string, outcome Outcome, execTime, waitTime time.Duration) {
+	b := w.bucket()
+	b.settled.Add(1)
+	if outcome == Failed || outcome == Panicked || outcome == TimedOut {
+		b.failed.Add(1)
+	}
+	b.latency[latencyBucket(execTime)].Add(1)
+}
+
+// Throughput returns how many futures settled per second over the window.
+func (w *WindowStats) Throughput() float64 {
+	var settled uint64
+	w.each(func(b *windowBucket) {
+		settled += b.settled.Load()
+	})
+	return float64(settled) / float64(len(w.buckets))
+}
+
+// ErrorRate returns the fraction of futures settled over the window that
+// failed, panicked, or timed out, or 0 if none settled.
+func (w *WindowStats) ErrorRate() float64 {
+	var settled, failed uint64
+	w.each(func(b *windowBucket) {
+		settled += b.settled.Load()
+		failed += b.failed.Load()
+	})
+	if settled == 0 {
+		return 0
+	}
+	return float64(failed) / float64(settled)
+}
+
+// P50 returns the median exec time over the window.
+func (w *WindowStats) P50() time.Duration {
+	return w.Quantile(0.5)
+}
+
+// P99 returns the 99th percentile exec time over the window.
+func (w *WindowStats) P99() time.Duration {
+	return w.Quantile(0.99)
+}
+
+// Quantile returns the q-quantile exec time over the window, for q between
+// 0 and 1, or 0 if nothing settled. It is accurate to within about 12%.
+func (w *WindowStats) Quantile(q float64) time.Duration {
+	var counts [latencyBuckets]uint64
+	var total uint64
+	w.each(func(b *windowBucket) {
+		for i := range b.latency {
+			n := uint64(b.latency[i].Load())
+			counts[i] += n
+			total += n
+		}
+	})
+	if total == 0 {
+		return 0
+	}
+	rank := uint64(min(max(q, 0), 1)*float64(total-1)) + 1
+	var seen uint64
+	for i, n := range counts {
+		seen += n
+		if seen >= rank {
+			return latencyValue(i)
+		}
+	}
+	return latencyValue(latencyBuckets - 1)
+}
+
+// bucket returns the current second's bucket, recycling it if it last held
+// an older second.
+func (w *WindowStats) bucket() *windowBucket {
+	now := w.now().Unix()
+	b := &w.buckets[now%int64(len(w.buckets))]
+	if old := b.second.Load(); old != now && b.second.CompareAndSwap(old, now) {
+		b.settled.Store(0)
+		b.failed.Store(0)
+		for i := range b.latency {
+			b.latency[i].Store(0)
+		}
+	}
+	return b
+}
+
+// each calls fn with every bucket that falls within the window.
+func (w *WindowStats) each(fn func(b *windowBucket)) {
+	now := w.now().Unix()
+	for i := range w.buckets {
+		b := &w.buckets[i]
+		if second := b.second.Load(); second > now-int64(len(w.buckets)) && second <= now {
+			fn(b)
+		}
+	}
+}
+
+func (w *WindowStats) now() time.Time {
+	if w.clock != nil {
+		return w.clock.Now()
+	}
+	return time.Now()
+}
+
+// latencyBucket returns the histogram bucket of d. Durations below 4ns get
+// a bucket each; above that, each power of two is split in four.
+func latencyBucket(d time.Duration) int {
+	if d < 4 {
+		return max(int(d), 0)
+	}
+	octave := bits.Len64(uint64(d))
+	quarter := int(uint64(d)>>(octave-3)) & 3
+	return min((octave-2)*4+quarter, latencyBuckets-1)
+}
+
+// latencyValue returns the midpoint of histogram bucket i.
+func latencyValue(i int) time.Duration {
+	if i < 4 {
+		return time.Duration(i)
+	}
+	octave, quarter := i/4+2, i%4
+	lower := time.Duration(4+quarter) << (octave - 3)
+	return lower + time.Duration(1)<<(octave-3)/2
+}
@@ -0,0 +1,104 @@
+package A
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ongniud/future/internal/fakeclock"
+)
+
+func TestWindowStats(t *testing.T) {
+	clock := fakeclock.New(time.Unix(1000, 0))
+	w := NewWindowStats(10*time.Second, WithWindowClock(clock))
+	if w.Throughput() != 0 || w.ErrorRate() != 0 || w.P50() != 0 {
+		t.Fatalf("expected empty stats")
+	}
+
+	// 100 futures over two seconds, a quarter of them failing
+	for i := range 100 {
+		outcome := Succeeded
+		if i%4 == 0 {
+			outcome = Failed
+		}
+		w.FutureSettled("", outcome, time.Duration(i+1)*time.Millisecond, 0)
+		if i == 49 {
+			clock.Advance(time.Second)
+		}
+	}
+	w.FutureSettled("", Aborted, 0, 0)
+	if got := w.Throughput(); got != 10.1 {
+		t.Fatalf("expected 10.1 per second, got %v", got)
+	}
+	if got := w.ErrorRate(); got != 25.0/101 {
+		t.Fatalf("expected an error rate of 25/101, got %v", got)
+	}
+	within := func(got, want time.Duration) bool {
+		return got >= want*88/100 && got <= want*112/100
+	}
+	if got := w.P50(); !within(got, 50*time.Millisecond) {
+		t.Fatalf("expected a P50 near 50ms, got %v", got)
+	}
+	if got := w.P99(); !within(got, 99*time.Millisecond) {
+		t.Fatalf("expected a P99 near 99ms, got %v", got)
+	}
+
+	// The first second leaves the window, then the second one
+	clock.Advance(9 * time.Second)
+	if got := w.Throughput(); got != 5.1 {
+		t.Fatalf("expected 5.1 per second once the first second expired, got %v", got)
+	}
+	clock.Advance(time.Second)
+	if got := w.Throughput(); got != 0 {
+		t.Fatalf("expected an empty window, got %v", got)
+	}
+
+	// A recycled bucket starts from zero
+	w.FutureSettled("", Failed, time.Second, 0)
+	if got := w.ErrorRate(); got != 1 {
+		t.Fatalf("expected only the new failure, got an error rate of %v", got)
+	}
+	if got := w.P50(); !within(got, time.Second) {
+		t.Fatalf("expected a P50 near 1s, got %v", got)
+	}
+}
+
+func TestLatencyBuckets(t *testing.T) {
+	prev := -1
+	for d := time.Duration(0); d < 1<<40; d = d*9/8 + 1 {
+		i := latencyBucket(d)
+		if i < prev {
+			t.Fatalf("bucket of %v went down to %d from %d", d, i, prev)
+		}
+		prev = i
+		if got := latencyValue(i); d >= 4 && (got < d*7/8 || got > d*9/8) {
+			t.Fatalf("expected bucket %d of %v to be within 12.5%%, got %v", i, d, got)
+		}
+	}
+	if i := latencyBucket(1 << 62); i != latencyBuckets-1 {
+		t.Fatalf("expected huge durations in the last bucket, got %d", i)
+	}
+}
+
+func TestWithPoolMetrics(t *testing.T) {
+	w := NewWindowStats(time.Minute)
+	p := NewPool(2, WithPoolMetrics(w))
+	defer p.Shutdown(context.Background())
+
+	errDown := errors.New("down")
+	for i := range 4 {
+		p.Submit(context.Background(), func(ctx context.Context) (any, error) {
+			if i == 0 {
+				return nil, errDown
+			}
+			return nil, nil
+		}).Result()
+	}
+	if got := w.ErrorRate(); got != 0.25 {
+		t.Fatalf("expected an error rate of 0.25, got %v", got)
+	}
+	if got := w.Throughput(); got != 4.0/60 {
+		t.Fatalf("expected 4 settlements over the minute, got %v", got)
+	}
+}